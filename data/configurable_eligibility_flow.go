@@ -0,0 +1,212 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gogen_pilots/matchers"
+	"io/ioutil"
+	"time"
+)
+
+type configurableEligibilityFlow struct {
+	options             EligibilityOptions
+	dismissCodeSections map[string]bool
+	reduceCodeSections  map[string]bool
+}
+
+func LoadEligibilityOptions(optionsFilePath string) (EligibilityOptions, error) {
+	var options EligibilityOptions
+
+	optionsJSON, err := ioutil.ReadFile(optionsFilePath)
+	if err != nil {
+		return options, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(optionsJSON))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&options)
+	if err != nil {
+		return options, fmt.Errorf("could not parse eligibility options %s: %v", optionsFilePath, err)
+	}
+	return options, nil
+}
+
+func NewConfigurableEligibilityFlow(options EligibilityOptions) (EligibilityFlow, error) {
+	dismissCodeSections, err := codeSectionSet("dismiss", options.BaselineEligibility.Dismiss)
+	if err != nil {
+		return nil, err
+	}
+	reduceCodeSections, err := codeSectionSet("reduce", options.BaselineEligibility.Reduce)
+	if err != nil {
+		return nil, err
+	}
+	for codeSection := range dismissCodeSections {
+		if reduceCodeSections[codeSection] {
+			return nil, fmt.Errorf("code section %q cannot be in both the dismiss and reduce lists", codeSection)
+		}
+	}
+
+	relief := options.AdditionalRelief
+	thresholds := map[string]int{
+		"subjectAgeThreshold":           relief.SubjectAgeThreshold,
+		"yearsSinceConvictionThreshold": relief.YearsSinceConvictionThreshold,
+		"yearsCrimeFreeThreshold":       relief.YearsCrimeFreeThreshold,
+	}
+	for name, value := range thresholds {
+		if value < 0 {
+			return nil, fmt.Errorf("%s must not be negative, got %d", name, value)
+		}
+	}
+
+	return configurableEligibilityFlow{
+		options:             options,
+		dismissCodeSections: dismissCodeSections,
+		reduceCodeSections:  reduceCodeSections,
+	}, nil
+}
+
+func codeSectionSet(listName string, codeSections []string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, codeSection := range codeSections {
+		if !matchers.IsProp64CodeSectionKey(codeSection) {
+			return nil, fmt.Errorf("unknown code section %q in %s list: Must be one of %v", codeSection, listName, matchers.Prop64CodeSectionKeys)
+		}
+		set[codeSection] = true
+	}
+	return set, nil
+}
+
+func (ef configurableEligibilityFlow) ProcessSubject(subject *Subject, comparisonTime time.Time, flowCounty string, age int, yearsConvictionFree int) map[int]*EligibilityInfo {
+	infos := make(map[int]*EligibilityInfo)
+	for _, conviction := range subject.Convictions {
+		if ef.checkRelevancy(conviction.CodeSection, conviction.County, flowCounty) {
+			info := NewEligibilityInfo(conviction, subject, comparisonTime, flowCounty)
+			if !info.alreadyRelieved(conviction) {
+				ef.BeginEligibilityFlow(info, conviction, subject, comparisonTime)
+			}
+			infos[conviction.Index] = info
+		}
+	}
+	return infos
+}
+
+func (ef configurableEligibilityFlow) ChecksRelatedCharges() bool {
+	return false
+}
+
+func (ef configurableEligibilityFlow) checkRelevancy(codeSection string, convictionCounty string, flowCounty string) bool {
+	return convictionCounty == flowCounty && matchers.IsProp64Charge(codeSection)
+}
+
+func (ef configurableEligibilityFlow) BeginEligibilityFlow(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time) {
+	codeSection := matchers.Prop64CodeSectionKey(row.ParsedCodeSection())
+	ef.EligibleDismissal(info, row, subject, comparisonTime, codeSection)
+}
+
+func (ef configurableEligibilityFlow) EligibleDismissal(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	if ef.dismissCodeSections[codeSection] {
+		if codeSection == "11357(no-sub-section)" {
+			info.SetEligibleForDismissal("Dismiss all HS 11357 convictions (when no sub-section is specified)")
+		} else {
+			info.SetEligibleForDismissal(fmt.Sprintf("Dismiss all HS %s convictions", codeSection))
+		}
+	} else {
+		ef.EligibleReduction(info, row, subject, comparisonTime, codeSection)
+	}
+}
+
+func (ef configurableEligibilityFlow) EligibleReduction(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	if ef.reduceCodeSections[codeSection] {
+		ef.ConvictionIsNotFelony(info, row, subject, comparisonTime, codeSection)
+	} else {
+		info.SetHandReview("No applicable eligibility criteria")
+	}
+}
+
+func (ef configurableEligibilityFlow) ConvictionIsNotFelony(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	if !row.IsFelony {
+		info.SetEligibleForDismissal("Misdemeanor or Infraction")
+	} else {
+		ef.Under21AtConviction(info, row, subject, comparisonTime, codeSection)
+	}
+}
+
+func (ef configurableEligibilityFlow) Under21AtConviction(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	if ef.options.AdditionalRelief.SubjectUnder21AtConviction {
+		under21, certain := row.wasConvictionUnderAgeOf21(subject)
+		if !certain {
+			info.SetHandReview("Imprecise date of birth or conviction")
+			return
+		}
+		if under21 {
+			info.SetEligibleForDismissal("21 years or younger at conviction")
+			return
+		}
+	}
+	ef.OlderThanAgeThreshold(info, row, subject, comparisonTime, codeSection)
+}
+
+func (ef configurableEligibilityFlow) OlderThanAgeThreshold(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	threshold := ef.options.AdditionalRelief.SubjectAgeThreshold
+	if threshold != 0 {
+		olderThan, certain := subject.olderThan(threshold, comparisonTime)
+		if !certain {
+			info.SetHandReview("Imprecise date of birth")
+			return
+		}
+		if olderThan {
+			info.SetEligibleForDismissal(fmt.Sprintf("%v years or older", threshold))
+			return
+		}
+	}
+	ef.YearsSinceConviction(info, row, subject, comparisonTime, codeSection)
+}
+
+func (ef configurableEligibilityFlow) YearsSinceConviction(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	threshold := ef.options.AdditionalRelief.YearsSinceConvictionThreshold
+	if threshold != 0 {
+		convictionBefore, certain := row.convictionBefore(threshold, comparisonTime)
+		if !certain {
+			info.SetHandReview("Imprecise date of conviction")
+			return
+		}
+		if convictionBefore {
+			info.SetEligibleForDismissal(fmt.Sprintf("Conviction occurred %v or more years ago", threshold))
+			return
+		}
+	}
+	ef.NoConvictionsInGivenTimePeriod(info, row, subject, comparisonTime, codeSection)
+}
+
+func (ef configurableEligibilityFlow) NoConvictionsInGivenTimePeriod(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	threshold := ef.options.AdditionalRelief.YearsCrimeFreeThreshold
+	if threshold != 0 {
+		noConvictions, certain := info.noConvictionsInGivenTimePeriod(row, subject, threshold)
+		if !certain {
+			info.SetHandReview("Imprecise conviction dates")
+			return
+		}
+		if noConvictions {
+			info.SetEligibleForDismissal(fmt.Sprintf("No convictions in past %v years", threshold))
+			return
+		}
+	}
+	ef.OnlyProp64Convictions(info, row, subject, comparisonTime, codeSection)
+}
+
+func (ef configurableEligibilityFlow) OnlyProp64Convictions(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	if ef.options.AdditionalRelief.SubjectHasOnlyProp64Charges && info.onlyProp64Convictions(row, subject) {
+		info.SetEligibleForDismissal("Only has 11357-60 charges")
+	} else {
+		ef.IsDeceased(info, row, subject, comparisonTime, codeSection)
+	}
+}
+
+func (ef configurableEligibilityFlow) IsDeceased(info *EligibilityInfo, row *DOJRow, subject *Subject, comparisonTime time.Time, codeSection string) {
+	if ef.options.AdditionalRelief.SubjectIsDeceased && subject.IsDeceased {
+		info.SetEligibleForDismissal("Deceased")
+	} else {
+		info.SetEligibleForReduction(fmt.Sprintf("Reduce all HS %s convictions", codeSection))
+	}
+}
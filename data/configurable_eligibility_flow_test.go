@@ -0,0 +1,270 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("configurableEligibilityFlow", func() {
+	const COUNTY = "SACRAMENTO"
+
+	birthDate := time.Date(1980, time.April, 10, 0, 0, 0, 0, time.UTC)
+	comparisonTime := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	age := 50
+	yearsConvictionFree := 10
+
+	var options EligibilityOptions
+
+	BeforeEach(func() {
+		options = EligibilityOptions{
+			BaselineEligibility: BaselineEligibility{
+				Dismiss: []string{"11357(a)", "11357(no-sub-section)", "11358"},
+				Reduce:  []string{"11359", "11360"},
+			},
+		}
+	})
+
+	processConviction := func(flow EligibilityFlow, conviction DOJRow, otherRows ...DOJRow) *EligibilityInfo {
+		subject := Subject{}
+		subject.PushRow(conviction)
+		for _, row := range otherRows {
+			subject.PushRow(row)
+		}
+		infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY, age, yearsConvictionFree)
+		Expect(infos).To(HaveKey(conviction.Index))
+		return infos[conviction.Index]
+	}
+
+	newConviction := func(codeSection string, isFelony bool, dispositionDate time.Time) DOJRow {
+		return DOJRow{
+			SubjectID:       "1",
+			DOB:             birthDate,
+			WasConvicted:    true,
+			CodeSection:     codeSection,
+			DispositionDate: dispositionDate,
+			County:          COUNTY,
+			CountOrder:      "101001001000",
+			Index:           0,
+			IsFelony:        isFelony,
+		}
+	}
+
+	Describe("Loading eligibility options", func() {
+		It("reads the options from a JSON file", func() {
+			options, err := LoadEligibilityOptions(path.Join("..", "test_fixtures", "eligibility_options.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(options.BaselineEligibility.Dismiss).To(Equal([]string{"11357(a)", "11357(c)", "11357(d)", "11357(no-sub-section)", "11358"}))
+			Expect(options.BaselineEligibility.Reduce).To(Equal([]string{"11357(b)", "11359", "11360"}))
+			Expect(options.AdditionalRelief).To(Equal(AdditionalRelief{
+				SubjectUnder21AtConviction:    true,
+				SubjectAgeThreshold:           57,
+				YearsSinceConvictionThreshold: 10,
+				SubjectIsDeceased:             true,
+				SubjectHasOnlyProp64Charges:   true,
+			}))
+		})
+
+		It("rejects options with unknown fields", func() {
+			optionsFile, err := ioutil.TempFile("", "eligibility_options")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(optionsFile.Name())
+			optionsFile.WriteString(`{"baselineEligibility": {"dismissAll": ["11358"]}}`)
+			optionsFile.Close()
+
+			_, err = LoadEligibilityOptions(optionsFile.Name())
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "dismissAll"`)))
+		})
+	})
+
+	Describe("Validating eligibility options", func() {
+		It("accepts the fixture options", func() {
+			options, err := LoadEligibilityOptions(path.Join("..", "test_fixtures", "eligibility_options.json"))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = NewConfigurableEligibilityFlow(options)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("rejects code sections that are not Prop 64 code sections", func() {
+			options.BaselineEligibility.Dismiss = append(options.BaselineEligibility.Dismiss, "11361")
+			_, err := NewConfigurableEligibilityFlow(options)
+			Expect(err).To(MatchError(ContainSubstring(`unknown code section "11361" in dismiss list`)))
+		})
+
+		It("rejects code sections that are in both lists", func() {
+			options.BaselineEligibility.Reduce = append(options.BaselineEligibility.Reduce, "11358")
+			_, err := NewConfigurableEligibilityFlow(options)
+			Expect(err).To(MatchError(`code section "11358" cannot be in both the dismiss and reduce lists`))
+		})
+
+		It("rejects negative thresholds", func() {
+			options.AdditionalRelief.SubjectAgeThreshold = -1
+			_, err := NewConfigurableEligibilityFlow(options)
+			Expect(err).To(MatchError("subjectAgeThreshold must not be negative, got -1"))
+		})
+	})
+
+	Describe("Processing a subject", func() {
+		var flow EligibilityFlow
+
+		JustBeforeEach(func() {
+			var err error
+			flow, err = NewConfigurableEligibilityFlow(options)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("dismisses convictions for code sections in the dismiss list", func() {
+			info := processConviction(flow, newConviction("11358 HS", true, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+			Expect(info.EligibilityReason).To(Equal("Dismiss all HS 11358 convictions"))
+		})
+
+		It("dismisses 11357 convictions without a sub-section when they are in the dismiss list", func() {
+			info := processConviction(flow, newConviction("11357 HS", true, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+			Expect(info.EligibilityReason).To(Equal("Dismiss all HS 11357 convictions (when no sub-section is specified)"))
+		})
+
+		It("dismisses misdemeanor convictions for code sections in the reduce list", func() {
+			info := processConviction(flow, newConviction("11359 HS", false, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+			Expect(info.EligibilityReason).To(Equal("Misdemeanor or Infraction"))
+		})
+
+		It("sends convictions for code sections in neither list to hand review", func() {
+			info := processConviction(flow, newConviction("11357(B) HS", true, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(info.EligibilityDetermination).To(Equal("Hand Review"))
+			Expect(info.EligibilityReason).To(Equal("No applicable eligibility criteria"))
+		})
+
+		It("ignores convictions from other counties", func() {
+			conviction := newConviction("11358 HS", true, time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC))
+			conviction.County = "LOS ANGELES"
+			subject := Subject{}
+			subject.PushRow(conviction)
+			Expect(flow.ProcessSubject(&subject, comparisonTime, COUNTY, age, yearsConvictionFree)).To(BeEmpty())
+		})
+
+		Context("When a reduce list felony qualifies for additional relief", func() {
+			It("dismisses convictions for subjects under 21 at conviction", func() {
+				options.AdditionalRelief.SubjectUnder21AtConviction = true
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				info := processConviction(flow, newConviction("11359 HS", true, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)))
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("21 years or younger at conviction"))
+			})
+
+			It("dismisses convictions for subjects older than the age threshold", func() {
+				options.AdditionalRelief.SubjectAgeThreshold = 40
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				info := processConviction(flow, newConviction("11359 HS", true, time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)))
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("40 years or older"))
+			})
+
+			It("dismisses convictions older than the years since conviction threshold", func() {
+				options.AdditionalRelief.YearsSinceConvictionThreshold = 10
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				otherConviction := newConviction("459 PC", true, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+				otherConviction.CountOrder = "102001001000"
+				otherConviction.Index = 1
+				info := processConviction(flow, newConviction("11360 HS", true, time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC)), otherConviction)
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("Conviction occurred 10 or more years ago"))
+			})
+
+			It("dismisses convictions for subjects without convictions in the crime free threshold", func() {
+				options.AdditionalRelief.YearsCrimeFreeThreshold = 5
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				info := processConviction(flow, newConviction("11360 HS", true, time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)))
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("No convictions in past 5 years"))
+			})
+
+			It("dismisses convictions for subjects with only Prop 64 convictions", func() {
+				options.AdditionalRelief.SubjectHasOnlyProp64Charges = true
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				info := processConviction(flow, newConviction("11360 HS", true, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)))
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("Only has 11357-60 charges"))
+			})
+
+			It("dismisses convictions for deceased subjects", func() {
+				options.AdditionalRelief.SubjectIsDeceased = true
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				deceased := DOJRow{SubjectID: "1", Type: "DECEASED", CountOrder: "103001001000", Index: 1}
+				info := processConviction(flow, newConviction("11360 HS", true, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)), deceased)
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("Deceased"))
+			})
+		})
+
+		Context("When additional relief depends on an imprecise date", func() {
+			It("sends convictions to hand review when the subject's age is not known precisely enough", func() {
+				options.AdditionalRelief.SubjectAgeThreshold = 40
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				conviction := newConviction("11359 HS", true, time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC))
+				conviction.DOB = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+				conviction.DOBPrecision = YearPrecision
+				info := processConviction(flow, conviction)
+				Expect(info.EligibilityDetermination).To(Equal("Hand Review"))
+				Expect(info.EligibilityReason).To(Equal("Imprecise date of birth"))
+
+				conviction.DOB = time.Date(1979, time.January, 1, 0, 0, 0, 0, time.UTC)
+				info = processConviction(flow, conviction)
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("40 years or older"))
+			})
+
+			It("sends convictions to hand review when the date of birth is not known", func() {
+				options.AdditionalRelief.SubjectUnder21AtConviction = true
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				conviction := newConviction("11359 HS", true, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+				conviction.DOB = time.Time{}
+				conviction.DOBPrecision = NoDate
+				info := processConviction(flow, conviction)
+				Expect(info.EligibilityDetermination).To(Equal("Hand Review"))
+				Expect(info.EligibilityReason).To(Equal("Imprecise date of birth or conviction"))
+			})
+
+			It("does not send convictions to hand review when every date they may be has the same outcome", func() {
+				options.AdditionalRelief.YearsSinceConvictionThreshold = 10
+				flow, _ = NewConfigurableEligibilityFlow(options)
+				conviction := newConviction("11360 HS", true, time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC))
+				conviction.DispositionDatePrecision = YearPrecision
+				info := processConviction(flow, conviction)
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("Conviction occurred 10 or more years ago"))
+			})
+		})
+
+		It("reduces reduce list felonies without additional relief", func() {
+			info := processConviction(flow, newConviction("11360 HS", true, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(info.EligibilityDetermination).To(Equal("Eligible for Reduction"))
+			Expect(info.EligibilityReason).To(Equal("Reduce all HS 11360 convictions"))
+		})
+
+		It("reduces 11357 sub-section felonies by their sub-section", func() {
+			options.BaselineEligibility.Reduce = append(options.BaselineEligibility.Reduce, "11357(b)")
+			flow, _ = NewConfigurableEligibilityFlow(options)
+			info := processConviction(flow, newConviction("11357(B) HS", true, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(info.EligibilityDetermination).To(Equal("Eligible for Reduction"))
+			Expect(info.EligibilityReason).To(Equal("Reduce all HS 11357(b) convictions"))
+		})
+
+		It("no longer counts subjects whose only felonies are reduced as having a felony", func() {
+			conviction := newConviction("11359 HS", true, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+			subject := Subject{}
+			subject.PushRow(conviction)
+			dojInformation := DOJInformation{Subjects: map[string]*Subject{"1": &subject}}
+
+			eligibilities := flow.ProcessSubject(&subject, comparisonTime, COUNTY, age, yearsConvictionFree)
+			Expect(dojInformation.CountIndividualsNoLongerHaveFelony(eligibilities)).To(Equal(1))
+			Expect(dojInformation.CountIndividualsNoLongerHaveConviction(eligibilities)).To(Equal(0))
+		})
+	})
+})
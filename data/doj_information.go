@@ -2,11 +2,10 @@ package data
 
 import (
 	"bufio"
-	"encoding/csv"
 	"fmt"
 	"gogen_pilots/matchers"
 	"gogen_pilots/utilities"
-	"os"
+	"io"
 	"sort"
 	"strings"
 	"time"
@@ -14,9 +13,12 @@ import (
 
 type DOJInformation struct {
 	Rows                 [][]string
+	RejectedRows         []RejectedRow
 	Subjects             map[string]*Subject
 	comparisonTime       time.Time
 	checksRelatedCharges bool
+	identities           *IdentityResolution
+	rejectedRowNumbers   map[string][]int
 }
 
 func (i *DOJInformation) aggregateSubjects() {
@@ -28,12 +30,7 @@ func (i *DOJInformation) aggregateSubjects() {
 
 	for index, row := range i.Rows {
 		startTime := time.Now()
-		dojRow := NewDOJRow(row, index)
-		if i.Subjects[dojRow.SubjectID] == nil {
-			i.Subjects[dojRow.SubjectID] = new(Subject)
-		}
-		i.Subjects[dojRow.SubjectID].PushRow(dojRow)
-
+		i.pushRow(row, index)
 		totalTime += time.Since(startTime)
 
 		utilities.PrintProgressBar(index+1, totalRows, totalTime, "")
@@ -41,11 +38,39 @@ func (i *DOJInformation) aggregateSubjects() {
 	fmt.Println("\nComplete...")
 }
 
+func (i *DOJInformation) pushRows() {
+	for index, row := range i.Rows {
+		i.pushRow(row, index)
+	}
+}
+
+func (i *DOJInformation) pushRow(row []string, index int) {
+	dojRow := NewDOJRow(row, index)
+	subjectID := dojRow.SubjectID
+	if i.identities != nil {
+		subjectID = i.identities.ResolvedSubjectID(subjectID)
+	}
+	if i.Subjects[subjectID] == nil {
+		i.Subjects[subjectID] = new(Subject)
+	}
+	i.Subjects[subjectID].PushRow(dojRow)
+}
+
+// addRejectedRowNumbers records rows of a SUBJECT_ID that failed validation,
+// under the subject the SUBJECT_ID is resolved to.
+func (i *DOJInformation) addRejectedRowNumbers(subjectID string, rowNumbers []int) {
+	if i.identities != nil {
+		subjectID = i.identities.ResolvedSubjectID(subjectID)
+	}
+	i.rejectedRowNumbers[subjectID] = append(i.rejectedRowNumbers[subjectID], rowNumbers...)
+}
+
 func (i *DOJInformation) DetermineEligibility(county string, eligibilityFlow EligibilityFlow, age int, timeSinceConviction int) map[int]*EligibilityInfo {
 	eligibilities := make(map[int]*EligibilityInfo)
-	for _, subject := range i.Subjects {
+	for subjectID, subject := range i.Subjects {
 		infos := eligibilityFlow.ProcessSubject(subject, i.comparisonTime, county, age, timeSinceConviction)
 		for index, info := range infos {
+			info.reviewRejectedRows(i.rejectedRowNumbers[subjectID])
 			eligibilities[index] = info
 		}
 	}
@@ -120,12 +145,15 @@ func (i *DOJInformation) EarliestProp64ConvictionDateInThisCounty(county string)
 			}
 		}
 	}
+	if len(convictionDates) == 0 {
+		return time.Time{}
+	}
 	sort.Sort(convictionDates)
 	return convictionDates[0]
 }
 
 func (i *DOJInformation) CountIndividualsWithFelony() int {
-	return i.countIndividualsFilteredByConviction(isFelonyFilter)
+	return i.countIndividualsFilteredByConviction(isCurrentFelonyFilter)
 }
 
 func (i *DOJInformation) CountIndividualsWithConviction() int {
@@ -136,8 +164,38 @@ func (i *DOJInformation) CountIndividualsWithConvictionInLast7Years() int {
 	return i.countIndividualsFilteredByConviction(occurredInLast7YearsFilter)
 }
 
+func (i *DOJInformation) CountIndividualsWithProp64ConvictionInCounty(county string) int {
+	return i.countIndividualsFilteredByConviction(func(conviction *DOJRow) bool {
+		return isProp64ConvictionInCounty(county, conviction)
+	})
+}
+
+func (i *DOJInformation) CountProp64FelonyConvictionsInCounty(county string) int {
+	return i.countProp64ConvictionsInCounty(county, isFelonyFilter)
+}
+
+func (i *DOJInformation) CountProp64MisdemeanorConvictionsInCounty(county string) int {
+	return i.countProp64ConvictionsInCounty(county, func(conviction *DOJRow) bool {
+		return !conviction.IsFelony
+	})
+}
+
+func (i *DOJInformation) CountIndividualsWithSomeRelief(eligibilities map[int]*EligibilityInfo) int {
+	countIndividuals := 0
+OuterLoop:
+	for _, subject := range i.Subjects {
+		for _, conviction := range subject.Convictions {
+			if eligibilities[conviction.Index] != nil && reducedOrDismissedFilter(eligibilities[conviction.Index]) {
+				countIndividuals++
+				continue OuterLoop
+			}
+		}
+	}
+	return countIndividuals
+}
+
 func (i *DOJInformation) CountIndividualsNoLongerHaveFelony(eligibilities map[int]*EligibilityInfo) int {
-	return i.countIndividualsFilteredByFullRelief(eligibilities, isFelonyFilter, reducedOrDismissedFilter)
+	return i.countIndividualsFilteredByFullRelief(eligibilities, isCurrentFelonyFilter, reducedOrDismissedFilter)
 }
 
 func (i *DOJInformation) CountIndividualsNoLongerHaveConviction(eligibilities map[int]*EligibilityInfo) int {
@@ -149,31 +207,29 @@ func (i *DOJInformation) CountIndividualsNoLongerHaveConvictionInLast7Years(elig
 }
 
 func NewDOJInformation(dojFileName string, comparisonTime time.Time, eligibilityFlow EligibilityFlow) (*DOJInformation, error) {
-	dojFile, err := os.Open(dojFileName)
+	dojFile, err := OpenDOJFile(dojFileName)
 	if err != nil {
 		return nil, err
 	}
+	defer dojFile.Close()
 
-	bufferedReader := bufio.NewReader(dojFile)
-	sourceCSV := csv.NewReader(bufferedReader)
-
-	hasHeaders, err := includesHeaders(bufferedReader)
+	rowReader, layout, err := newDOJRowReader(dojFile, AutoDetectFormat, DefaultDOJSchema)
 	if err != nil {
 		return nil, err
 	}
-	if hasHeaders {
-		bufferedReader.ReadLine() // read and discard header row
-	}
 
-	rows, err := sourceCSV.ReadAll()
+	validatingReader := newValidatingRowReader(rowReader, layout)
+	rows, err := readAllRows(validatingReader)
 	if err != nil {
 		return nil, err
 	}
 	info := DOJInformation{
 		Rows:                 rows,
+		RejectedRows:         validatingReader.takeRejectedRows(),
 		Subjects:             make(map[string]*Subject),
 		comparisonTime:       comparisonTime,
 		checksRelatedCharges: eligibilityFlow.ChecksRelatedCharges(),
+		rejectedRowNumbers:   validatingReader.rejectedRowNumbers,
 	}
 
 	info.aggregateSubjects()
@@ -221,6 +277,18 @@ func (i *DOJInformation) countByCodeSectionAndEligibilityFilteredMatchedConvicti
 	return convictionMap
 }
 
+func (i *DOJInformation) countProp64ConvictionsInCounty(county string, filter func(conviction *DOJRow) bool) int {
+	countConvictions := 0
+	for _, subject := range i.Subjects {
+		for _, conviction := range subject.Convictions {
+			if isProp64ConvictionInCounty(county, conviction) && filter(conviction) {
+				countConvictions++
+			}
+		}
+	}
+	return countConvictions
+}
+
 func (i *DOJInformation) countIndividualsFilteredByConviction(filter func(conviction *DOJRow) bool) int {
 	countIndividuals := 0
 OuterLoop:
@@ -291,20 +359,30 @@ func countyFilter(county string, conviction *DOJRow) bool {
 	return conviction.County == county
 }
 
+func isProp64ConvictionInCounty(county string, conviction *DOJRow) bool {
+	return countyFilter(county, conviction) && matchers.IsProp64Charge(conviction.CodeSection)
+}
+
 func emptyFilter(_ string, _ *DOJRow) bool {
 	return true
 }
 
+// Convictions that a later court action already dismissed or vacated are no
+// longer on the record, and ones that it reduced are no longer felonies.
 func hasConvictionFilter(conviction *DOJRow) bool {
-	return conviction != nil
+	return conviction != nil && !conviction.Relief.removesConviction()
 }
 
 func isFelonyFilter(conviction *DOJRow) bool {
 	return conviction.IsFelony
 }
 
+func isCurrentFelonyFilter(conviction *DOJRow) bool {
+	return conviction.IsFelony && !conviction.Relief.removesFelony()
+}
+
 func occurredInLast7YearsFilter(conviction *DOJRow) bool {
-	return conviction.OccurredInLast7Years()
+	return conviction.OccurredInLast7Years() && !conviction.Relief.removesConviction()
 }
 
 func reducedOrDismissedFilter(eligibility *EligibilityInfo) bool {
@@ -317,18 +395,27 @@ func dismissedFilter(eligibility *EligibilityInfo) bool {
 	return determination == "Eligible for Dismissal"
 }
 
+// isHeaderRow treats a row naming any DOJ column as a header row, so a file
+// whose columns were reordered or dropped is still read by its header.
 func isHeaderRow(rowString string) bool {
-	return strings.HasPrefix(rowString, "RECORD_ID")
+	for _, field := range strings.Split(rowString, ",") {
+		if _, ok := dojColumnsByName[normalizeColumnName(strings.Trim(field, "\"\r"))]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func includesHeaders(reader *bufio.Reader) (bool, error) {
-	firstRowBytes, err := reader.Peek(128)
-
-	if err != nil {
+	firstRowBytes, err := reader.Peek(reader.Size())
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
 		return false, err
 	}
 
 	firstRow := string(firstRowBytes)
+	if newline := strings.IndexByte(firstRow, '\n'); newline >= 0 {
+		firstRow = firstRow[:newline]
+	}
 
 	return isHeaderRow(firstRow), nil
 }
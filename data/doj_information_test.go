@@ -166,6 +166,15 @@ var _ = Describe("DojInformation", func() {
 				It("Calculates individuals with any conviction in the last 7 years", func() {
 					Expect(dojInformation.CountIndividualsWithConvictionInLast7Years()).To(Equal(5))
 				})
+
+				It("Calculates individuals with a Prop64 conviction in this county", func() {
+					Expect(dojInformation.CountIndividualsWithProp64ConvictionInCounty(county)).To(Equal(10))
+				})
+
+				It("Counts Prop64 felony and misdemeanor convictions in this county", func() {
+					Expect(dojInformation.CountProp64FelonyConvictionsInCounty(county)).To(Equal(13))
+					Expect(dojInformation.CountProp64MisdemeanorConvictionsInCounty(county)).To(Equal(3))
+				})
 			})
 
 			Context("After eligibility is run", func() {
@@ -180,6 +189,10 @@ var _ = Describe("DojInformation", func() {
 				It("Calculates individuals who no longer have any conviction in the last 7 years", func() {
 					Expect(dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(dojEligibilities)).To(Equal(0))
 				})
+
+				It("Calculates individuals with relief for at least one conviction", func() {
+					Expect(dojInformation.CountIndividualsWithSomeRelief(dojEligibilities)).To(Equal(7))
+				})
 			})
 
 		})
@@ -0,0 +1,246 @@
+package data
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// StdinFileName is the DOJ file name that reads the DOJ file from stdin.
+const StdinFileName = "-"
+
+// ZipEntrySeparator joins the name of a zip archive and the name of a file in
+// it into a DOJ file name, ex: "cadoj.zip!part_1.csv".
+const ZipEntrySeparator = "!"
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+	// An archive without any files starts with its end of central directory.
+	emptyZipMagic = []byte("PK\x05\x06")
+)
+
+func isZip(magic []byte) bool {
+	return bytes.HasPrefix(magic, zipMagic) || bytes.HasPrefix(magic, emptyZipMagic)
+}
+
+// stdin is streamed in a single pass, so memory stays bounded by the largest
+// subject history however large the file piped in is. It can only be opened
+// once: its rows cannot be counted up front, identities cannot be resolved in
+// a pass of their own, and zip archives, which are read out of order, cannot
+// be read from it.
+var stdin struct {
+	sync.Mutex
+	opened bool
+}
+
+func openStdin() (io.ReadCloser, error) {
+	stdin.Lock()
+	defer stdin.Unlock()
+	if stdin.opened {
+		return nil, fmt.Errorf("%s: stdin can only be read once", StdinFileName)
+	}
+	stdin.opened = true
+	return ioutil.NopCloser(os.Stdin), nil
+}
+
+// OpenDOJFile opens a DOJ file for reading. Files compressed with gzip are
+// decompressed as they are read, detected by their first bytes rather than
+// their extension, so nothing is decompressed to disk. A zip archive holding
+// a single file opens that file, and a file in an archive can be named with
+// ZipEntrySeparator. StdinFileName reads stdin, which can be opened once.
+func OpenDOJFile(dojFileName string) (io.ReadCloser, error) {
+	if dojFileName == StdinFileName {
+		contents, err := openStdin()
+		if err != nil {
+			return nil, err
+		}
+		return openDOJContents(dojFileName, contents, nil, 0)
+	}
+
+	file, err := os.Open(dojFileName)
+	if os.IsNotExist(err) {
+		if archive, entry, ok := splitZipEntryName(dojFileName); ok {
+			return openZipEntry(archive, entry)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return openDOJContents(dojFileName, file, file, info.Size())
+}
+
+// openDOJContents decompresses the contents of a DOJ file when they are
+// compressed. readerAt is the same contents, for reading zip archives, and is
+// nil for stdin.
+func openDOJContents(dojFileName string, contents io.ReadCloser, readerAt io.ReaderAt, size int64) (io.ReadCloser, error) {
+	bufferedContents := bufio.NewReader(contents)
+	magic, _ := bufferedContents.Peek(len(zipMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(bufferedContents)
+		if err != nil {
+			contents.Close()
+			return nil, fmt.Errorf("%s: %v", dojFileName, err)
+		}
+		return readCloser{gzipReader, contents}, nil
+	case isZip(magic):
+		if readerAt == nil {
+			contents.Close()
+			return nil, fmt.Errorf("%s is a zip archive: Zip archives cannot be read from stdin, name the archive in --input-doj instead", dojFileName)
+		}
+		archive, err := zip.NewReader(readerAt, size)
+		if err != nil {
+			contents.Close()
+			return nil, fmt.Errorf("%s: %v", dojFileName, err)
+		}
+		files := zipDOJFiles(archive)
+		if len(files) != 1 {
+			contents.Close()
+			return nil, fmt.Errorf("%s is a zip archive of %d files: Expand it with ExpandDOJFiles", dojFileName, len(files))
+		}
+		return openZipFile(dojFileName, files[0], contents)
+	}
+	return readCloser{bufferedContents, contents}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// ExpandDOJFiles replaces each zip archive in dojFileNames by the names of the
+// files it holds, in the order they are stored, so each is processed as a DOJ
+// file of its own. Files that cannot be opened are left for processing to
+// report.
+func ExpandDOJFiles(dojFileNames []string) ([]string, error) {
+	var expanded []string
+	for _, dojFileName := range dojFileNames {
+		entries, isZip, err := zipArchiveEntries(dojFileName)
+		if err != nil {
+			return nil, err
+		}
+		if !isZip {
+			expanded = append(expanded, dojFileName)
+			continue
+		}
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("%s is a zip archive without any files", dojFileName)
+		}
+		for _, entry := range entries {
+			expanded = append(expanded, dojFileName+ZipEntrySeparator+entry)
+		}
+	}
+	return expanded, nil
+}
+
+// zipArchiveEntries returns the names of the files in dojFileName when it is a
+// zip archive.
+func zipArchiveEntries(dojFileName string) ([]string, bool, error) {
+	// Stdin is left for OpenDOJFile to stream, which rejects zip archives.
+	if dojFileName == StdinFileName {
+		return nil, false, nil
+	}
+	file, err := os.Open(dojFileName)
+	if err != nil {
+		return nil, false, nil
+	}
+	defer file.Close()
+	magic := make([]byte, len(zipMagic))
+	_, err = io.ReadFull(file, magic)
+	if err != nil || !isZip(magic) {
+		return nil, false, nil
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, true, err
+	}
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return nil, true, fmt.Errorf("%s: %v", dojFileName, err)
+	}
+
+	var entries []string
+	for _, file := range zipDOJFiles(archive) {
+		entries = append(entries, file.Name)
+	}
+	return entries, true, nil
+}
+
+// zipDOJFiles are the files of a zip archive, leaving out folders and the
+// resource forks macOS adds to archives.
+func zipDOJFiles(archive *zip.Reader) []*zip.File {
+	var files []*zip.File
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// splitZipEntryName splits a DOJ file name made by ExpandDOJFiles into the
+// archive and the name of the file in it.
+func splitZipEntryName(dojFileName string) (string, string, bool) {
+	for i := 0; i < len(dojFileName); i++ {
+		if !strings.HasPrefix(dojFileName[i:], ZipEntrySeparator) {
+			continue
+		}
+		archive := dojFileName[:i]
+		if info, err := os.Stat(archive); err == nil && info.Mode().IsRegular() {
+			return archive, dojFileName[i+len(ZipEntrySeparator):], true
+		}
+	}
+	return "", "", false
+}
+
+func openZipEntry(archiveName string, entry string) (io.ReadCloser, error) {
+	archiveFile, err := zip.OpenReader(archiveName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", archiveName, err)
+	}
+
+	for _, file := range zipDOJFiles(&archiveFile.Reader) {
+		if file.Name == entry {
+			return openZipFile(archiveName+ZipEntrySeparator+entry, file, archiveFile)
+		}
+	}
+	archiveFile.Close()
+	return nil, fmt.Errorf("%s has no file %s", archiveName, entry)
+}
+
+// openZipFile opens a file of a zip archive. Closing it closes the archive.
+func openZipFile(dojFileName string, file *zip.File, archiveCloser io.Closer) (io.ReadCloser, error) {
+	entryReader, err := file.Open()
+	if err != nil {
+		archiveCloser.Close()
+		return nil, fmt.Errorf("%s: %v", dojFileName, err)
+	}
+	return readCloser{entryReader, multiCloser{entryReader, archiveCloser}}, nil
+}
+
+type multiCloser []io.Closer
+
+func (closers multiCloser) Close() error {
+	var firstErr error
+	for _, closer := range closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,104 @@
+package data_test
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+	. "gogen_pilots/test_fixtures"
+
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+var _ = Describe("OpenDOJFile", func() {
+	var (
+		pathToDOJ   string
+		dojContents []byte
+		tempDir     string
+		err         error
+	)
+
+	BeforeEach(func() {
+		pathToDOJ, _, err = ExtractFullCSVFixtures(path.Join("..", "test_fixtures", "los_angeles.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+		dojContents, err = ioutil.ReadFile(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		tempDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	writeZip := func(name string, entries ...string) string {
+		archivePath := path.Join(tempDir, name)
+		archiveFile, err := os.Create(archivePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer archiveFile.Close()
+		archive := zip.NewWriter(archiveFile)
+		for _, entry := range entries {
+			entryWriter, err := archive.Create(entry)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = entryWriter.Write(dojContents)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(archive.Close()).To(Succeed())
+		return archivePath
+	}
+
+	It("decompresses a gzip file whatever its name", func() {
+		gzipPath := path.Join(tempDir, "cadoj_extract")
+		gzipFile, err := os.Create(gzipPath)
+		Expect(err).ToNot(HaveOccurred())
+		gzipWriter := gzip.NewWriter(gzipFile)
+		_, err = gzipWriter.Write(dojContents)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gzipWriter.Close()).To(Succeed())
+		Expect(gzipFile.Close()).To(Succeed())
+
+		dojFile, err := data.OpenDOJFile(gzipPath)
+		Expect(err).ToNot(HaveOccurred())
+		contents, err := ioutil.ReadAll(dojFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dojFile.Close()).To(Succeed())
+		Expect(contents).To(Equal(dojContents))
+
+		Expect(data.CountDOJRows(gzipPath)).To(Equal(35))
+		dojReader, err := data.NewDOJReader(gzipPath, data.AutoDetectFormat, data.DefaultDOJSchema, time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC), data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+		defer dojReader.Close()
+		dojInformation, err := dojReader.NextSubject()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dojInformation.TotalRows()).To(Equal(6))
+	})
+
+	It("opens the file of a zip archive holding one file", func() {
+		archivePath := writeZip("cadoj.zip", "cadoj.csv")
+
+		Expect(data.ExpandDOJFiles([]string{archivePath})).To(Equal([]string{archivePath + "!cadoj.csv"}))
+		Expect(data.CountDOJRows(archivePath)).To(Equal(35))
+	})
+
+	It("expands a zip archive into its files, which open by name", func() {
+		archivePath := writeZip("cadoj.zip", "part_1.csv", "__MACOSX/._part_1.csv", "part_2.csv")
+
+		dojFiles, err := data.ExpandDOJFiles([]string{pathToDOJ, archivePath})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dojFiles).To(Equal([]string{pathToDOJ, archivePath + "!part_1.csv", archivePath + "!part_2.csv"}))
+
+		Expect(data.CountDOJRows(dojFiles[2])).To(Equal(35))
+
+		_, err = data.OpenDOJFile(archivePath)
+		Expect(err).To(MatchError(ContainSubstring("is a zip archive of 2 files")))
+		_, err = data.OpenDOJFile(archivePath + "!part_3.csv")
+		Expect(err).To(MatchError(ContainSubstring("has no file part_3.csv")))
+	})
+
+	It("fails to expand an empty zip archive", func() {
+		archivePath := writeZip("empty.zip")
+
+		_, err := data.ExpandDOJFiles([]string{archivePath})
+		Expect(err).To(MatchError(ContainSubstring("is a zip archive without any files")))
+	})
+})
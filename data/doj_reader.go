@@ -0,0 +1,310 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// DOJReader streams a DOJ file one subject at a time. Rows for a subject must
+// be contiguous in the file, which is how DOJ research files are delivered.
+// A SUBJECT_ID that appears again after rows of other subjects is an error,
+// rather than two partial histories that could each be found eligible.
+type DOJReader struct {
+	dojFile              io.ReadCloser
+	rowReader            *validatingRowReader
+	pendingRow           []string
+	readSubjectIDs       map[string]bool
+	comparisonTime       time.Time
+	checksRelatedCharges bool
+	identities           *IdentityResolution
+	heldIdentities       map[string]*heldIdentity
+}
+
+// heldIdentity collects the rows of a merged identity until all of its
+// SUBJECT_IDs have been read.
+type heldIdentity struct {
+	rows       [][]string
+	subjectIDs int
+}
+
+// NewDOJReader opens a DOJ file for streaming. Its columns are read by the
+// names in its header row, or in the order of schema when it has none.
+func NewDOJReader(dojFileName string, format DOJFileFormat, schema DOJSchema, comparisonTime time.Time, eligibilityFlow EligibilityFlow) (*DOJReader, error) {
+	dojFile, err := OpenDOJFile(dojFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	rowReader, layout, err := newDOJRowReader(dojFile, format, schema)
+	if err != nil {
+		dojFile.Close()
+		return nil, fmt.Errorf("%s: %v", dojFileName, err)
+	}
+
+	return &DOJReader{
+		dojFile:              dojFile,
+		rowReader:            newValidatingRowReader(rowReader, layout),
+		readSubjectIDs:       make(map[string]bool),
+		comparisonTime:       comparisonTime,
+		checksRelatedCharges: eligibilityFlow.ChecksRelatedCharges(),
+	}, nil
+}
+
+// NextSubject returns the rows of the next subject in the file, aggregated
+// into a DOJInformation of their own. It returns io.EOF when the file is
+// exhausted.
+//
+// When identities are resolved, the rows of SUBJECT_IDs merged into one
+// identity are held until the last of them is read, and returned together.
+func (r *DOJReader) NextSubject() (*DOJInformation, error) {
+	for {
+		rows, err := r.readSubjectRows()
+		if err == io.EOF {
+			if rows = r.takeHeldIdentity(); rows != nil {
+				return r.newSubjectInformation(rows), nil
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.identities == nil {
+			return r.newSubjectInformation(rows), nil
+		}
+
+		mergedSubjectIDs := r.identities.MergedSubjectIDs(rows[0][SUBJECT_ID])
+		if mergedSubjectIDs == nil {
+			return r.newSubjectInformation(rows), nil
+		}
+		resolvedSubjectID := r.identities.ResolvedSubjectID(rows[0][SUBJECT_ID])
+		held := r.heldIdentities[resolvedSubjectID]
+		if held == nil {
+			held = &heldIdentity{}
+			r.heldIdentities[resolvedSubjectID] = held
+		}
+		held.rows = append(held.rows, rows...)
+		held.subjectIDs++
+		if held.subjectIDs == len(mergedSubjectIDs) {
+			delete(r.heldIdentities, resolvedSubjectID)
+			return r.newSubjectInformation(held.rows), nil
+		}
+	}
+}
+
+// ResolveIdentities merges the histories of SUBJECT_IDs that belong to the
+// same identity. Call it before reading any subjects.
+func (r *DOJReader) ResolveIdentities(identities *IdentityResolution) {
+	r.identities = identities
+	r.heldIdentities = make(map[string]*heldIdentity)
+}
+
+func (r *DOJReader) readSubjectRows() ([][]string, error) {
+	var rows [][]string
+
+	if r.pendingRow != nil {
+		rows = append(rows, r.pendingRow)
+		r.pendingRow = nil
+	} else {
+		row, err := r.readRow()
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	subjectID := rows[0][SUBJECT_ID]
+	if r.readSubjectIDs[subjectID] {
+		return nil, fmt.Errorf("SUBJECT_ID %s appears again at row %d, after rows of other subjects: The rows of each subject must be contiguous, sort the DOJ file by SUBJECT_ID", subjectID, r.rowReader.rowsRead)
+	}
+	r.readSubjectIDs[subjectID] = true
+	for {
+		row, err := r.readRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row[SUBJECT_ID] != subjectID {
+			r.pendingRow = row
+			break
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// takeHeldIdentity returns the rows of an identity that is still held at the
+// end of the file, so no rows are dropped when the file does not match its
+// identity resolution.
+func (r *DOJReader) takeHeldIdentity() [][]string {
+	var resolvedSubjectIDs []string
+	for resolvedSubjectID := range r.heldIdentities {
+		resolvedSubjectIDs = append(resolvedSubjectIDs, resolvedSubjectID)
+	}
+	if len(resolvedSubjectIDs) == 0 {
+		return nil
+	}
+	sort.Strings(resolvedSubjectIDs)
+	held := r.heldIdentities[resolvedSubjectIDs[0]]
+	delete(r.heldIdentities, resolvedSubjectIDs[0])
+	return held.rows
+}
+
+func (r *DOJReader) newSubjectInformation(rows [][]string) *DOJInformation {
+	info := DOJInformation{
+		Rows:                 rows,
+		Subjects:             make(map[string]*Subject),
+		comparisonTime:       r.comparisonTime,
+		checksRelatedCharges: r.checksRelatedCharges,
+		identities:           r.identities,
+		rejectedRowNumbers:   make(map[string][]int),
+	}
+	for _, row := range rows {
+		if rowNumbers := r.rowReader.takeRejectedRowNumbers(row[SUBJECT_ID]); rowNumbers != nil {
+			info.addRejectedRowNumbers(row[SUBJECT_ID], rowNumbers)
+		}
+	}
+	info.pushRows()
+	return &info
+}
+
+// RowsRead is the number of data rows read from the file so far, including
+// rejected rows.
+func (r *DOJReader) RowsRead() int {
+	return r.rowReader.rowsRead
+}
+
+// TakeRejectedRows returns the rows that failed validation since it was last
+// called.
+func (r *DOJReader) TakeRejectedRows() []RejectedRow {
+	return r.rowReader.takeRejectedRows()
+}
+
+// ExtraColumns are the names of the columns in the file's header row that are
+// not DOJ columns. Rows read from the file end with their values.
+func (r *DOJReader) ExtraColumns() []string {
+	return r.rowReader.layout.extraColumnNames
+}
+
+func (r *DOJReader) Close() error {
+	return r.dojFile.Close()
+}
+
+func (r *DOJReader) readRow() ([]string, error) {
+	return r.rowReader.Read()
+}
+
+// CountDOJRows counts the data rows in a DOJ file without parsing them, so
+// progress can be reported while the file is streamed.
+func CountDOJRows(dojFileName string) (int, error) {
+	dojFile, err := OpenDOJFile(dojFileName)
+	if err != nil {
+		return 0, err
+	}
+	defer dojFile.Close()
+
+	bufferedReader := bufio.NewReaderSize(dojFile, dojReaderBufferSize)
+	hasHeaders, err := includesHeaders(bufferedReader)
+	if err != nil {
+		return 0, err
+	}
+
+	lines := 0
+	endsWithNewline := true
+	buffer := make([]byte, 64*1024)
+	for {
+		n, err := bufferedReader.Read(buffer)
+		if n > 0 {
+			lines += bytes.Count(buffer[:n], []byte{'\n'})
+			endsWithNewline = buffer[n-1] == '\n'
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if !endsWithNewline {
+		lines++
+	}
+	if hasHeaders && lines > 0 {
+		lines--
+	}
+	return lines, nil
+}
+
+// dojReaderBufferSize is large enough to hold the header row of a DOJ file,
+// so it can be detected before it is read.
+const dojReaderBufferSize = 64 * 1024
+
+// newDOJRowReader returns a reader for the rows of a DOJ file, and the layout
+// of its columns: the ones in its header row, or the ones of schema.
+func newDOJRowReader(dojFile io.Reader, format DOJFileFormat, schema DOJSchema) (dojRowReader, *dojColumnLayout, error) {
+	bufferedReader := bufio.NewReaderSize(dojFile, dojReaderBufferSize)
+
+	if format == AutoDetectFormat {
+		format = detectDOJFileFormat(bufferedReader)
+	}
+	switch format {
+	case FixedWidthFormat:
+		if schema.Name != DefaultDOJSchemaName {
+			return nil, nil, fmt.Errorf("the fixed-width DOJ file format is only defined for DOJ schema %s", DefaultDOJSchemaName)
+		}
+		layout, err := newDOJColumnLayout(schema.Columns, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		return newFixedWidthReader(bufferedReader), layout, nil
+	case CSVFormat:
+		return newDOJCSVReader(bufferedReader, schema)
+	}
+	return nil, nil, fmt.Errorf("unknown DOJ file format %q", format)
+}
+
+func newDOJCSVReader(bufferedReader *bufio.Reader, schema DOJSchema) (*csv.Reader, *dojColumnLayout, error) {
+	sourceCSV := csv.NewReader(bufferedReader)
+	sourceCSV.FieldsPerRecord = -1 // column counts are checked by validatingRowReader
+
+	hasHeaders, err := includesHeaders(bufferedReader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !hasHeaders {
+		layout, err := newDOJColumnLayout(schema.Columns, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sourceCSV, layout, nil
+	}
+
+	headers, err := sourceCSV.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	layout, err := newDOJColumnLayout(headers, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sourceCSV, layout, nil
+}
+
+func readAllRows(rowReader *validatingRowReader) ([][]string, error) {
+	var rows [][]string
+	for {
+		row, err := rowReader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
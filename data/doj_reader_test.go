@@ -0,0 +1,165 @@
+package data_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+	. "gogen_pilots/test_fixtures"
+
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var _ = Describe("DOJReader", func() {
+	var (
+		pathToDOJ      string
+		comparisonTime time.Time
+		dojReader      *data.DOJReader
+		err            error
+	)
+
+	BeforeEach(func() {
+		inputPath := path.Join("..", "test_fixtures", "los_angeles.xlsx")
+		pathToDOJ, _, err = ExtractFullCSVFixtures(inputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		comparisonTime = time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+		dojReader, err = data.NewDOJReader(pathToDOJ, data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		dojReader.Close()
+	})
+
+	It("reads one subject at a time", func() {
+		dojInformation, err := dojReader.NextSubject()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(dojInformation.TotalRows()).To(Equal(6))
+		Expect(dojInformation.TotalIndividuals()).To(Equal(1))
+		Expect(dojInformation.Subjects).To(HaveKey("18675309"))
+		Expect(dojReader.RowsRead()).To(Equal(7))
+	})
+
+	It("reads every row and subject in the file before returning EOF", func() {
+		totalRows := 0
+		subjectIDs := make(map[string]bool)
+		for {
+			dojInformation, err := dojReader.NextSubject()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			totalRows += dojInformation.TotalRows()
+			for subjectID := range dojInformation.Subjects {
+				Expect(subjectIDs).ToNot(HaveKey(subjectID))
+				subjectIDs[subjectID] = true
+			}
+		}
+
+		Expect(totalRows).To(Equal(35))
+		Expect(dojReader.RowsRead()).To(Equal(35))
+		Expect(len(subjectIDs)).To(Equal(10))
+	})
+
+	It("matches the subjects read into memory all at once", func() {
+		dojInformation, err := data.NewDOJInformation(pathToDOJ, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+
+		for {
+			subjectInformation, err := dojReader.NextSubject()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			for subjectID, subject := range subjectInformation.Subjects {
+				Expect(len(subject.Convictions)).To(Equal(len(dojInformation.Subjects[subjectID].Convictions)))
+			}
+		}
+	})
+
+	It("fails when a subject's rows are not contiguous", func() {
+		dojContents, err := ioutil.ReadFile(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		lines := strings.SplitAfter(string(dojContents), "\n")
+
+		// Move the first row of the second subject between rows of the first.
+		reordered := append([]string{}, lines[0:4]...)
+		reordered = append(reordered, lines[7])
+		reordered = append(reordered, lines[4:7]...)
+		reordered = append(reordered, lines[8:]...)
+		unsortedDOJ, err := ioutil.TempFile("", "unsorted_doj")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(unsortedDOJ.Name())
+		unsortedDOJ.WriteString(strings.Join(reordered, ""))
+		unsortedDOJ.Close()
+
+		unsortedReader, err := data.NewDOJReader(unsortedDOJ.Name(), data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+		defer unsortedReader.Close()
+
+		for {
+			_, err = unsortedReader.NextSubject()
+			if err != nil {
+				break
+			}
+		}
+		Expect(err).To(MatchError("SUBJECT_ID 18675309 appears again at row 5, after rows of other subjects: The rows of each subject must be contiguous, sort the DOJ file by SUBJECT_ID"))
+	})
+
+	It("sends the eligible convictions of a subject with rejected rows to hand review", func() {
+		dojFile, err := os.Open(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		rows, err := csv.NewReader(dojFile).ReadAll()
+		dojFile.Close()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Reject the 503 VC conviction on row 3, which the flow cannot see.
+		for column, header := range rows[0] {
+			if header == "CNT_ORDER" {
+				rows[2][column] = "1010010"
+			}
+		}
+		rejectedDOJ, err := ioutil.TempFile("", "rejected_doj")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(rejectedDOJ.Name())
+		Expect(csv.NewWriter(rejectedDOJ).WriteAll(rows)).To(Succeed())
+		rejectedDOJ.Close()
+
+		rejectedReader, err := data.NewDOJReader(rejectedDOJ.Name(), data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+		defer rejectedReader.Close()
+
+		dojInformation, err := rejectedReader.NextSubject()
+		Expect(err).ToNot(HaveOccurred())
+		eligibilities := dojInformation.DetermineEligibility("LOS ANGELES", data.EligibilityFlows["LOS ANGELES"], 50, 10)
+		Expect(eligibilities).To(HaveLen(1))
+		for _, info := range eligibilities {
+			Expect(info.EligibilityDetermination).To(Equal("Hand Review"))
+			Expect(info.EligibilityReason).To(Equal("Rows of this subject failed validation: 3"))
+			Expect(info.TraceSummary()).To(HaveSuffix("RowsFailedValidation: yes (rows=[3])"))
+		}
+
+		dojInformation, err = rejectedReader.NextSubject()
+		Expect(err).ToNot(HaveOccurred())
+		for _, info := range dojInformation.DetermineEligibility("LOS ANGELES", data.EligibilityFlows["LOS ANGELES"], 50, 10) {
+			Expect(info.EligibilityReason).ToNot(ContainSubstring("failed validation"))
+		}
+	})
+
+	It("counts the rows in a file", func() {
+		totalRows, err := data.CountDOJRows(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(totalRows).To(Equal(35))
+
+		totalRows, err = data.CountDOJRows(path.Join("..", "test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(totalRows).To(Equal(38))
+	})
+})
@@ -1,80 +1,80 @@
 package data
 
 import (
-	"strconv"
+	"gogen_pilots/matchers"
 	"strings"
 	"time"
 )
 
 type DOJRow struct {
-	SubjectID              string
-	DOB                    time.Time
-	Name                   string
-	WasConvicted           bool
-	CodeSection            string
-	DispositionDate        time.Time
-	OFN                    string
-	Type                   string
-	IsPC290Registration    bool
-	County                 string
-	IsFelony               bool
-	NumCrtCase             string
-	CourtNoParts           []string
-	CountOrder             string
-	Index                  int
-	SentenceEndDate        time.Time
-	SentencePartDuration   time.Duration
-	HasProp64ChargeInCycle bool
+	SubjectID                string
+	DOB                      time.Time
+	DOBPrecision             DatePrecision
+	Name                     string
+	WasConvicted             bool
+	CodeSection              string
+	DispositionDate          time.Time
+	DispositionDatePrecision DatePrecision
+	OFN                      string
+	Type                     string
+	IsPC290Registration      bool
+	County                   string
+	IsFelony                 bool
+	NumCrtCase               string
+	CourtNoParts             []string
+	CountOrder               string
+	Index                    int
+	Sentence                 Sentence
+	SentenceEndDate          time.Time
+	Relief                   Relief
+	HasProp64ChargeInCycle   bool
 }
 
 const dateFormat = "20060102"
 
 func NewDOJRow(rawRow []string, index int) DOJRow {
+	dob, dobPrecision := parseDate(rawRow[PRI_DOB])
+	dispositionDate, dispositionDatePrecision := parseDate(rawRow[STP_EVENT_DATE])
+	sentence := newSentence(newSentenceSegment(rawRow))
 
 	return DOJRow{
-		Name:                 rawRow[PRI_NAME],
-		SubjectID:            rawRow[SUBJECT_ID],
-		DOB:                  parseDate(dateFormat, rawRow[PRI_DOB]),
-		WasConvicted:         strings.HasPrefix(rawRow[DISP_DESCR], "CONVICTED"),
-		CodeSection:          findCodeSection(rawRow),
-		DispositionDate:      parseDate(dateFormat, rawRow[STP_EVENT_DATE]),
-		OFN:                  rawRow[OFN],
-		Type:                 rawRow[STP_TYPE_DESCR],
-		IsPC290Registration:  rawRow[STP_TYPE_DESCR] == "REGISTRATION" && strings.HasPrefix(rawRow[OFFENSE_DESCR], "290"),
-		County:               rawRow[STP_ORI_CNTY_NAME],
-		IsFelony:             isFelony(rawRow),
-		CountOrder:           rawRow[CNT_ORDER],
-		Index:                index,
-		SentenceEndDate:      getSentenceEndDate(rawRow),
-		SentencePartDuration: getSentencePartDuration(rawRow),
+		Name:                     rawRow[PRI_NAME],
+		SubjectID:                rawRow[SUBJECT_ID],
+		DOB:                      dob,
+		DOBPrecision:             dobPrecision,
+		WasConvicted:             strings.HasPrefix(rawRow[DISP_DESCR], "CONVICTED"),
+		CodeSection:              findCodeSection(rawRow),
+		DispositionDate:          dispositionDate,
+		DispositionDatePrecision: dispositionDatePrecision,
+		OFN:                      rawRow[OFN],
+		Type:                     rawRow[STP_TYPE_DESCR],
+		IsPC290Registration:      rawRow[STP_TYPE_DESCR] == "REGISTRATION" && strings.HasPrefix(rawRow[OFFENSE_DESCR], "290"),
+		County:                   rawRow[STP_ORI_CNTY_NAME],
+		IsFelony:                 isFelony(rawRow),
+		CountOrder:               rawRow[CNT_ORDER],
+		Index:                    index,
+		Sentence:                 sentence,
+		SentenceEndDate:          sentence.EndDate(dispositionDate),
+		Relief:                   newRelief(rawRow, dispositionDate, dispositionDatePrecision),
 	}
 }
 
-func isFelony(rawRow []string) bool {
-	return rawRow[CONV_STAT_DESCR] == "FELONY" || (rawRow[CONV_STAT_DESCR] == "" && rawRow[OFFENSE_TOC] == "F")
+// Cycles, cases and counts are numbered within a SUBJECT_ID, so their keys
+// include it to keep them apart when identities are merged.
+func (row *DOJRow) cycleKey() string {
+	return row.SubjectID + ":" + row.CountOrder[0:3]
 }
 
-func getSentenceEndDate(rawRow []string) time.Time {
-	dispDate := parseDate(dateFormat, rawRow[STP_EVENT_DATE])
-	return dispDate.Add(getSentencePartDuration(rawRow))
+func (row *DOJRow) caseKey() string {
+	return row.SubjectID + ":" + row.CountOrder[0:6]
 }
 
-func getSentencePartDuration(rawRow []string) time.Duration {
-	sentenceLength, _ := strconv.Atoi(rawRow[SENT_LENGTH])
-
-	days := time.Duration(24) * (time.Hour)
-	years := time.Date(2012, 03, 04, 0, 0, 0, 0, time.UTC).Sub(time.Date(2011, 03, 04, 0, 0, 0, 0, time.UTC))
-	months := years / 12
+func (row *DOJRow) countKey() string {
+	return row.SubjectID + ":" + row.CountOrder
+}
 
-	switch rawRow[SENT_TIME_CODE] {
-	case "D":
-		return time.Duration(sentenceLength) * days
-	case "M":
-		return time.Duration(sentenceLength) * months
-	case "Y":
-		return time.Duration(sentenceLength) * years
-	}
-	return time.Duration(0)
+func isFelony(rawRow []string) bool {
+	return rawRow[CONV_STAT_DESCR] == "FELONY" || (rawRow[CONV_STAT_DESCR] == "" && rawRow[OFFENSE_TOC] == "F")
 }
 
 func findCodeSection(rawRow []string) string {
@@ -85,6 +85,13 @@ func findCodeSection(rawRow []string) string {
 	}
 }
 
+// ParsedCodeSection parses CodeSection so that spellings like "11357B2" and
+// "11357(B)(2) HS" can be compared.
+func (row *DOJRow) ParsedCodeSection() matchers.CodeSection {
+	codeSection, _ := matchers.ParseCodeSection(row.CodeSection)
+	return codeSection
+}
+
 func IsCodeSectionInComment(offenseDescription string) bool {
 	trimmedOffenseDescription := strings.TrimSpace(offenseDescription)
 	return trimmedOffenseDescription == "" || trimmedOffenseDescription == "SEE COMMENT FOR CHARGE"
@@ -198,10 +205,234 @@ const (
 	END_OF_REC
 )
 
-func (row *DOJRow) wasConvictionUnderAgeOf21(subject *Subject) bool {
-	return subject.DOB.AddDate(21, 0, 0).After(row.DispositionDate)
+// DOJColumnNames is the name of each column defined above, as it appears in
+// the header row of a DOJ file.
+var DOJColumnNames = [...]string{
+	RECORD_ID:             "RECORD_ID",
+	SUBJECT_STATUS:        "SUBJECT_STATUS",
+	SUBJECT_ID:            "SUBJECT_ID",
+	REQ_SEG_SEP:           "REQ_SEG_SEP",
+	REQ_CII_NUMBER:        "REQ_CII_NUMBER",
+	REQ_NAME:              "REQ_NAME",
+	REQ_GENDER:            "REQ_GENDER",
+	REQ_DOB:               "REQ_DOB",
+	REQ_CDL:               "REQ_CDL",
+	REQ_SSN:               "REQ_SSN",
+	PII_SEG_SEP:           "PII_SEG_SEP",
+	CII_NUMBER:            "CII_NUMBER",
+	PRI_NAME:              "PRI_NAME",
+	GENDER:                "GENDER",
+	PRI_DOB:               "PRI_DOB",
+	PRI_SSN:               "PRI_SSN",
+	PRI_CDL:               "PRI_CDL",
+	PRI_IDN:               "PRI_IDN",
+	PRI_INN:               "PRI_INN",
+	FBI_NUMBER:            "FBI_NUMBER",
+	PDR_SEG_SEP:           "PDR_SEG_SEP",
+	RACE_CODE:             "RACE_CODE",
+	RACE_DESCR:            "RACE_DESCR",
+	EYE_COLOR_CODE:        "EYE_COLOR_CODE",
+	EYE_COLOR_DESCR:       "EYE_COLOR_DESCR",
+	HAIR_COLOR_CODE:       "HAIR_COLOR_CODE",
+	HAIR_COLOR_DESCR:      "HAIR_COLOR_DESCR",
+	HEIGHT:                "HEIGHT",
+	WEIGHT:                "WEIGHT",
+	SINGLE_SOURCE:         "SINGLE_SOURCE",
+	MULTI_SOURCE:          "MULTI_SOURCE",
+	POB_CODE:              "POB_CODE",
+	POB_NAME:              "POB_NAME",
+	POB_TYPE:              "POB_TYPE",
+	CITIZENSHIP_LIST:      "CITIZENSHIP_LIST",
+	CYC_SEG_SEP:           "CYC_SEG_SEP",
+	CYC_ORDER:             "CYC_ORDER",
+	CYC_DATE:              "CYC_DATE",
+	STP_SEG_SEP:           "STP_SEG_SEP",
+	STP_ORDER:             "STP_ORDER",
+	STP_EVENT_DATE:        "STP_EVENT_DATE",
+	STP_TYPE_CODE:         "STP_TYPE_CODE",
+	STP_TYPE_DESCR:        "STP_TYPE_DESCR",
+	STP_ORI_TYPE:          "STP_ORI_TYPE",
+	STP_ORI_TYPE_DESCR:    "STP_ORI_TYPE_DESCR",
+	STP_ORI_CODE:          "STP_ORI_CODE",
+	STP_ORI_DESCR:         "STP_ORI_DESCR",
+	STP_ORI_CNTY_CODE:     "STP_ORI_CNTY_CODE",
+	STP_ORI_CNTY_NAME:     "STP_ORI_CNTY_NAME",
+	CNT_SEG_SEP:           "CNT_SEG_SEP",
+	CNT_ORDER:             "CNT_ORDER",
+	DISP_DATE:             "DISP_DATE",
+	OFN:                   "OFN",
+	OFFENSE_CODE:          "OFFENSE_CODE",
+	OFFENSE_DESCR:         "OFFENSE_DESCR",
+	OFFENSE_TOC:           "OFFENSE_TOC",
+	OFFENSE_QUAL_LST:      "OFFENSE_QUAL_LST",
+	DISP_OFFENSE_CODE:     "DISP_OFFENSE_CODE",
+	DISP_OFFENSE_DESCR:    "DISP_OFFENSE_DESCR",
+	DISP_OFFENSE_TOC:      "DISP_OFFENSE_TOC",
+	DISP_OFFENSE_QUAL_LST: "DISP_OFFENSE_QUAL_LST",
+	CONV_OFFENSE_ORDER:    "CONV_OFFENSE_ORDER",
+	CONV_OFFENSE_CODE:     "CONV_OFFENSE_CODE",
+	CONV_OFFENSE_DESCR:    "CONV_OFFENSE_DESCR",
+	CONV_OFFENSE_TOC:      "CONV_OFFENSE_TOC",
+	CONV_OFFENSE_QUAL_LST: "CONV_OFFENSE_QUAL_LST",
+	FE_NUM_ORDER:          "FE_NUM_ORDER",
+	FE_NUM_ARR_AGY:        "FE_NUM_ARR_AGY",
+	FE_NUM_BNCH_WARR:      "FE_NUM_BNCH_WARR",
+	FE_NUM_CITE:           "FE_NUM_CITE",
+	FE_NUM_DOCKET:         "FE_NUM_DOCKET",
+	FE_NUM_INCIDENT:       "FE_NUM_INCIDENT",
+	FE_NUM_BOOKING:        "FE_NUM_BOOKING",
+	FE_NUM_NUMBER:         "FE_NUM_NUMBER",
+	FE_NUM_REMAND:         "FE_NUM_REMAND",
+	FE_NUM_OOS_INN:        "FE_NUM_OOS_INN",
+	FE_NUM_CRT_CASE:       "FE_NUM_CRT_CASE",
+	FE_NUM_WARRANT:        "FE_NUM_WARRANT",
+	DISP_ORDER:            "DISP_ORDER",
+	DISP_CODE:             "DISP_CODE",
+	DISP_DESCR:            "DISP_DESCR",
+	CONV_STAT_CODE:        "CONV_STAT_CODE",
+	CONV_STAT_DESCR:       "CONV_STAT_DESCR",
+	SENT_SEG_SEP:          "SENT_SEG_SEP",
+	SENT_ORDER:            "SENT_ORDER",
+	SENT_LOC_CODE:         "SENT_LOC_CODE",
+	SENT_LOC_DESCR:        "SENT_LOC_DESCR",
+	SENT_LENGTH:           "SENT_LENGTH",
+	SENT_TIME_CODE:        "SENT_TIME_CODE",
+	SENT_TIME_DESCR:       "SENT_TIME_DESCR",
+	CYC_AGE:               "CYC_AGE",
+	CII_TYPE:              "CII_TYPE",
+	CII_TYPE_ALPHA:        "CII_TYPE_ALPHA",
+	COMMENT_TEXT:          "COMMENT_TEXT",
+	END_OF_REC:            "END_OF_REC",
+}
+
+// fixedWidthFieldWidths is the width of each column in a fixed-width DOJ
+// research file (.dat), in the same order as the column constants above.
+// They follow the start and end positions of the record layout in
+// test_fixtures/dat_record_layout.csv, which the fixed-width reader specs
+// check them against. That table is not transcribed from a versioned DOJ
+// record layout document, as none is kept in this repository: check it
+// against the layout DOJ sends with an extract before reading its .dat files,
+// and name that document and its version here.
+var fixedWidthFieldWidths = [...]int{
+	RECORD_ID:             2,
+	SUBJECT_STATUS:        1,
+	SUBJECT_ID:            10,
+	REQ_SEG_SEP:           1,
+	REQ_CII_NUMBER:        10,
+	REQ_NAME:              30,
+	REQ_GENDER:            1,
+	REQ_DOB:               8,
+	REQ_CDL:               8,
+	REQ_SSN:               9,
+	PII_SEG_SEP:           1,
+	CII_NUMBER:            10,
+	PRI_NAME:              30,
+	GENDER:                1,
+	PRI_DOB:               8,
+	PRI_SSN:               9,
+	PRI_CDL:               8,
+	PRI_IDN:               10,
+	PRI_INN:               10,
+	FBI_NUMBER:            9,
+	PDR_SEG_SEP:           1,
+	RACE_CODE:             1,
+	RACE_DESCR:            30,
+	EYE_COLOR_CODE:        3,
+	EYE_COLOR_DESCR:       20,
+	HAIR_COLOR_CODE:       3,
+	HAIR_COLOR_DESCR:      20,
+	HEIGHT:                3,
+	WEIGHT:                3,
+	SINGLE_SOURCE:         1,
+	MULTI_SOURCE:          1,
+	POB_CODE:              2,
+	POB_NAME:              30,
+	POB_TYPE:              1,
+	CITIZENSHIP_LIST:      60,
+	CYC_SEG_SEP:           1,
+	CYC_ORDER:             3,
+	CYC_DATE:              8,
+	STP_SEG_SEP:           1,
+	STP_ORDER:             3,
+	STP_EVENT_DATE:        8,
+	STP_TYPE_CODE:         2,
+	STP_TYPE_DESCR:        40,
+	STP_ORI_TYPE:          1,
+	STP_ORI_TYPE_DESCR:    40,
+	STP_ORI_CODE:          9,
+	STP_ORI_DESCR:         50,
+	STP_ORI_CNTY_CODE:     2,
+	STP_ORI_CNTY_NAME:     30,
+	CNT_SEG_SEP:           1,
+	CNT_ORDER:             12,
+	DISP_DATE:             8,
+	OFN:                   20,
+	OFFENSE_CODE:          5,
+	OFFENSE_DESCR:         80,
+	OFFENSE_TOC:           1,
+	OFFENSE_QUAL_LST:      60,
+	DISP_OFFENSE_CODE:     5,
+	DISP_OFFENSE_DESCR:    80,
+	DISP_OFFENSE_TOC:      1,
+	DISP_OFFENSE_QUAL_LST: 60,
+	CONV_OFFENSE_ORDER:    3,
+	CONV_OFFENSE_CODE:     5,
+	CONV_OFFENSE_DESCR:    80,
+	CONV_OFFENSE_TOC:      1,
+	CONV_OFFENSE_QUAL_LST: 60,
+	FE_NUM_ORDER:          3,
+	FE_NUM_ARR_AGY:        20,
+	FE_NUM_BNCH_WARR:      20,
+	FE_NUM_CITE:           20,
+	FE_NUM_DOCKET:         20,
+	FE_NUM_INCIDENT:       20,
+	FE_NUM_BOOKING:        20,
+	FE_NUM_NUMBER:         20,
+	FE_NUM_REMAND:         20,
+	FE_NUM_OOS_INN:        20,
+	FE_NUM_CRT_CASE:       20,
+	FE_NUM_WARRANT:        20,
+	DISP_ORDER:            3,
+	DISP_CODE:             4,
+	DISP_DESCR:            60,
+	CONV_STAT_CODE:        1,
+	CONV_STAT_DESCR:       20,
+	SENT_SEG_SEP:          1,
+	SENT_ORDER:            3,
+	SENT_LOC_CODE:         3,
+	SENT_LOC_DESCR:        30,
+	SENT_LENGTH:           3,
+	SENT_TIME_CODE:        1,
+	SENT_TIME_DESCR:       10,
+	CYC_AGE:               3,
+	CII_TYPE:              1,
+	CII_TYPE_ALPHA:        10,
+	COMMENT_TEXT:          200,
+	END_OF_REC:            1,
+}
+
+func (row *DOJRow) dispositionDateBounds() dateBounds {
+	return newDateBounds(row.DispositionDate, row.DispositionDatePrecision)
+}
+
+// The checks of a conviction's date also report whether their result is
+// certain, given how precisely DOJ knows the dates they compare.
+
+func (row *DOJRow) wasConvictionUnderAgeOf21(subject *Subject) (bool, bool) {
+	return row.dispositionDateBounds().before(subject.dobBounds().addYears(21))
+}
+
+func (row *DOJRow) convictionBefore(years int, comparisonTime time.Time) (bool, bool) {
+	after, certain := exactDate(comparisonTime.AddDate(-years, 0, 0)).before(row.dispositionDateBounds())
+	return !after, certain
+}
+
+// sentenceCompletedBy reports whether the custody and probation of the
+// conviction's sentence have ended by t. Life terms never end.
+func (row *DOJRow) sentenceCompletedBy(t time.Time) bool {
+	return !row.Sentence.IsLife() && !row.SentenceEndDate.After(t)
 }
 
-func (row *DOJRow) convictionBefore(years int, comparisonTime time.Time) bool {
-	return !row.DispositionDate.After(comparisonTime.AddDate(-years, 0, 0))
+func (row *DOJRow) convictionBeforeDate(cutoff time.Time) (bool, bool) {
+	return row.dispositionDateBounds().before(exactDate(cutoff))
 }
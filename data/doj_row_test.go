@@ -29,6 +29,28 @@ var _ = Describe("DojRow", func() {
 		Expect(row.IsFelony).To(BeTrue())
 	})
 
+	It("parses dates DOJ knows only to the month or year, with their precision", func() {
+		row := NewDOJRow(rawRow, 1)
+		Expect(row.DOBPrecision).To(Equal(DayPrecision))
+		Expect(row.DispositionDatePrecision).To(Equal(DayPrecision))
+
+		rawRow[PRI_DOB] = "19600000"
+		rawRow[STP_EVENT_DATE] = "19790500"
+		row = NewDOJRow(rawRow, 1)
+		Expect(row.DOB).To(Equal(time.Date(1960, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(row.DOBPrecision).To(Equal(YearPrecision))
+		Expect(row.DispositionDate).To(Equal(time.Date(1979, time.May, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(row.DispositionDatePrecision).To(Equal(MonthPrecision))
+
+		rawRow[PRI_DOB] = ""
+		rawRow[STP_EVENT_DATE] = "19791300"
+		row = NewDOJRow(rawRow, 1)
+		Expect(row.DOB).To(BeZero())
+		Expect(row.DOBPrecision).To(Equal(NoDate))
+		Expect(row.DispositionDate).To(BeZero())
+		Expect(row.DispositionDatePrecision).To(Equal(NoDate))
+	})
+
 	Context("The row is a registration event", func() {
 		BeforeEach(func() {
 			rawRow = []string{
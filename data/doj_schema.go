@@ -0,0 +1,178 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DOJSchema is the order of the columns of a DOJ file without a header row.
+// A DOJ file with a header row is read by the names in its header instead, so
+// columns DOJ adds, drops or reorders do not shift the values of the others.
+type DOJSchema struct {
+	Name    string
+	Columns []string
+}
+
+const DefaultDOJSchemaName = "v1"
+
+// DOJSchemas are the schema versions DOJ files without a header row can be
+// read with, by name. v1 is the research file layout of the columns in
+// doj_row.go, which fixed-width DOJ files also use.
+var DOJSchemas = map[string]DOJSchema{
+	DefaultDOJSchemaName: {Name: DefaultDOJSchemaName, Columns: DOJColumnNames[:]},
+}
+
+var DefaultDOJSchema = DOJSchemas[DefaultDOJSchemaName]
+
+func ParseDOJSchema(name string) (DOJSchema, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return DefaultDOJSchema, nil
+	}
+	schema, ok := DOJSchemas[name]
+	if !ok {
+		var names []string
+		for name := range DOJSchemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return DOJSchema{}, fmt.Errorf("unknown DOJ schema %q: Must be one of %s", name, strings.Join(names, ", "))
+	}
+	return schema, nil
+}
+
+// requiredDOJColumns are the columns eligibility is determined from. A DOJ
+// file without one of them cannot be processed, while other missing columns
+// are read as blank.
+var requiredDOJColumns = []int{
+	SUBJECT_ID,
+	PRI_NAME,
+	PRI_DOB,
+	STP_EVENT_DATE,
+	STP_TYPE_DESCR,
+	STP_ORI_CNTY_NAME,
+	CNT_ORDER,
+	OFFENSE_DESCR,
+	OFFENSE_TOC,
+	DISP_DESCR,
+	CONV_STAT_DESCR,
+	SENT_LENGTH,
+	SENT_TIME_CODE,
+	COMMENT_TEXT,
+}
+
+var dojColumnsByName = func() map[string]int {
+	columns := make(map[string]int)
+	for column, name := range DOJColumnNames {
+		columns[name] = column
+	}
+	return columns
+}()
+
+func normalizeColumnName(name string) string {
+	return strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+// dojColumnLayout maps the columns of a DOJ file, named by its header row or
+// its schema, to the columns defined in doj_row.go. Columns with other names
+// are extra columns, which are carried through to the full results after
+// END_OF_REC.
+type dojColumnLayout struct {
+	hasHeaders       bool
+	inDOJOrder       bool
+	columnCount      int
+	lastColumn       string
+	sourceColumns    [dojColumnCount]int
+	extraColumns     []int
+	extraColumnNames []string
+}
+
+func newDOJColumnLayout(columnNames []string, hasHeaders bool) (*dojColumnLayout, error) {
+	layout := dojColumnLayout{hasHeaders: hasHeaders}
+	for column := range layout.sourceColumns {
+		layout.sourceColumns[column] = -1
+	}
+
+	for sourceColumn, name := range columnNames {
+		normalizedName := normalizeColumnName(name)
+		if normalizedName == "" {
+			continue
+		}
+		layout.columnCount = sourceColumn + 1
+		layout.lastColumn = normalizedName
+
+		column, ok := dojColumnsByName[normalizedName]
+		if !ok {
+			layout.extraColumns = append(layout.extraColumns, sourceColumn)
+			layout.extraColumnNames = append(layout.extraColumnNames, strings.TrimSpace(name))
+			continue
+		}
+		if layout.sourceColumns[column] >= 0 {
+			return nil, fmt.Errorf("duplicate column %s", normalizedName)
+		}
+		layout.sourceColumns[column] = sourceColumn
+	}
+
+	var missingColumns []string
+	for _, column := range requiredDOJColumns {
+		if layout.sourceColumns[column] < 0 {
+			missingColumns = append(missingColumns, DOJColumnNames[column])
+		}
+	}
+	if len(missingColumns) > 0 {
+		return nil, fmt.Errorf("missing required columns: %s", strings.Join(missingColumns, ", "))
+	}
+
+	layout.inDOJOrder = len(layout.extraColumns) == 0
+	for column, sourceColumn := range layout.sourceColumns {
+		if sourceColumn != column {
+			layout.inDOJOrder = false
+		}
+	}
+	return &layout, nil
+}
+
+// validateColumnCount returns the reasons a row does not fit the layout.
+func (l *dojColumnLayout) validateColumnCount(rawRow []string) []string {
+	if len(rawRow) < l.columnCount {
+		return []string{"too few columns"}
+	}
+	for _, value := range rawRow[l.columnCount:] {
+		if strings.TrimSpace(value) != "" {
+			return []string{"unexpected values after " + l.lastColumn}
+		}
+	}
+	return nil
+}
+
+// mapRow orders the values of a row by the columns defined in doj_row.go,
+// followed by its extra columns and any values after the last column of the
+// layout. Columns the file does not have are blank. Rows of files whose
+// columns are already in that order are left as they were read.
+func (l *dojColumnLayout) mapRow(rawRow []string) []string {
+	if l.inDOJOrder {
+		return rawRow
+	}
+	row := make([]string, dojColumnCount+len(l.extraColumns))
+	for column, sourceColumn := range l.sourceColumns {
+		if sourceColumn >= 0 && sourceColumn < len(rawRow) {
+			row[column] = rawRow[sourceColumn]
+		}
+	}
+	for i, sourceColumn := range l.extraColumns {
+		if sourceColumn < len(rawRow) {
+			row[dojColumnCount+i] = rawRow[sourceColumn]
+		}
+	}
+	if len(rawRow) > l.columnCount {
+		row = append(row, rawRow[l.columnCount:]...)
+	}
+	return row
+}
+
+// rowLength is the length of a valid row: the columns defined in doj_row.go
+// and the extra columns.
+func (l *dojColumnLayout) rowLength() int {
+	return dojColumnCount + len(l.extraColumns)
+}
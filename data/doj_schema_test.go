@@ -0,0 +1,124 @@
+package data_test
+
+import (
+	"encoding/csv"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+	. "gogen_pilots/test_fixtures"
+
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+var _ = Describe("DOJ columns", func() {
+	var (
+		rows           [][]string
+		tempDir        string
+		comparisonTime time.Time
+		err            error
+	)
+
+	BeforeEach(func() {
+		pathToDOJ, _, err := ExtractFullCSVFixtures(path.Join("..", "test_fixtures", "los_angeles.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+		dojFile, err := os.Open(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		defer dojFile.Close()
+		rows, err = csv.NewReader(dojFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		tempDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		comparisonTime = time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+	})
+
+	// writeColumns writes the fixture with only the given columns, in their
+	// order. Columns that are not DOJ columns get the value of the row number.
+	writeColumns := func(fileName string, withHeaders bool, columns ...string) string {
+		filePath := path.Join(tempDir, fileName)
+		file, err := os.Create(filePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+		writer := csv.NewWriter(file)
+		if withHeaders {
+			Expect(writer.Write(columns)).To(Succeed())
+		}
+		for i, row := range rows[1:] {
+			var values []string
+			for _, column := range columns {
+				value := string(rune('a' + i%26))
+				for index, name := range data.DOJColumnNames {
+					if name == column {
+						value = row[index]
+					}
+				}
+				values = append(values, value)
+			}
+			Expect(writer.Write(values)).To(Succeed())
+		}
+		writer.Flush()
+		return filePath
+	}
+
+	readAllSubjects := func(reader *data.DOJReader) [][]string {
+		var allRows [][]string
+		for {
+			subject, err := reader.NextSubject()
+			if err == io.EOF {
+				return allRows
+			}
+			Expect(err).ToNot(HaveOccurred())
+			allRows = append(allRows, subject.Rows...)
+		}
+	}
+
+	It("reads columns by the names in the header row, carrying extra columns after END_OF_REC", func() {
+		columns := append([]string{"COUNTY_NOTE"}, data.DOJColumnNames[:]...)
+		columns[1+data.PRI_NAME], columns[1+data.SUBJECT_ID] = columns[1+data.SUBJECT_ID], columns[1+data.PRI_NAME]
+		columns = append(columns[:1+data.RECORD_ID], columns[1+data.RECORD_ID+1:]...)
+		filePath := writeColumns("reordered.csv", true, columns...)
+
+		reader, err := data.NewDOJReader(filePath, data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+		Expect(reader.ExtraColumns()).To(Equal([]string{"COUNTY_NOTE"}))
+
+		readRows := readAllSubjects(reader)
+		Expect(readRows).To(HaveLen(len(rows) - 1))
+		for i, row := range readRows {
+			expected := append([]string{""}, rows[i+1][data.RECORD_ID+1:]...)
+			Expect(row).To(Equal(append(expected, string(rune('a'+i%26)))))
+		}
+		Expect(reader.TakeRejectedRows()).To(BeEmpty())
+	})
+
+	It("fails when the header row is missing required columns", func() {
+		filePath := writeColumns("missing.csv", true, "SUBJECT_ID", "PRI_NAME", "STP_EVENT_DATE", "CNT_ORDER", "COMMENT_TEXT")
+
+		_, err = data.NewDOJReader(filePath, data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).To(MatchError(filePath + ": missing required columns: PRI_DOB, STP_TYPE_DESCR, STP_ORI_CNTY_NAME, OFFENSE_DESCR, OFFENSE_TOC, DISP_DESCR, CONV_STAT_DESCR, SENT_LENGTH, SENT_TIME_CODE"))
+
+		filePath = writeColumns("duplicate.csv", true, append(data.DOJColumnNames[:], "CNT_ORDER")...)
+		_, err = data.ResolveIdentities(filePath, data.AutoDetectFormat, data.DefaultDOJSchema)
+		Expect(err).To(MatchError(filePath + ": duplicate column CNT_ORDER"))
+	})
+
+	It("reads files without a header row by the columns of a schema version", func() {
+		filePath := writeColumns("no_headers.csv", false, data.DOJColumnNames[:]...)
+
+		schema, err := data.ParseDOJSchema("v1")
+		Expect(err).ToNot(HaveOccurred())
+		reader, err := data.NewDOJReader(filePath, data.AutoDetectFormat, schema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+		Expect(reader.ExtraColumns()).To(BeEmpty())
+		Expect(readAllSubjects(reader)).To(Equal(rows[1:]))
+
+		_, err = data.ParseDOJSchema("v0")
+		Expect(err).To(MatchError(`unknown DOJ schema "v0": Must be one of v1`))
+	})
+})
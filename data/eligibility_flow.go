@@ -1,6 +1,7 @@
 package data
 
 import (
+	"sort"
 	"time"
 )
 
@@ -9,12 +10,42 @@ type EligibilityFlow interface {
 	ChecksRelatedCharges() (result bool)
 }
 
+// CountyEligibilityFlows are the built-in eligibility flows of counties, by
+// county name. Convictions are evaluated by the flow of the county they are in.
+var CountyEligibilityFlows = map[string]EligibilityFlow{
+	"LOS ANGELES": losAngelesEligibilityFlow{},
+}
+
+// EligibilityFlows are the county flows and the hypothetical flows that
+// every run also applies, ex: DISMISS ALL PROP 64.
 var EligibilityFlows = map[string]EligibilityFlow{
-	"LOS ANGELES":                     losAngelesEligibilityFlow{},
 	"DISMISS ALL PROP 64":             dismissAllProp64EligibilityFlow{},
 	"DISMISS ALL PROP 64 AND RELATED": dismissAllProp64AndRelatedEligibilityFlow{},
 }
 
+func init() {
+	for county, flow := range CountyEligibilityFlows {
+		EligibilityFlows[county] = flow
+	}
+}
+
+func EligibilityFlowNames() []string {
+	return sortedFlowNames(EligibilityFlows)
+}
+
+func CountyNames() []string {
+	return sortedFlowNames(CountyEligibilityFlows)
+}
+
+func sortedFlowNames(flows map[string]EligibilityFlow) []string {
+	var names []string
+	for name := range flows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 type EligibilityOptions struct {
 	BaselineEligibility BaselineEligibility `json:"baselineEligibility"`
 	AdditionalRelief    AdditionalRelief    `json:"additionalRelief"`
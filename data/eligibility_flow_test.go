@@ -0,0 +1,23 @@
+package data_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+)
+
+var _ = Describe("EligibilityFlowNames", func() {
+	It("lists every registered eligibility flow in sorted order", func() {
+		Expect(data.EligibilityFlowNames()).To(Equal([]string{
+			"DISMISS ALL PROP 64",
+			"DISMISS ALL PROP 64 AND RELATED",
+			"LOS ANGELES",
+		}))
+	})
+})
+
+var _ = Describe("CountyNames", func() {
+	It("lists only the counties with a built-in eligibility flow", func() {
+		Expect(data.CountyNames()).To(Equal([]string{"LOS ANGELES"}))
+	})
+})
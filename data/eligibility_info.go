@@ -1,7 +1,9 @@
 package data
 
 import (
+	"fmt"
 	"gogen_pilots/matchers"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -26,6 +28,7 @@ type EligibilityInfo struct {
 	EligibilityReason              string
 	CaseNumber                     string
 	Deceased                       string
+	Trace                          []TraceStep
 }
 
 func NewEligibilityInfo(row *DOJRow, subject *Subject, comparisonTime time.Time, county string) *EligibilityInfo {
@@ -75,7 +78,7 @@ func NewEligibilityInfo(row *DOJRow, subject *Subject, comparisonTime time.Time,
 	info.NumberOfConvictionsOnRecord = len(subject.Convictions)
 	info.NumberOfProp64Convictions, info.NumberOf11357Convictions, info.NumberOf11358Convictions, info.NumberOf11359Convictions, info.NumberOf11360Convictions = subject.Prop64ConvictionsBySection()
 	info.DateOfConviction = row.DispositionDate
-	info.CaseNumber = strings.Join(subject.CaseNumbers[row.CountOrder[0:6]], "; ")
+	info.CaseNumber = strings.Join(subject.CaseNumbers[row.caseKey()], "; ")
 
 	return info
 }
@@ -94,29 +97,75 @@ func (info *EligibilityInfo) hasPC290() bool {
 	return info.PC290CodeSections != "-" || info.PC290Registration != "-"
 }
 
-func (info *EligibilityInfo) hasTwoPriors(row *DOJRow, subject *Subject) bool {
-	priorConvictionsOfSameCodeSectionPrefix := 0
-	codeSectionRune := []rune(row.CodeSection)
-	codeSectionPrefix := string(codeSectionRune[0:5])
+// hasSuperstrikeBefore reports whether the subject has a superstrike before
+// the conviction, and whether that is certain. Superstrikes found only by a
+// gang enhancement have no date of their own, so they precede every conviction.
+func (info *EligibilityInfo) hasSuperstrikeBefore(row *DOJRow, subject *Subject) (bool, bool) {
+	if !info.hasSuperstrikes() {
+		return false, true
+	}
+	if info.EarliestSuperstrike.IsZero() {
+		return true, true
+	}
+	return subject.hasConvictionBefore(row, func(conviction *DOJRow) bool {
+		return IsSuperstrike(conviction.CodeSection)
+	})
+}
+
+// hasPC290Before reports whether the subject has a PC 290 conviction before
+// the conviction, and whether that is certain. Registrations are not
+// convictions, so a subject who is only registered precedes every conviction.
+func (info *EligibilityInfo) hasPC290Before(row *DOJRow, subject *Subject) (bool, bool) {
+	if !info.hasPC290() {
+		return false, true
+	}
+	if info.EarliestPC290.IsZero() {
+		return true, true
+	}
+	return subject.hasConvictionBefore(row, func(conviction *DOJRow) bool {
+		return IsPC290(conviction.CodeSection) || conviction.IsPC290Registration
+	})
+}
+
+func (info *EligibilityInfo) hasTwoPriors(row *DOJRow, subject *Subject) (bool, bool) {
+	return info.hasPriorConvictionsOfSameCodeSection(row, subject, 2)
+}
+
+// priorConvictionsOfSameCodeSection counts the Prop 64 convictions of the
+// same section that were before the conviction, and the ones that may have
+// been given how precisely their dates are known.
+func (info *EligibilityInfo) priorConvictionsOfSameCodeSection(row *DOJRow, subject *Subject) (int, int) {
+	priorConvictionsOfSameCodeSection := 0
+	possiblePriorConvictionsOfSameCodeSection := 0
+	section := row.ParsedCodeSection().Section
 	for _, conviction := range subject.Convictions {
 		if matchers.IsProp64Charge(conviction.CodeSection) {
-			if conviction.DispositionDate.Before(row.DispositionDate) {
-				if strings.HasPrefix(conviction.CodeSection, codeSectionPrefix) {
-					priorConvictionsOfSameCodeSectionPrefix++
+			before, certain := conviction.dispositionDateBounds().before(row.dispositionDateBounds())
+			if before || !certain {
+				if conviction.ParsedCodeSection().Section == section {
+					possiblePriorConvictionsOfSameCodeSection++
+					if certain {
+						priorConvictionsOfSameCodeSection++
+					}
 				}
 			}
 		}
 	}
 
-	return priorConvictionsOfSameCodeSectionPrefix >= 2
+	return priorConvictionsOfSameCodeSection, possiblePriorConvictionsOfSameCodeSection
 }
 
-func (info *EligibilityInfo) youngerThanTwentyOne(row *DOJRow, subject *Subject) bool {
-	age := info.yearsSinceEvent(subject.DOB)
-	if age <= 21 {
-		return true
-	}
-	return false
+// hasPriorConvictionsOfSameCodeSection reports whether there are at least
+// atLeast prior convictions of the same section, and whether that is certain.
+func (info *EligibilityInfo) hasPriorConvictionsOfSameCodeSection(row *DOJRow, subject *Subject, atLeast int) (bool, bool) {
+	priorConvictions, possiblePriorConvictions := info.priorConvictionsOfSameCodeSection(row, subject)
+	return priorConvictions >= atLeast, priorConvictions >= atLeast || possiblePriorConvictions < atLeast
+}
+
+func (info *EligibilityInfo) youngerThanTwentyOne(row *DOJRow, subject *Subject) (bool, bool) {
+	return subject.dobBounds().check(func(dob time.Time) bool {
+		return info.yearsSinceEvent(dob) <= 21
+	})
 }
 
 func (info *EligibilityInfo) onlyProp64Convictions(row *DOJRow, subject *Subject) bool {
@@ -125,20 +174,45 @@ func (info *EligibilityInfo) onlyProp64Convictions(row *DOJRow, subject *Subject
 
 func (info *EligibilityInfo) allSentencesCompleted(row *DOJRow, subject *Subject) bool {
 	for _, conviction := range subject.Convictions {
-		if conviction.SentenceEndDate.After(info.comparisonTime) {
+		if !conviction.sentenceCompletedBy(info.comparisonTime) {
 			return false
 		}
 	}
 	return true
 }
 
-func (info *EligibilityInfo) noConvictionsInGivenTimePeriod(row *DOJRow, subject *Subject, timeSinceConviction int) bool {
+func (info *EligibilityInfo) latestSentenceEndDate(subject *Subject) time.Time {
+	var latest time.Time
 	for _, conviction := range subject.Convictions {
-		if conviction.DispositionDate.After(info.comparisonTime.AddDate(-timeSinceConviction, 0, 0)) {
-			return false
+		if conviction.SentenceEndDate.After(latest) {
+			latest = conviction.SentenceEndDate
 		}
 	}
-	return true
+	return latest
+}
+
+// traceLatestSentenceEndDate is the latest sentence end date for the trace,
+// or "life" when the subject has a life term.
+func (info *EligibilityInfo) traceLatestSentenceEndDate(subject *Subject) string {
+	for _, conviction := range subject.Convictions {
+		if conviction.Sentence.IsLife() {
+			return "life"
+		}
+	}
+	return traceDate(info.latestSentenceEndDate(subject))
+}
+
+func (info *EligibilityInfo) noConvictionsInGivenTimePeriod(row *DOJRow, subject *Subject, timeSinceConviction int) (bool, bool) {
+	cutoff := exactDate(info.comparisonTime.AddDate(-timeSinceConviction, 0, 0))
+	allCertain := true
+	for _, conviction := range subject.Convictions {
+		after, certain := cutoff.before(conviction.dispositionDateBounds())
+		if after && certain {
+			return false, true
+		}
+		allCertain = allCertain && certain
+	}
+	return true, allCertain
 }
 
 func (info *EligibilityInfo) SetEligibleForDismissal(reason string) {
@@ -170,3 +244,38 @@ func (info *EligibilityInfo) SetCityAttorneyReview(reason string) {
 	info.EligibilityDetermination = "To be reviewed by City Attorneys"
 	info.EligibilityReason = strings.TrimSpace(reason)
 }
+
+func (info *EligibilityInfo) SetAlreadyRelieved(reason string) {
+	info.EligibilityDetermination = "Already Relieved"
+	info.EligibilityReason = strings.TrimSpace(reason)
+}
+
+// reviewRejectedRows sends an eligible conviction to hand review when rows of
+// its subject failed validation, since the rows left out could have changed
+// its eligibility, ex: a disqualifying conviction.
+func (info *EligibilityInfo) reviewRejectedRows(rowNumbers []int) {
+	if len(rowNumbers) == 0 || !strings.HasPrefix(info.EligibilityDetermination, "Eligible") {
+		return
+	}
+	rows := make([]string, len(rowNumbers))
+	for i, rowNumber := range rowNumbers {
+		rows[i] = strconv.Itoa(rowNumber)
+	}
+	info.addTraceStep("RowsFailedValidation", true, map[string]interface{}{"rows": rowNumbers})
+	info.SetHandReview(fmt.Sprintf("Rows of this subject failed validation: %s", strings.Join(rows, ", ")))
+}
+
+// alreadyRelieved determines a conviction that a later court action already
+// dismissed, reduced or vacated as Already Relieved, so that no flow
+// recommends relief it already has.
+func (info *EligibilityInfo) alreadyRelieved(row *DOJRow) bool {
+	if row.Relief.Type == NoRelief {
+		return false
+	}
+	info.addTraceStep("AlreadyRelieved", true, map[string]interface{}{
+		"relief":     row.Relief.Type,
+		"reliefDate": traceImpreciseDate(row.Relief.Date, row.Relief.DatePrecision),
+	})
+	info.SetAlreadyRelieved(row.Relief.Reason)
+	return true
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TraceStep records one node of an eligibility flow that was visited for a
+// conviction, whether its check passed, and the values the check compared.
+type TraceStep struct {
+	Node   string                 `json:"node"`
+	Result bool                   `json:"result"`
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+func (info *EligibilityInfo) addTraceStep(node string, result bool, values map[string]interface{}) {
+	info.Trace = append(info.Trace, TraceStep{Node: node, Result: result, Values: values})
+}
+
+// TraceSummary renders the trace on one line for the results CSV, ex:
+// "OlderThanGivenAge: no (age=39, threshold=50) > YoungerThanTwentyOne: ..."
+func (info *EligibilityInfo) TraceSummary() string {
+	steps := make([]string, len(info.Trace))
+	for i, step := range info.Trace {
+		steps[i] = step.String()
+	}
+	return strings.Join(steps, " > ")
+}
+
+func (step TraceStep) String() string {
+	result := "no"
+	if step.Result {
+		result = "yes"
+	}
+	if len(step.Values) == 0 {
+		return fmt.Sprintf("%s: %s", step.Node, result)
+	}
+
+	names := make([]string, 0, len(step.Values))
+	for name := range step.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = fmt.Sprintf("%s=%v", name, step.Values[name])
+	}
+	return fmt.Sprintf("%s: %s (%s)", step.Node, result, strings.Join(values, ", "))
+}
+
+func traceDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return date.Format("2006-01-02")
+}
+
+// traceImpreciseDate renders a date known only to its month or year as
+// "2006-01" or "2006".
+func traceImpreciseDate(date time.Time, precision DatePrecision) string {
+	switch precision {
+	case MonthPrecision:
+		return date.Format("2006-01")
+	case YearPrecision:
+		return date.Format("2006")
+	case NoDate:
+		return "-"
+	}
+	return traceDate(date)
+}
+
+// wholeYearsBetween is the number of birthdays or anniversaries of from that
+// have passed by to.
+func wholeYearsBetween(from time.Time, to time.Time) int {
+	years := to.Year() - from.Year()
+	if to.Before(from.AddDate(years, 0, 0)) {
+		years--
+	}
+	return years
+}
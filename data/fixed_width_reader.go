@@ -0,0 +1,120 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type DOJFileFormat string
+
+const (
+	AutoDetectFormat DOJFileFormat = "auto"
+	CSVFormat        DOJFileFormat = "csv"
+	FixedWidthFormat DOJFileFormat = "dat"
+)
+
+var fixedWidthRecordLength = sumOfWidths(fixedWidthFieldWidths[:])
+
+func ParseDOJFileFormat(format string) (DOJFileFormat, error) {
+	switch DOJFileFormat(strings.ToLower(strings.TrimSpace(format))) {
+	case "", AutoDetectFormat:
+		return AutoDetectFormat, nil
+	case CSVFormat:
+		return CSVFormat, nil
+	case FixedWidthFormat:
+		return FixedWidthFormat, nil
+	}
+	return "", fmt.Errorf("unknown DOJ file format %q: Must be one of %s, %s or %s", format, AutoDetectFormat, CSVFormat, FixedWidthFormat)
+}
+
+// dojRowReader reads one DOJ row at a time, split into the columns defined in
+// doj_row.go. *csv.Reader satisfies it.
+type dojRowReader interface {
+	Read() ([]string, error)
+}
+
+// fixedWidthReader reads the native DOJ research file layout, where each line
+// is one record and each column is padded with spaces to the width given in
+// fixedWidthFieldWidths.
+type fixedWidthReader struct {
+	reader *bufio.Reader
+}
+
+func newFixedWidthReader(reader *bufio.Reader) *fixedWidthReader {
+	return &fixedWidthReader{reader: reader}
+}
+
+func (r *fixedWidthReader) Read() ([]string, error) {
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return r.parseRecord(line)
+	}
+}
+
+// parseRecord splits a record into columns. Records whose trailing blank
+// columns were trimmed are accepted; records longer than the layout are not.
+func (r *fixedWidthReader) parseRecord(line string) ([]string, error) {
+	if len(line) > fixedWidthRecordLength {
+		return nil, &malformedRecordError{record: line, reason: "record longer than the fixed-width layout"}
+	}
+
+	row := make([]string, len(fixedWidthFieldWidths))
+	start := 0
+	for column, width := range fixedWidthFieldWidths {
+		end := start + width
+		if start < len(line) {
+			if end > len(line) {
+				end = len(line)
+			}
+			row[column] = strings.TrimRight(line[start:end], " ")
+		}
+		start += width
+	}
+	return row, nil
+}
+
+// malformedRecordError is returned for a record that cannot be split into
+// columns. Reading can continue with the next record.
+type malformedRecordError struct {
+	record string
+	reason string
+}
+
+func (e *malformedRecordError) Error() string {
+	return e.reason
+}
+
+// detectDOJFileFormat treats a file as fixed-width when its first line is
+// exactly one record long. CSV rows do not pad their columns, so they are
+// much shorter.
+func detectDOJFileFormat(reader *bufio.Reader) DOJFileFormat {
+	firstLineBytes, _ := reader.Peek(fixedWidthRecordLength + 2)
+	firstLine := string(firstLineBytes)
+	if newline := strings.IndexByte(firstLine, '\n'); newline >= 0 {
+		firstLine = firstLine[:newline]
+	}
+	firstLine = strings.TrimRight(firstLine, "\r")
+
+	if len(firstLine) == fixedWidthRecordLength {
+		return FixedWidthFormat
+	}
+	return CSVFormat
+}
+
+func sumOfWidths(widths []int) int {
+	total := 0
+	for _, width := range widths {
+		total += width
+	}
+	return total
+}
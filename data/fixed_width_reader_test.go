@@ -0,0 +1,175 @@
+package data_test
+
+import (
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+)
+
+var _ = Describe("Reading fixed-width DOJ files", func() {
+	var (
+		pathToFixedWidthDOJ string
+		pathToCSVDOJ        string
+		comparisonTime      time.Time
+	)
+
+	BeforeEach(func() {
+		pathToFixedWidthDOJ = path.Join("..", "test_fixtures", "fixed_width.dat")
+		pathToCSVDOJ = path.Join("..", "test_fixtures", "no_headers.csv")
+		comparisonTime = time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+	})
+
+	readSubjects := func(pathToDOJ string, format data.DOJFileFormat) []*data.DOJInformation {
+		dojReader, err := data.NewDOJReader(pathToDOJ, format, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+		defer dojReader.Close()
+
+		var subjects []*data.DOJInformation
+		for {
+			dojInformation, err := dojReader.NextSubject()
+			if err == io.EOF {
+				return subjects
+			}
+			Expect(err).ToNot(HaveOccurred())
+			subjects = append(subjects, dojInformation)
+		}
+	}
+
+	It("reads the same rows as the CSV version of the file, without column padding", func() {
+		csvFile, err := os.Open(pathToCSVDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		defer csvFile.Close()
+		csvRows, err := csv.NewReader(csvFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		var fixedWidthRows [][]string
+		for _, subject := range readSubjects(pathToFixedWidthDOJ, data.FixedWidthFormat) {
+			fixedWidthRows = append(fixedWidthRows, subject.Rows...)
+		}
+
+		Expect(fixedWidthRows).To(HaveLen(len(csvRows)))
+		for i, csvRow := range csvRows {
+			for column, value := range csvRow {
+				Expect(fixedWidthRows[i][column]).To(Equal(strings.TrimRight(value, " ")))
+			}
+		}
+	})
+
+	It("splits records at the field positions of the DOJ record layout", func() {
+		layoutFile, err := os.Open(path.Join("..", "test_fixtures", "dat_record_layout.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		defer layoutFile.Close()
+		layout, err := csv.NewReader(layoutFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layout[0]).To(Equal([]string{"FIELD", "START", "END", "LENGTH"}))
+		fields := layout[1:]
+		Expect(fields).To(HaveLen(len(data.DOJColumnNames)))
+
+		validValues := map[string]string{
+			"SUBJECT_ID":     "0008675309",
+			"PRI_DOB":        "19600314",
+			"STP_EVENT_DATE": "19790525",
+			"DISP_DATE":      "19790601",
+			"CNT_ORDER":      "001001001000",
+		}
+
+		// Place a value at each field's start and end position, so that a
+		// field that is one column off reads a neighbouring field's letters.
+		record := []byte(strings.Repeat(" ", 1612))
+		expectedValues := make([]string, len(fields))
+		for column, field := range fields {
+			Expect(field[0]).To(Equal(data.DOJColumnNames[column]))
+			start, err := strconv.Atoi(field[1])
+			Expect(err).ToNot(HaveOccurred())
+			end, err := strconv.Atoi(field[2])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(field[3]).To(Equal(strconv.Itoa(end - start + 1)))
+
+			value, ok := validValues[field[0]]
+			if !ok {
+				value = strings.Repeat(string(rune('A'+column%26)), end-start+1)
+			}
+			copy(record[start-1:end], value)
+			expectedValues[column] = value
+		}
+
+		datFile, err := ioutil.TempFile("", "layout_fixed_width")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(datFile.Name())
+		datFile.Write(append(record, "\r\n"...))
+		datFile.Close()
+
+		subjects := readSubjects(datFile.Name(), data.AutoDetectFormat)
+		Expect(subjects).To(HaveLen(1))
+		Expect(subjects[0].Rows).To(Equal([][]string{expectedValues}))
+	})
+
+	It("keeps leading zeros and dates as they appear in the file", func() {
+		subjects := readSubjects(pathToFixedWidthDOJ, data.FixedWidthFormat)
+		Expect(subjects[0].Rows[0][data.CII_NUMBER]).To(Equal("1008675309"))
+		Expect(subjects[0].Rows[0][data.PRI_DOB]).To(Equal("19600314"))
+		Expect(subjects[0].Rows[0][data.CNT_ORDER]).To(Equal("101001001000"))
+	})
+
+	It("detects fixed-width files when the format is not given", func() {
+		Expect(readSubjects(pathToFixedWidthDOJ, data.AutoDetectFormat)).To(HaveLen(11))
+		Expect(readSubjects(pathToCSVDOJ, data.AutoDetectFormat)).To(HaveLen(11))
+	})
+
+	It("can read fixed-width files into memory all at once", func() {
+		dojInformation, err := data.NewDOJInformation(pathToFixedWidthDOJ, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dojInformation.TotalRows()).To(Equal(38))
+		Expect(dojInformation.TotalIndividuals()).To(Equal(11))
+	})
+
+	It("counts the rows in a fixed-width file", func() {
+		totalRows, err := data.CountDOJRows(pathToFixedWidthDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(totalRows).To(Equal(38))
+	})
+
+	It("rejects records longer than the fixed-width layout", func() {
+		fixedWidthDOJ, err := ioutil.ReadFile(pathToFixedWidthDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		firstRecord := strings.SplitN(string(fixedWidthDOJ), "\r\n", 2)[0]
+
+		badFile, err := ioutil.TempFile("", "bad_fixed_width")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(badFile.Name())
+		badFile.WriteString(firstRecord + "\r\n" + firstRecord + "EXTRA\r\n")
+		badFile.Close()
+
+		dojReader, err := data.NewDOJReader(badFile.Name(), data.FixedWidthFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+		Expect(err).ToNot(HaveOccurred())
+		defer dojReader.Close()
+
+		dojInformation, err := dojReader.NextSubject()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dojInformation.TotalRows()).To(Equal(1))
+
+		Expect(dojReader.TakeRejectedRows()).To(Equal([]data.RejectedRow{{
+			RowNumber: 2,
+			Row:       []string{firstRecord + "EXTRA"},
+			Reasons:   []string{"record longer than the fixed-width layout"},
+		}}))
+	})
+
+	It("validates the file format option", func() {
+		format, err := data.ParseDOJFileFormat("DAT")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(format).To(Equal(data.FixedWidthFormat))
+
+		_, err = data.ParseDOJFileFormat("xlsx")
+		Expect(err).To(MatchError(`unknown DOJ file format "xlsx": Must be one of auto, csv or dat`))
+	})
+})
@@ -1,17 +1,103 @@
 package data
 
 import (
+	"strings"
 	"time"
 )
 
-func parseDate(format string, date string) time.Time {
-	//TODO: handle dates that have format YYYY0000
-	t, err := time.Parse(format, date)
-	if err != nil {
-		t = time.Time{}
+// DatePrecision is how much of a DOJ date is known. DOJ writes dates whose
+// day or month is not known as YYYYMM00 or YYYY0000. The zero value is a date
+// known to the day, so rows built without a precision have exact dates.
+type DatePrecision int
+
+const (
+	DayPrecision DatePrecision = iota
+	MonthPrecision
+	YearPrecision
+	NoDate
+)
+
+var datePrecisionNames = [...]string{
+	DayPrecision:   "day",
+	MonthPrecision: "month",
+	YearPrecision:  "year",
+	NoDate:         "none",
+}
+
+func (precision DatePrecision) String() string {
+	return datePrecisionNames[precision]
+}
+
+// parseDate parses a DOJ date. A date known only to its month or year is the
+// first day of that month or year. Blank and invalid dates are the zero time.
+func parseDate(date string) (time.Time, DatePrecision) {
+	if t, err := time.Parse(dateFormat, date); err == nil {
+		return t, DayPrecision
+	}
+	if len(date) != len(dateFormat) {
+		return time.Time{}, NoDate
+	}
+	if strings.HasSuffix(date, "0000") {
+		if t, err := time.Parse("2006", date[0:4]); err == nil {
+			return t, YearPrecision
+		}
+		return time.Time{}, NoDate
+	}
+	if strings.HasSuffix(date, "00") {
+		if t, err := time.Parse("200601", date[0:6]); err == nil {
+			return t, MonthPrecision
+		}
+	}
+	return time.Time{}, NoDate
+}
+
+// lastDate stands in for the end of a date that is not known at all.
+var lastDate = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// dateBounds are the first and last days a DOJ date may be. They are the same
+// day for a date known to the day, and span all days for one not known at all.
+type dateBounds struct {
+	earliest time.Time
+	latest   time.Time
+}
+
+func newDateBounds(date time.Time, precision DatePrecision) dateBounds {
+	switch precision {
+	case DayPrecision:
+		return dateBounds{earliest: date, latest: date}
+	case MonthPrecision:
+		return dateBounds{earliest: date, latest: date.AddDate(0, 1, -1)}
+	case YearPrecision:
+		return dateBounds{earliest: date, latest: date.AddDate(1, 0, -1)}
 	}
-	//if time.Now().Before(t) {
-	//	t = t.AddDate(-100, 0, 0)
-	//}
-	return t
+	return dateBounds{latest: lastDate}
+}
+
+func exactDate(date time.Time) dateBounds {
+	return dateBounds{earliest: date, latest: date}
+}
+
+func (bounds dateBounds) addYears(years int) dateBounds {
+	return dateBounds{earliest: bounds.earliest.AddDate(years, 0, 0), latest: bounds.latest.AddDate(years, 0, 0)}
+}
+
+// before reports whether bounds is before other, and whether that holds for
+// every day each may be. When it does not, the result is that of their
+// earliest days.
+func (bounds dateBounds) before(other dateBounds) (bool, bool) {
+	if bounds.latest.Before(other.earliest) {
+		return true, true
+	}
+	if !bounds.earliest.Before(other.latest) {
+		return false, true
+	}
+	return bounds.earliest.Before(other.earliest), false
+}
+
+// check reports the result of check for the earliest day of bounds, and
+// whether the latest day has the same result. check must not change its
+// result more than once from one day to the next.
+func (bounds dateBounds) check(check func(date time.Time) bool) (bool, bool) {
+	result := check(bounds.earliest)
+	return result, result == check(bounds.latest)
 }
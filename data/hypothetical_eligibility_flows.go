@@ -13,7 +13,9 @@ func (ef dismissAllProp64EligibilityFlow) ProcessSubject(subject *Subject, compa
 	for _, conviction := range subject.Convictions {
 		if ef.checkRelevancy(conviction.CodeSection, conviction.County, county) {
 			info := NewEligibilityInfo(conviction, subject, comparisonTime, county)
-			ef.BeginEligibilityFlow(info, conviction, subject)
+			if !info.alreadyRelieved(conviction) {
+				ef.BeginEligibilityFlow(info, conviction, subject)
+			}
 			infos[conviction.Index] = info
 		}
 	}
@@ -42,7 +44,9 @@ func (ef dismissAllProp64AndRelatedEligibilityFlow) ProcessSubject(subject *Subj
 	for _, conviction := range subject.Convictions {
 		if ef.checkRelevancy(conviction.CodeSection, conviction.County, county) {
 			info := NewEligibilityInfo(conviction, subject, comparisonTime, county)
-			ef.BeginEligibilityFlow(info, conviction, subject)
+			if !info.alreadyRelieved(conviction) {
+				ef.BeginEligibilityFlow(info, conviction, subject)
+			}
 			infos[conviction.Index] = info
 		}
 	}
@@ -0,0 +1,229 @@
+package data
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IdentityResolution maps each SUBJECT_ID in a DOJ file to the identity it
+// belongs to. SUBJECT_IDs that share a CII_NUMBER or FBI_NUMBER are merged
+// into one identity, named after the SUBJECT_ID that appears first in the
+// file. SUBJECT_IDs that only share a name and date of birth are not merged,
+// but are flagged as probable duplicates for review.
+type IdentityResolution struct {
+	Merges             []IdentityMerge
+	ProbableDuplicates []ProbableDuplicate
+	resolvedSubjectIDs map[string]string
+	subjectIDsByMerge  map[string][]string
+}
+
+// IdentityMerge records SUBJECT_IDs merged into one identity and the
+// identifiers they shared, ex: "CII_NUMBER 12345678".
+type IdentityMerge struct {
+	ResolvedSubjectID string
+	SubjectIDs        []string
+	MatchedOn         []string
+}
+
+// ProbableDuplicate records identities with the same name and date of birth
+// but no shared CII_NUMBER or FBI_NUMBER.
+type ProbableDuplicate struct {
+	Name       string
+	DOB        string
+	SubjectIDs []string
+}
+
+type subjectIdentifiers struct {
+	subjectID   string
+	identifiers []string
+	name        string
+	dob         string
+}
+
+// ResolveIdentities reads a DOJ file to find the SUBJECT_IDs that belong to
+// the same person. Rows that fail validation are ignored, as they are when
+// the file is processed.
+func ResolveIdentities(dojFileName string, format DOJFileFormat, schema DOJSchema) (*IdentityResolution, error) {
+	dojFile, err := OpenDOJFile(dojFileName)
+	if err != nil {
+		return nil, err
+	}
+	defer dojFile.Close()
+
+	rowReader, layout, err := newDOJRowReader(dojFile, format, schema)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", dojFileName, err)
+	}
+	validRows := newValidatingRowReader(rowReader, layout)
+
+	var subjects []*subjectIdentifiers
+	subjectsByID := make(map[string]*subjectIdentifiers)
+	for {
+		row, err := validRows.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		subject := subjectsByID[row[SUBJECT_ID]]
+		if subject == nil {
+			subject = &subjectIdentifiers{
+				subjectID: row[SUBJECT_ID],
+				name:      row[PRI_NAME],
+				dob:       row[PRI_DOB],
+			}
+			subjectsByID[subject.subjectID] = subject
+			subjects = append(subjects, subject)
+		}
+		for _, identifier := range rowIdentifiers(row) {
+			subject.identifiers = setAppend(subject.identifiers, identifier)
+		}
+	}
+
+	return resolveSubjectIdentities(subjects), nil
+}
+
+func rowIdentifiers(row []string) []string {
+	var identifiers []string
+	if cii := strings.TrimSpace(row[CII_NUMBER]); cii != "" {
+		identifiers = append(identifiers, "CII_NUMBER "+cii)
+	}
+	if fbi := strings.TrimSpace(row[FBI_NUMBER]); fbi != "" {
+		identifiers = append(identifiers, "FBI_NUMBER "+fbi)
+	}
+	return identifiers
+}
+
+func resolveSubjectIdentities(subjects []*subjectIdentifiers) *IdentityResolution {
+	resolution := &IdentityResolution{
+		resolvedSubjectIDs: make(map[string]string),
+		subjectIDsByMerge:  make(map[string][]string),
+	}
+
+	// subjects are in file order and union keeps the lower index as the root,
+	// so each identity is named after its first SUBJECT_ID.
+	parents := make([]int, len(subjects))
+	for index := range parents {
+		parents[index] = index
+	}
+	var find func(index int) int
+	find = func(index int) int {
+		if parents[index] != index {
+			parents[index] = find(parents[index])
+		}
+		return parents[index]
+	}
+	union := func(a int, b int) {
+		rootA, rootB := find(a), find(b)
+		if rootA < rootB {
+			parents[rootB] = rootA
+		} else if rootB < rootA {
+			parents[rootA] = rootB
+		}
+	}
+
+	subjectsByIdentifier := make(map[string][]int)
+	for index, subject := range subjects {
+		for _, identifier := range subject.identifiers {
+			if others := subjectsByIdentifier[identifier]; len(others) > 0 {
+				union(others[0], index)
+			}
+			subjectsByIdentifier[identifier] = append(subjectsByIdentifier[identifier], index)
+		}
+	}
+
+	membersByRoot := make(map[int][]int)
+	var roots []int
+	for index, subject := range subjects {
+		root := find(index)
+		if membersByRoot[root] == nil {
+			roots = append(roots, root)
+		}
+		membersByRoot[root] = append(membersByRoot[root], index)
+		resolution.resolvedSubjectIDs[subject.subjectID] = subjects[root].subjectID
+	}
+
+	for _, root := range roots {
+		members := membersByRoot[root]
+		if len(members) < 2 {
+			continue
+		}
+		merge := IdentityMerge{ResolvedSubjectID: subjects[root].subjectID}
+		for _, member := range members {
+			merge.SubjectIDs = append(merge.SubjectIDs, subjects[member].subjectID)
+			for _, identifier := range subjects[member].identifiers {
+				if len(subjectsByIdentifier[identifier]) > 1 {
+					merge.MatchedOn = setAppend(merge.MatchedOn, identifier)
+				}
+			}
+		}
+		sort.Strings(merge.MatchedOn)
+		resolution.Merges = append(resolution.Merges, merge)
+		resolution.subjectIDsByMerge[merge.ResolvedSubjectID] = merge.SubjectIDs
+	}
+
+	resolution.ProbableDuplicates = findProbableDuplicates(subjects, roots, resolution)
+	return resolution
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Z0-9]+`)
+
+func normalizeName(name string) string {
+	return strings.TrimSpace(nonAlphanumeric.ReplaceAllString(strings.ToUpper(name), " "))
+}
+
+func findProbableDuplicates(subjects []*subjectIdentifiers, roots []int, resolution *IdentityResolution) []ProbableDuplicate {
+	var duplicates []ProbableDuplicate
+	duplicateIndexes := make(map[string]int)
+	for _, root := range roots {
+		subject := subjects[root]
+		name := normalizeName(subject.name)
+		if name == "" || strings.TrimSpace(subject.dob) == "" {
+			continue
+		}
+		key := name + "|" + subject.dob
+		index, seen := duplicateIndexes[key]
+		if !seen {
+			duplicateIndexes[key] = len(duplicates)
+			duplicates = append(duplicates, ProbableDuplicate{Name: subject.name, DOB: subject.dob, SubjectIDs: []string{subject.subjectID}})
+			continue
+		}
+		duplicates[index].SubjectIDs = append(duplicates[index].SubjectIDs, subject.subjectID)
+	}
+
+	var probableDuplicates []ProbableDuplicate
+	for _, duplicate := range duplicates {
+		if len(duplicate.SubjectIDs) > 1 {
+			probableDuplicates = append(probableDuplicates, duplicate)
+		}
+	}
+	return probableDuplicates
+}
+
+// ResolvedSubjectID returns the SUBJECT_ID of the identity subjectID belongs
+// to, which is subjectID itself when it was not merged.
+func (r *IdentityResolution) ResolvedSubjectID(subjectID string) string {
+	if resolved, ok := r.resolvedSubjectIDs[subjectID]; ok {
+		return resolved
+	}
+	return subjectID
+}
+
+// MergedSubjectIDs returns the SUBJECT_IDs merged into the identity
+// subjectID belongs to, or nil when it was not merged.
+func (r *IdentityResolution) MergedSubjectIDs(subjectID string) []string {
+	return r.subjectIDsByMerge[r.ResolvedSubjectID(subjectID)]
+}
+
+// MergedSubjectCount is the number of SUBJECT_IDs merged into another.
+func (r *IdentityResolution) MergedSubjectCount() int {
+	count := 0
+	for _, merge := range r.Merges {
+		count += len(merge.SubjectIDs) - 1
+	}
+	return count
+}
@@ -0,0 +1,147 @@
+package data_test
+
+import (
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+	. "gogen_pilots/test_fixtures"
+)
+
+var _ = Describe("IdentityResolution", func() {
+	var (
+		pathToDOJ      string
+		comparisonTime time.Time
+		err            error
+	)
+
+	BeforeEach(func() {
+		pathToDOJ, _, err = ExtractFullCSVFixtures(path.Join("..", "test_fixtures", "los_angeles.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+		comparisonTime = time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("merges SUBJECT_IDs that share a CII_NUMBER into the first SUBJECT_ID in the file", func() {
+		identities, err := data.ResolveIdentities(pathToDOJ, data.AutoDetectFormat, data.DefaultDOJSchema)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(identities.Merges).To(Equal([]data.IdentityMerge{
+			{ResolvedSubjectID: "18675309", SubjectIDs: []string{"18675309", "23675654", "14575654"}, MatchedOn: []string{"CII_NUMBER 1008675309"}},
+			{ResolvedSubjectID: "90675321", SubjectIDs: []string{"90675321", "95875321"}, MatchedOn: []string{"CII_NUMBER A123456781"}},
+		}))
+		Expect(identities.ProbableDuplicates).To(BeEmpty())
+		Expect(identities.MergedSubjectCount()).To(Equal(3))
+		Expect(identities.ResolvedSubjectID("14575654")).To(Equal("18675309"))
+		Expect(identities.ResolvedSubjectID("17954908")).To(Equal("17954908"))
+		Expect(identities.MergedSubjectIDs("17954908")).To(BeNil())
+	})
+
+	It("merges SUBJECT_IDs that share an FBI_NUMBER and flags SUBJECT_IDs that share a name and date of birth", func() {
+		inputFile, err := os.Open(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		reader := csv.NewReader(inputFile)
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		inputFile.Close()
+		Expect(err).ToNot(HaveOccurred())
+
+		withIdentity := func(row []string, subjectID string, cii string, fbi string, name string) []string {
+			newRow := append([]string{}, row...)
+			newRow[data.SUBJECT_ID] = subjectID
+			newRow[data.CII_NUMBER] = cii
+			newRow[data.FBI_NUMBER] = fbi
+			newRow[data.PRI_NAME] = name
+			return newRow
+		}
+		cadabbyRow := rows[len(rows)-5]
+		Expect(cadabbyRow[data.SUBJECT_ID]).To(Equal("34499400"))
+		rows = append(rows,
+			withIdentity(cadabbyRow, "34499401", "A967852999", "", "CADABBY, ABBIGAIL"),
+			withIdentity(cadabbyRow, "55500001", "A555000001", "123456AB1", "SNUFFLEUPAGUS,ALOYSIUS"),
+			withIdentity(cadabbyRow, "55500002", "A555000002", "123456AB1", "SNUFFY"),
+		)
+
+		outputDir, err := ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		pathToResolvedDOJ := path.Join(outputDir, "input.csv")
+		outputFile, err := os.Create(pathToResolvedDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(csv.NewWriter(outputFile).WriteAll(rows)).To(Succeed())
+		outputFile.Close()
+
+		identities, err := data.ResolveIdentities(pathToResolvedDOJ, data.AutoDetectFormat, data.DefaultDOJSchema)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(identities.Merges).To(HaveLen(3))
+		Expect(identities.Merges[2]).To(Equal(data.IdentityMerge{
+			ResolvedSubjectID: "55500001",
+			SubjectIDs:        []string{"55500001", "55500002"},
+			MatchedOn:         []string{"FBI_NUMBER 123456AB1"},
+		}))
+		Expect(identities.ProbableDuplicates).To(Equal([]data.ProbableDuplicate{
+			{Name: "CADABBY,ABBIGAIL", DOB: "20060814", SubjectIDs: []string{"34499400", "34499401"}},
+		}))
+		Expect(identities.ResolvedSubjectID("34499401")).To(Equal("34499401"))
+	})
+
+	Describe("Reading subjects with resolved identities", func() {
+		var dojReader *data.DOJReader
+
+		BeforeEach(func() {
+			dojReader, err = data.NewDOJReader(pathToDOJ, data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows["LOS ANGELES"])
+			Expect(err).ToNot(HaveOccurred())
+			identities, err := data.ResolveIdentities(pathToDOJ, data.AutoDetectFormat, data.DefaultDOJSchema)
+			Expect(err).ToNot(HaveOccurred())
+			dojReader.ResolveIdentities(identities)
+		})
+
+		AfterEach(func() {
+			dojReader.Close()
+		})
+
+		readAll := func() map[string]*data.DOJInformation {
+			subjects := make(map[string]*data.DOJInformation)
+			for {
+				dojInformation, err := dojReader.NextSubject()
+				if err == io.EOF {
+					return subjects
+				}
+				Expect(err).ToNot(HaveOccurred())
+				Expect(dojInformation.Subjects).To(HaveLen(1))
+				for subjectID := range dojInformation.Subjects {
+					subjects[subjectID] = dojInformation
+				}
+			}
+		}
+
+		It("returns the rows of merged SUBJECT_IDs together as one subject", func() {
+			subjects := readAll()
+
+			Expect(subjects).To(HaveLen(7))
+			Expect(subjects).ToNot(HaveKey("14575654"))
+			Expect(subjects["18675309"].TotalRows()).To(Equal(14))
+			Expect(subjects["90675321"].TotalRows()).To(Equal(6))
+			Expect(dojReader.RowsRead()).To(Equal(35))
+		})
+
+		It("considers the convictions of every merged SUBJECT_ID when determining eligibility", func() {
+			merged := readAll()["18675309"]
+			eligibilities := merged.DetermineEligibility("LOS ANGELES", data.EligibilityFlows["LOS ANGELES"], 50, 10)
+
+			var reasons []string
+			for index, row := range merged.Rows {
+				if row[data.SUBJECT_ID] == "14575654" && eligibilities[index] != nil {
+					reasons = append(reasons, eligibilities[index].EligibilityReason)
+				}
+			}
+			Expect(reasons).ToNot(BeEmpty())
+			Expect(reasons).ToNot(ContainElement(ContainSubstring("Only has 11357-60 charges")))
+		})
+	})
+})
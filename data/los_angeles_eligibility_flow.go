@@ -3,7 +3,6 @@ package data
 import (
 	"fmt"
 	"gogen_pilots/matchers"
-	"strings"
 	"time"
 )
 
@@ -13,9 +12,11 @@ type losAngelesEligibilityFlow struct {
 func (ef losAngelesEligibilityFlow) ProcessSubject(subject *Subject, comparisonTime time.Time, flowCounty string, age int, yearsConvictionFree int) map[int]*EligibilityInfo {
 	infos := make(map[int]*EligibilityInfo)
 	for _, conviction := range subject.Convictions {
-		if ef.checkRelevancy(conviction.CodeSection, conviction.County) {
-			info := NewEligibilityInfo(conviction, subject, comparisonTime, "LOS ANGELES")
-			ef.BeginEligibilityFlow(info, conviction, subject, age, yearsConvictionFree, comparisonTime)
+		if ef.checkRelevancy(conviction.CodeSection, conviction.County, flowCounty) {
+			info := NewEligibilityInfo(conviction, subject, comparisonTime, flowCounty)
+			if !info.alreadyRelieved(conviction) {
+				ef.BeginEligibilityFlow(info, conviction, subject, age, yearsConvictionFree, comparisonTime)
+			}
 			infos[conviction.Index] = info
 		}
 	}
@@ -26,17 +27,24 @@ func (ef losAngelesEligibilityFlow) ChecksRelatedCharges() bool {
 	return false
 }
 
-func (ef losAngelesEligibilityFlow) checkRelevancy(codeSection string, county string) bool {
-	return county == "LOS ANGELES" && matchers.IsProp64Charge(codeSection)
+func (ef losAngelesEligibilityFlow) checkRelevancy(codeSection string, county string, flowCounty string) bool {
+	return county == flowCounty && matchers.IsProp64Charge(codeSection)
 }
 
 func (ef losAngelesEligibilityFlow) BeginEligibilityFlow(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int, comparisonTime time.Time) {
-	if matchers.IsProp64Charge(row.CodeSection) {
+	isProp64Charge := matchers.IsProp64Charge(row.CodeSection)
+	info.addTraceStep("BeginEligibilityFlow", isProp64Charge, map[string]interface{}{
+		"codeSection": row.CodeSection,
+	})
+	if isProp64Charge {
 		ef.ConvictionIsMisdemeanorOrInfraction(info, row, subject, age, yearsConvictionFree, comparisonTime)
 	}
 }
 
 func (ef losAngelesEligibilityFlow) ConvictionIsMisdemeanorOrInfraction(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int, comparisonTime time.Time) {
+	info.addTraceStep("ConvictionIsMisdemeanorOrInfraction", !row.IsFelony, map[string]interface{}{
+		"isFelony": row.IsFelony,
+	})
 	if row.IsFelony {
 		ef.ConvictionBeforeNovNine2016(info, row, subject, age, yearsConvictionFree, comparisonTime)
 	} else {
@@ -45,18 +53,34 @@ func (ef losAngelesEligibilityFlow) ConvictionIsMisdemeanorOrInfraction(info *El
 }
 
 func (ef losAngelesEligibilityFlow) ConvictionBeforeNovNine2016(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int, comparisonTime time.Time) {
-	if info.DateOfConviction.Before(time.Date(2016, 11, 9, 0, 0, 0, 0, time.UTC)) {
+	cutoff := time.Date(2016, 11, 9, 0, 0, 0, 0, time.UTC)
+	beforeCutoff, certain := row.convictionBeforeDate(cutoff)
+	info.addTraceStep("ConvictionBeforeNovNine2016", beforeCutoff, map[string]interface{}{
+		"dateOfConviction": traceImpreciseDate(info.DateOfConviction, row.DispositionDatePrecision),
+		"cutoff":           traceDate(cutoff),
+	})
+	if !certain {
+		info.SetHandReview("Imprecise date of conviction")
+	} else if beforeCutoff {
 		ef.ConvictionIs11357(info, row, subject, age, yearsConvictionFree, comparisonTime)
 	} else {
 		info.SetNotEligible("Occurred after 11/09/2016")
 	}
 }
 
-
 func (ef losAngelesEligibilityFlow) ConvictionIs11357(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int, comparisonTime time.Time) {
 	ok, codeSection := matchers.ExtractProp64Section(row.CodeSection)
-	if ok && codeSection == "11357" {
-		if strings.HasPrefix(row.CodeSection, "11357(A)") || strings.HasPrefix(row.CodeSection, "11357(B)") {
+	is11357 := ok && codeSection == "11357"
+	info.addTraceStep("ConvictionIs11357", is11357, map[string]interface{}{
+		"codeSection": row.CodeSection,
+	})
+	if is11357 {
+		subdivision := row.ParsedCodeSection().Subdivision(0)
+		is11357AOrB := subdivision == "A" || subdivision == "B"
+		info.addTraceStep("ConvictionIs11357AOrB", is11357AOrB, map[string]interface{}{
+			"codeSection": row.CodeSection,
+		})
+		if is11357AOrB {
 			info.SetEligibleForDismissal("11357(a) or 11357(b)")
 		} else {
 			info.SetHandReview("Other 11357")
@@ -67,7 +91,15 @@ func (ef losAngelesEligibilityFlow) ConvictionIs11357(info *EligibilityInfo, row
 }
 
 func (ef losAngelesEligibilityFlow) HasPrecedingSuperstrike(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int, comparisonTime time.Time) {
-	if info.hasSuperstrikes() && info.EarliestSuperstrike.Before(row.DispositionDate) {
+	hasPrecedingSuperstrike, certain := info.hasSuperstrikeBefore(row, subject)
+	info.addTraceStep("HasPrecedingSuperstrike", hasPrecedingSuperstrike, map[string]interface{}{
+		"superstrikes":        info.Superstrikes,
+		"earliestSuperstrike": traceDate(info.EarliestSuperstrike),
+		"dateOfConviction":    traceImpreciseDate(row.DispositionDate, row.DispositionDatePrecision),
+	})
+	if !certain {
+		info.SetHandReview("Imprecise conviction dates")
+	} else if hasPrecedingSuperstrike {
 		info.SetNotEligible("PC 667(e)(2)(c)(iv)")
 	} else {
 		ef.HasPrecedingPC290(info, row, subject, age, yearsConvictionFree, comparisonTime)
@@ -75,7 +107,16 @@ func (ef losAngelesEligibilityFlow) HasPrecedingSuperstrike(info *EligibilityInf
 }
 
 func (ef losAngelesEligibilityFlow) HasPrecedingPC290(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int, comparisonTime time.Time) {
-	if info.hasPC290() && info.EarliestPC290.Before(row.DispositionDate) {
+	hasPrecedingPC290, certain := info.hasPC290Before(row, subject)
+	info.addTraceStep("HasPrecedingPC290", hasPrecedingPC290, map[string]interface{}{
+		"pc290CodeSections": info.PC290CodeSections,
+		"pc290Registration": info.PC290Registration,
+		"earliestPC290":     traceDate(info.EarliestPC290),
+		"dateOfConviction":  traceImpreciseDate(row.DispositionDate, row.DispositionDatePrecision),
+	})
+	if !certain {
+		info.SetHandReview("Imprecise conviction dates")
+	} else if hasPrecedingPC290 {
 		info.SetNotEligible("PC 290")
 	} else {
 		ef.TwoPriors(info, row, subject, age, yearsConvictionFree, comparisonTime)
@@ -83,7 +124,15 @@ func (ef losAngelesEligibilityFlow) HasPrecedingPC290(info *EligibilityInfo, row
 }
 
 func (ef losAngelesEligibilityFlow) TwoPriors(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int, comparisonTime time.Time) {
-	if info.hasTwoPriors(row, subject) {
+	hasTwoPriors, certain := info.hasTwoPriors(row, subject)
+	priorConvictions, _ := info.priorConvictionsOfSameCodeSection(row, subject)
+	info.addTraceStep("TwoPriors", hasTwoPriors, map[string]interface{}{
+		"priorConvictions": priorConvictions,
+		"threshold":        2,
+	})
+	if !certain {
+		info.SetHandReview("Imprecise conviction dates")
+	} else if hasTwoPriors {
 		info.SetNotEligible("Two priors")
 	} else {
 		ef.OlderThanGivenAge(info, row, subject, age, yearsConvictionFree, comparisonTime)
@@ -91,7 +140,15 @@ func (ef losAngelesEligibilityFlow) TwoPriors(info *EligibilityInfo, row *DOJRow
 }
 
 func (ef losAngelesEligibilityFlow) OlderThanGivenAge(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int, comparisonTime time.Time) {
-	if subject.olderThan(age, comparisonTime) {
+	olderThanGivenAge, certain := subject.olderThan(age, comparisonTime)
+	info.addTraceStep("OlderThanGivenAge", olderThanGivenAge, map[string]interface{}{
+		"dateOfBirth": traceImpreciseDate(subject.DOB, subject.DOBPrecision),
+		"age":         wholeYearsBetween(subject.DOB, comparisonTime),
+		"threshold":   age,
+	})
+	if !certain {
+		info.SetHandReview("Imprecise date of birth")
+	} else if olderThanGivenAge {
 		info.SetEligibleForDismissal(fmt.Sprintf("%v years or older", age))
 	} else {
 		ef.YoungerThanTwentyOne(info, row, subject, yearsConvictionFree)
@@ -99,7 +156,15 @@ func (ef losAngelesEligibilityFlow) OlderThanGivenAge(info *EligibilityInfo, row
 }
 
 func (ef losAngelesEligibilityFlow) YoungerThanTwentyOne(info *EligibilityInfo, row *DOJRow, subject *Subject, yearsConvictionFree int) {
-	if info.youngerThanTwentyOne(row, subject) {
+	youngerThanTwentyOne, certain := info.youngerThanTwentyOne(row, subject)
+	info.addTraceStep("YoungerThanTwentyOne", youngerThanTwentyOne, map[string]interface{}{
+		"dateOfBirth": traceImpreciseDate(subject.DOB, subject.DOBPrecision),
+		"age":         wholeYearsBetween(subject.DOB, info.comparisonTime),
+		"threshold":   21,
+	})
+	if !certain {
+		info.SetHandReview("Imprecise date of birth")
+	} else if youngerThanTwentyOne {
 		info.SetEligibleForDismissal("21 years or younger")
 	} else {
 		ef.Prop64OnlyWithCompletedSentences(info, row, subject, yearsConvictionFree)
@@ -107,15 +172,30 @@ func (ef losAngelesEligibilityFlow) YoungerThanTwentyOne(info *EligibilityInfo,
 }
 
 func (ef losAngelesEligibilityFlow) Prop64OnlyWithCompletedSentences(info *EligibilityInfo, row *DOJRow, subject *Subject, yearsConvictionFree int) {
-	if info.onlyProp64Convictions(row, subject) && info.allSentencesCompleted(row, subject) {
+	onlyProp64Convictions := info.onlyProp64Convictions(row, subject)
+	allSentencesCompleted := info.allSentencesCompleted(row, subject)
+	info.addTraceStep("Prop64OnlyWithCompletedSentences", onlyProp64Convictions && allSentencesCompleted, map[string]interface{}{
+		"convictions":           len(subject.Convictions),
+		"prop64Convictions":     info.NumberOfProp64Convictions,
+		"latestSentenceEndDate": info.traceLatestSentenceEndDate(subject),
+	})
+	if onlyProp64Convictions && allSentencesCompleted {
 		info.SetEligibleForDismissal("Only has 11357-60 charges and completed sentence")
 	} else {
-		ef.NoConvictionsInGivenTimePeriod(info, row, subject,yearsConvictionFree)
+		ef.NoConvictionsInGivenTimePeriod(info, row, subject, yearsConvictionFree)
 	}
 }
 
 func (ef losAngelesEligibilityFlow) NoConvictionsInGivenTimePeriod(info *EligibilityInfo, row *DOJRow, subject *Subject, yearsConvictionFree int) {
-	if info.noConvictionsInGivenTimePeriod(row, subject, yearsConvictionFree) {
+	noConvictionsInGivenTimePeriod, certain := info.noConvictionsInGivenTimePeriod(row, subject, yearsConvictionFree)
+	info.addTraceStep("NoConvictionsInGivenTimePeriod", noConvictionsInGivenTimePeriod, map[string]interface{}{
+		"mostRecentConviction": traceDate(subject.MostRecentConvictionDate()),
+		"cutoff":               traceDate(info.comparisonTime.AddDate(-yearsConvictionFree, 0, 0)),
+		"yearsConvictionFree":  yearsConvictionFree,
+	})
+	if !certain {
+		info.SetHandReview("Imprecise conviction dates")
+	} else if noConvictionsInGivenTimePeriod {
 		info.SetEligibleForDismissal(fmt.Sprintf("No convictions in past %v years", yearsConvictionFree))
 	} else {
 		ef.ServingSentence(info, row, subject)
@@ -123,7 +203,12 @@ func (ef losAngelesEligibilityFlow) NoConvictionsInGivenTimePeriod(info *Eligibi
 }
 
 func (ef losAngelesEligibilityFlow) ServingSentence(info *EligibilityInfo, row *DOJRow, subject *Subject) {
-	if !info.allSentencesCompleted(row, subject) {
+	servingSentence := !info.allSentencesCompleted(row, subject)
+	info.addTraceStep("ServingSentence", servingSentence, map[string]interface{}{
+		"latestSentenceEndDate": info.traceLatestSentenceEndDate(subject),
+		"comparisonDate":        traceDate(info.comparisonTime),
+	})
+	if servingSentence {
 		info.SetHandReview("Currently serving sentence")
 	} else {
 		ef.IsDeceased(info, row, subject)
@@ -131,6 +216,7 @@ func (ef losAngelesEligibilityFlow) ServingSentence(info *EligibilityInfo, row *
 }
 
 func (ef losAngelesEligibilityFlow) IsDeceased(info *EligibilityInfo, row *DOJRow, subject *Subject) {
+	info.addTraceStep("IsDeceased", subject.IsDeceased, nil)
 	if subject.IsDeceased {
 		info.SetEligibleForDismissal("Deceased")
 	} else {
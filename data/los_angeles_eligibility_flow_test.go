@@ -18,7 +18,6 @@ var _ = Describe("losAngelesEligibilityFlow", func() {
 	Describe("Processing a subject", func() {
 
 		birthDate := time.Date(1994, time.April, 10, 0, 0, 0, 0, time.UTC)
-		days := time.Duration(24) * (time.Hour)
 		comparisonTime := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
 		age := 50
 
@@ -101,6 +100,17 @@ var _ = Describe("losAngelesEligibilityFlow", func() {
 				Expect(infos[2].EligibilityDetermination).To(Equal("Not eligible"))
 				Expect(infos[2].EligibilityReason).To(Equal("Occurred after 11/09/2016"))
 			})
+
+			It("records the nodes visited for each conviction", func() {
+				infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY, age, yearsConvictionFree)
+				Expect(infos[0].TraceSummary()).To(Equal("BeginEligibilityFlow: yes (codeSection=11357 HS) > ConvictionIsMisdemeanorOrInfraction: yes (isFelony=false)"))
+				Expect(infos[2].TraceSummary()).To(Equal("BeginEligibilityFlow: yes (codeSection=11358 PC) > ConvictionIsMisdemeanorOrInfraction: no (isFelony=true) > ConvictionBeforeNovNine2016: no (cutoff=2016-11-09, dateOfConviction=2017-05-04)"))
+			})
+
+			It("only evaluates convictions in the county it is run for", func() {
+				infos := flow.ProcessSubject(&subject, comparisonTime, "SAN JOAQUIN", age, yearsConvictionFree)
+				Expect(len(infos)).To(Equal(0))
+			})
 		})
 
 		Context("Superstrikes", func() {
@@ -134,16 +144,16 @@ var _ = Describe("losAngelesEligibilityFlow", func() {
 					Index:           1,
 				}
 				conviction3 = DOJRow{
-					DOB:                  birthDate,
-					WasConvicted:         true,
-					CodeSection:          "11360 HS",
-					DispositionDate:      time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC),
-					OFN:                  "1236 334455-00",
-					County:               COUNTY,
-					CountOrder:           "104001006000",
-					Index:                2,
-					SentencePartDuration: time.Duration(30 * days),
-					IsFelony:             true,
+					DOB:             birthDate,
+					WasConvicted:    true,
+					CodeSection:     "11360 HS",
+					DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC),
+					OFN:             "1236 334455-00",
+					County:          COUNTY,
+					CountOrder:      "104001006000",
+					Index:           2,
+					Sentence:        Sentence{Segments: []SentenceSegment{{Type: CustodySentence, Length: 30, TimeCode: "D"}}},
+					IsFelony:        true,
 				}
 				rows := []DOJRow{conviction1, superstrike, conviction3}
 				subject = Subject{}
@@ -201,16 +211,16 @@ var _ = Describe("losAngelesEligibilityFlow", func() {
 					Index:           1,
 				}
 				conviction3 = DOJRow{
-					DOB:                  birthDate,
-					WasConvicted:         true,
-					CodeSection:          "11360 HS",
-					DispositionDate:      time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC),
-					OFN:                  "1236 334455-00",
-					County:               COUNTY,
-					CountOrder:           "104001006000",
-					Index:                2,
-					SentencePartDuration: time.Duration(30 * days),
-					IsFelony:             true,
+					DOB:             birthDate,
+					WasConvicted:    true,
+					CodeSection:     "11360 HS",
+					DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC),
+					OFN:             "1236 334455-00",
+					County:          COUNTY,
+					CountOrder:      "104001006000",
+					Index:           2,
+					Sentence:        Sentence{Segments: []SentenceSegment{{Type: CustodySentence, Length: 30, TimeCode: "D"}}},
+					IsFelony:        true,
 				}
 				rows := []DOJRow{conviction1, pc290, conviction3}
 				subject = Subject{}
@@ -269,16 +279,16 @@ var _ = Describe("losAngelesEligibilityFlow", func() {
 					Index:               1,
 				}
 				conviction3 = DOJRow{
-					DOB:                  birthDate,
-					WasConvicted:         true,
-					CodeSection:          "11360 HS",
-					DispositionDate:      time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC),
-					OFN:                  "1236 334455-00",
-					County:               COUNTY,
-					CountOrder:           "104001006000",
-					Index:                2,
-					SentencePartDuration: time.Duration(30 * days),
-					IsFelony:             true,
+					DOB:             birthDate,
+					WasConvicted:    true,
+					CodeSection:     "11360 HS",
+					DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC),
+					OFN:             "1236 334455-00",
+					County:          COUNTY,
+					CountOrder:      "104001006000",
+					Index:           2,
+					Sentence:        Sentence{Segments: []SentenceSegment{{Type: CustodySentence, Length: 30, TimeCode: "D"}}},
+					IsFelony:        true,
 				}
 				rows := []DOJRow{conviction1, pc290, conviction3}
 				subject = Subject{}
@@ -737,6 +747,25 @@ var _ = Describe("losAngelesEligibilityFlow", func() {
 				Expect(infos[0].EligibilityDetermination).To(Equal("Hand Review"))
 				Expect(infos[0].EligibilityReason).To(Equal("No applicable eligibility criteria"))
 			})
+
+			It("records every node visited with the values it compared", func() {
+				infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY, age, yearsConvictionFree)
+				Expect(infos[0].Trace).To(Equal([]TraceStep{
+					{Node: "BeginEligibilityFlow", Result: true, Values: map[string]interface{}{"codeSection": "11359(b) HS"}},
+					{Node: "ConvictionIsMisdemeanorOrInfraction", Result: false, Values: map[string]interface{}{"isFelony": true}},
+					{Node: "ConvictionBeforeNovNine2016", Result: true, Values: map[string]interface{}{"dateOfConviction": "2004-12-05", "cutoff": "2016-11-09"}},
+					{Node: "ConvictionIs11357", Result: false, Values: map[string]interface{}{"codeSection": "11359(b) HS"}},
+					{Node: "HasPrecedingSuperstrike", Result: false, Values: map[string]interface{}{"superstrikes": "-", "earliestSuperstrike": "-", "dateOfConviction": "2004-12-05"}},
+					{Node: "HasPrecedingPC290", Result: false, Values: map[string]interface{}{"pc290CodeSections": "-", "pc290Registration": "-", "earliestPC290": "-", "dateOfConviction": "2004-12-05"}},
+					{Node: "TwoPriors", Result: false, Values: map[string]interface{}{"priorConvictions": 0, "threshold": 2}},
+					{Node: "OlderThanGivenAge", Result: false, Values: map[string]interface{}{"dateOfBirth": "1994-04-10", "age": 26, "threshold": 50}},
+					{Node: "YoungerThanTwentyOne", Result: false, Values: map[string]interface{}{"dateOfBirth": "1994-04-10", "age": 26, "threshold": 21}},
+					{Node: "Prop64OnlyWithCompletedSentences", Result: false, Values: map[string]interface{}{"convictions": 3, "prop64Convictions": 2, "latestSentenceEndDate": "2012-03-04"}},
+					{Node: "NoConvictionsInGivenTimePeriod", Result: false, Values: map[string]interface{}{"mostRecentConviction": "2016-12-05", "cutoff": "2010-07-01", "yearsConvictionFree": 10}},
+					{Node: "ServingSentence", Result: false, Values: map[string]interface{}{"latestSentenceEndDate": "2012-03-04", "comparisonDate": "2020-07-01"}},
+					{Node: "IsDeceased", Result: false},
+				}))
+			})
 		})
 
 	})
@@ -0,0 +1,97 @@
+package data
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ReliefType is what a court action did to a conviction after it was entered.
+type ReliefType int
+
+const (
+	NoRelief ReliefType = iota
+	DismissedRelief
+	ReducedRelief
+	VacatedRelief
+	ResentencedRelief
+)
+
+var reliefTypeNames = [...]string{
+	NoRelief:          "none",
+	DismissedRelief:   "dismissed",
+	ReducedRelief:     "reduced",
+	VacatedRelief:     "vacated",
+	ResentencedRelief: "resentenced",
+}
+
+func (reliefType ReliefType) String() string {
+	return reliefTypeNames[reliefType]
+}
+
+// Relief is a court action on a count that already dismissed, reduced, vacated
+// or resentenced its conviction, ex: a PC 1203.4 dismissal or a PC 17(b)
+// reduction.
+type Relief struct {
+	Type          ReliefType
+	Reason        string
+	Date          time.Time
+	DatePrecision DatePrecision
+}
+
+var pc17bPattern = regexp.MustCompile(`(^|[^0-9])17\s*(\(B\)|B\b)`)
+
+// notReliefPattern matches court actions that mention relief without granting
+// it, ex: a denied PC 1203.4 motion, a filed HS 11361.8 petition or a vacated
+// warrant.
+var notReliefPattern = regexp.MustCompile(`\b(DENIED|FILED|WARRANT)\b`)
+
+// newRelief reads the relief a row gives its count from its DISP_DESCR and
+// COMMENT_TEXT. Only relief that is spelled out and granted counts: a bare
+// DISMISSED on a count that was never convicted is not relief, nor is a denied
+// motion, a filed petition or a recalled or vacated warrant.
+func newRelief(rawRow []string, date time.Time, precision DatePrecision) Relief {
+	description := strings.ToUpper(rawRow[DISP_DESCR] + " " + rawRow[COMMENT_TEXT])
+	if notReliefPattern.MatchString(description) {
+		return Relief{}
+	}
+	reduced := strings.Contains(description, "REDUC")
+	relief := Relief{Date: date, DatePrecision: precision}
+	switch {
+	case strings.Contains(description, "11361.8"):
+		switch {
+		case reduced:
+			relief.Type, relief.Reason = ReducedRelief, "Reduced under HS 11361.8"
+		case strings.Contains(description, "GRANTED") || strings.Contains(description, "DISMISS"):
+			relief.Type, relief.Reason = DismissedRelief, "Dismissed under HS 11361.8"
+		default:
+			return Relief{}
+		}
+	case strings.Contains(description, "VACATE"):
+		relief.Type, relief.Reason = VacatedRelief, "Vacated"
+	case strings.Contains(description, "1203.4") || strings.Contains(description, "SET ASIDE"):
+		relief.Type, relief.Reason = DismissedRelief, "Dismissed under PC 1203.4"
+	case pc17bPattern.MatchString(description):
+		relief.Type, relief.Reason = ReducedRelief, "Reduced under PC 17(b)"
+	case strings.Contains(description, "RECALL") && strings.Contains(description, "RESENTENC"):
+		// A resentence only reduces the count when the row says so.
+		if reduced {
+			relief.Type, relief.Reason = ReducedRelief, "Reduced on recall and resentence"
+		} else {
+			relief.Type, relief.Reason = ResentencedRelief, "Recalled and resentenced"
+		}
+	default:
+		return Relief{}
+	}
+	return relief
+}
+
+// removesFelony is whether the conviction is no longer a felony.
+func (relief Relief) removesFelony() bool {
+	return relief.Type == DismissedRelief || relief.Type == ReducedRelief || relief.Type == VacatedRelief
+}
+
+// removesConviction is whether the conviction is no longer on the record.
+func (relief Relief) removesConviction() bool {
+	return relief.Type == DismissedRelief || relief.Type == VacatedRelief
+}
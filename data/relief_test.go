@@ -0,0 +1,106 @@
+package data_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "gogen_pilots/data"
+)
+
+var _ = Describe("Relief", func() {
+	comparisonTime := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+
+	// countRow is a row of a Los Angeles felony 11359 count 101001001000.
+	countRow := func(index int, date string, disposition string, comment string) DOJRow {
+		rawRow := make([]string, len(DOJColumnNames))
+		rawRow[SUBJECT_ID] = "1"
+		rawRow[PRI_DOB] = "19700101"
+		rawRow[STP_TYPE_DESCR] = "COURT ACTION"
+		rawRow[STP_EVENT_DATE] = date
+		rawRow[STP_ORI_CNTY_NAME] = "LOS ANGELES"
+		rawRow[DISP_DESCR] = disposition
+		rawRow[COMMENT_TEXT] = comment
+		rawRow[CONV_STAT_DESCR] = "FELONY"
+		rawRow[OFFENSE_DESCR] = "11359 HS-POSSESS MARIJUANA FOR SALE"
+		rawRow[CNT_ORDER] = "101001001000"
+		return NewDOJRow(rawRow, index)
+	}
+
+	relievedSubject := func(disposition string, comment string) *Subject {
+		subject := Subject{}
+		subject.PushRow(countRow(0, "20100504", "CONVICTED", ""))
+		subject.PushRow(countRow(1, "20180300", disposition, comment))
+		return &subject
+	}
+
+	It("reads relief spelled out by a later court action on a convicted count", func() {
+		reliefs := map[[2]string]Relief{
+			{"DISMISSED", "CONV SET ASIDE & DISM PER 1203.4 PC"}: {Type: DismissedRelief, Reason: "Dismissed under PC 1203.4"},
+			{"CONVICTED", "REDUCED TO MISD PER PC17(B)"}:         {Type: ReducedRelief, Reason: "Reduced under PC 17(b)"},
+			{"CONVICTED", "RECALLED AND RESENTENCED"}:            {Type: ResentencedRelief, Reason: "Recalled and resentenced"},
+			{"CONVICTED", "RECALLED, RESENTENCED AND REDUCED"}:   {Type: ReducedRelief, Reason: "Reduced on recall and resentence"},
+			{"CONV VACATED", ""}:                                 {Type: VacatedRelief, Reason: "Vacated"},
+			{"DISMISSED", "PETITION GRANTED HS 11361.8"}:         {Type: DismissedRelief, Reason: "Dismissed under HS 11361.8"},
+			{"CONVICTED", "REDUCED TO 11357(B) PER 11361.8 HS"}:  {Type: ReducedRelief, Reason: "Reduced under HS 11361.8"},
+			{"DISMISSED/CHARGE DROPPED", ""}:                     {},
+			{"CONVICTED", "11357(B) HS"}:                         {},
+			{"CONVICTED", "WARRANT RECALLED"}:                    {},
+			{"CONVICTED", "RECALLED ABSTRACT"}:                   {},
+			{"DISMISSED", "PETITION 11361.8 DENIED"}:             {},
+			{"CONVICTED", "PETITION FILED HS 11361.8"}:           {},
+			{"CONVICTED", "PC 1203.4 MOTION DENIED"}:             {},
+			{"CONVICTED", "17(B) DENIED"}:                        {},
+			{"CONVICTED", "MOTION TO VACATE DENIED"}:             {},
+			{"CONVICTED", "WARRANT VACATED"}:                     {},
+			{"CONVICTED", "RECALL AND RESENTENCE DENIED"}:        {},
+		}
+		for descriptions, relief := range reliefs {
+			if relief.Type != NoRelief {
+				relief.Date = time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC)
+				relief.DatePrecision = MonthPrecision
+			}
+			subject := relievedSubject(descriptions[0], descriptions[1])
+			Expect(subject.Convictions).To(HaveLen(1))
+			Expect(subject.Convictions[0].Relief).To(Equal(relief), "%v", descriptions)
+		}
+	})
+
+	It("does not read relief from the row that convicts the count", func() {
+		subject := Subject{}
+		subject.PushRow(countRow(0, "20100504", "CONVICTED", "REDUCED TO MISD PER PC17(B)"))
+		Expect(subject.Convictions[0].Relief).To(Equal(Relief{}))
+
+		infos := EligibilityFlows["LOS ANGELES"].ProcessSubject(&subject, comparisonTime, "LOS ANGELES", 50, 10)
+		Expect(infos[0].EligibilityDetermination).ToNot(Equal("Already Relieved"))
+	})
+
+	It("determines relieved convictions as Already Relieved instead of running the flow", func() {
+		subject := relievedSubject("DISMISSED", "CONV SET ASIDE & DISM PER 1203.4 PC")
+
+		for _, flowName := range []string{"LOS ANGELES", "DISMISS ALL PROP 64"} {
+			infos := EligibilityFlows[flowName].ProcessSubject(subject, comparisonTime, "LOS ANGELES", 50, 10)
+			Expect(infos[0].EligibilityDetermination).To(Equal("Already Relieved"), flowName)
+			Expect(infos[0].EligibilityReason).To(Equal("Dismissed under PC 1203.4"), flowName)
+			Expect(infos[0].TraceSummary()).To(Equal("AlreadyRelieved: yes (relief=dismissed, reliefDate=2018-03)"), flowName)
+		}
+	})
+
+	It("does not count relieved convictions as convictions individuals currently have", func() {
+		dojInformation := DOJInformation{Subjects: map[string]*Subject{
+			"1": relievedSubject("CONVICTED", "REDUCED TO MISD PER PC17(B)"),
+		}}
+		Expect(dojInformation.CountIndividualsWithFelony()).To(Equal(0))
+		Expect(dojInformation.CountIndividualsWithConviction()).To(Equal(1))
+
+		dojInformation.Subjects["1"] = relievedSubject("CONVICTED", "RECALLED AND RESENTENCED")
+		Expect(dojInformation.CountIndividualsWithFelony()).To(Equal(1))
+
+		dojInformation.Subjects["1"] = relievedSubject("DISMISSED", "CONV SET ASIDE & DISM PER 1203.4 PC")
+		Expect(dojInformation.CountIndividualsWithConviction()).To(Equal(0))
+		eligibilities := dojInformation.DetermineEligibility("LOS ANGELES", EligibilityFlows["LOS ANGELES"], 50, 10)
+		Expect(dojInformation.CountIndividualsWithSomeRelief(eligibilities)).To(Equal(0))
+		Expect(dojInformation.CountIndividualsNoLongerHaveConviction(eligibilities)).To(Equal(0))
+	})
+})
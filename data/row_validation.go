@@ -0,0 +1,161 @@
+package data
+
+import (
+	"encoding/csv"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// RejectedRow is a row of a DOJ file that failed validation. Rejected rows are
+// left out of eligibility processing and reported back to the user. Since the
+// history of their subject is incomplete without them, the subject's eligible
+// convictions are sent to hand review.
+type RejectedRow struct {
+	RowNumber int
+	Row       []string
+	Reasons   []string
+}
+
+const dojColumnCount = END_OF_REC + 1
+
+var countOrderPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+var requiredColumns = []struct {
+	column int
+	name   string
+}{
+	{SUBJECT_ID, "SUBJECT_ID"},
+	{PRI_DOB, "PRI_DOB"},
+	{STP_EVENT_DATE, "STP_EVENT_DATE"},
+	{CNT_ORDER, "CNT_ORDER"},
+}
+
+var dateColumns = []struct {
+	column int
+	name   string
+}{
+	{PRI_DOB, "PRI_DOB"},
+	{STP_EVENT_DATE, "STP_EVENT_DATE"},
+}
+
+// validateDOJRow returns the reasons a row cannot be processed, or nothing if
+// the row is valid. Reasons do not include the row's values, so they can be
+// counted across a file.
+func validateDOJRow(rawRow []string) []string {
+	if len(rawRow) < dojColumnCount {
+		return []string{"too few columns"}
+	}
+
+	var reasons []string
+	for _, value := range rawRow[dojColumnCount:] {
+		if strings.TrimSpace(value) != "" {
+			reasons = append(reasons, "unexpected values after END_OF_REC")
+			break
+		}
+	}
+	for _, required := range requiredColumns {
+		if strings.TrimSpace(rawRow[required.column]) == "" {
+			reasons = append(reasons, "missing "+required.name)
+		}
+	}
+	for _, date := range dateColumns {
+		value := rawRow[date.column]
+		if strings.TrimSpace(value) != "" && !isValidDOJDate(value) {
+			reasons = append(reasons, "invalid "+date.name+" date")
+		}
+	}
+	countOrder := rawRow[CNT_ORDER]
+	if strings.TrimSpace(countOrder) != "" && !countOrderPattern.MatchString(countOrder) {
+		reasons = append(reasons, "invalid CNT_ORDER")
+	}
+	return reasons
+}
+
+// isValidDOJDate accepts YYYYMMDD dates, as well as the YYYYMM00 and YYYY0000
+// dates DOJ uses when only the month or year is known.
+func isValidDOJDate(value string) bool {
+	_, precision := parseDate(value)
+	return precision != NoDate
+}
+
+// validatingRowReader reads rows from a DOJ file and sets aside the ones that
+// fail validation, so a malformed row does not stop the rest of the file from
+// being processed.
+type validatingRowReader struct {
+	rowReader    dojRowReader
+	layout       *dojColumnLayout
+	rowNumber    int
+	rowsRead     int
+	rejectedRows []RejectedRow
+	// rejectedRowNumbers are the row numbers of rejected rows by their
+	// SUBJECT_ID, for rows where it could be read.
+	rejectedRowNumbers map[string][]int
+}
+
+func newValidatingRowReader(rowReader dojRowReader, layout *dojColumnLayout) *validatingRowReader {
+	v := validatingRowReader{rowReader: rowReader, layout: layout, rejectedRowNumbers: make(map[string][]int)}
+	if layout.hasHeaders {
+		v.rowNumber = 1
+	}
+	return &v
+}
+
+// Read returns the next valid row, in the order of the DOJ columns followed by
+// the extra columns.
+func (v *validatingRowReader) Read() ([]string, error) {
+	for {
+		row, err := v.rowReader.Read()
+		if err == io.EOF {
+			return nil, err
+		}
+		v.rowNumber++
+		v.rowsRead++
+
+		var reasons []string
+		var subjectID string
+		switch err := err.(type) {
+		case nil:
+			reasons = v.layout.validateColumnCount(row)
+			hasAllColumns := len(row) >= v.layout.columnCount
+			row = v.layout.mapRow(row)
+			if hasAllColumns {
+				reasons = append(reasons, validateDOJRow(row[:dojColumnCount])...)
+			}
+			if len(row) > SUBJECT_ID {
+				subjectID = strings.TrimSpace(row[SUBJECT_ID])
+			}
+		case *csv.ParseError:
+			reasons = []string{"malformed CSV"}
+		case *malformedRecordError:
+			row, reasons = []string{err.record}, []string{err.reason}
+		default:
+			return nil, err
+		}
+
+		if len(reasons) > 0 {
+			v.rejectedRows = append(v.rejectedRows, RejectedRow{RowNumber: v.rowNumber, Row: row, Reasons: reasons})
+			if subjectID != "" {
+				v.rejectedRowNumbers[subjectID] = append(v.rejectedRowNumbers[subjectID], v.rowNumber)
+			}
+			continue
+		}
+		rowLength := v.layout.rowLength()
+		return row[:rowLength:rowLength], nil
+	}
+}
+
+// takeRejectedRowNumbers returns the numbers of the rejected rows of a
+// SUBJECT_ID that have been read so far.
+func (v *validatingRowReader) takeRejectedRowNumbers(subjectID string) []int {
+	rowNumbers := v.rejectedRowNumbers[subjectID]
+	delete(v.rejectedRowNumbers, subjectID)
+	return rowNumbers
+}
+
+// takeRejectedRows returns the rows rejected since it was last called.
+func (v *validatingRowReader) takeRejectedRows() []RejectedRow {
+	rejectedRows := v.rejectedRows
+	v.rejectedRows = nil
+	return rejectedRows
+}
@@ -0,0 +1,92 @@
+package data
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("validateDOJRow", func() {
+	var row []string
+
+	BeforeEach(func() {
+		row = make([]string, dojColumnCount)
+		row[SUBJECT_ID] = "18675309"
+		row[PRI_DOB] = "19600314"
+		row[STP_EVENT_DATE] = "19790525"
+		row[CNT_ORDER] = "101001001000"
+	})
+
+	It("accepts a valid row", func() {
+		Expect(validateDOJRow(row)).To(BeEmpty())
+	})
+
+	It("rejects rows with too few columns", func() {
+		Expect(validateDOJRow(row[:END_OF_REC])).To(Equal([]string{"too few columns"}))
+	})
+
+	It("accepts extra columns only when they are empty", func() {
+		Expect(validateDOJRow(append(row, "", " "))).To(BeEmpty())
+		Expect(validateDOJRow(append(row, "", "X"))).To(Equal([]string{"unexpected values after END_OF_REC"}))
+	})
+
+	It("rejects rows that are missing required fields", func() {
+		row[SUBJECT_ID] = ""
+		row[CNT_ORDER] = "  "
+		Expect(validateDOJRow(row)).To(Equal([]string{"missing SUBJECT_ID", "missing CNT_ORDER"}))
+	})
+
+	It("rejects rows with invalid dates", func() {
+		row[PRI_DOB] = "1960-03-14"
+		row[STP_EVENT_DATE] = "19791340"
+		Expect(validateDOJRow(row)).To(Equal([]string{"invalid PRI_DOB date", "invalid STP_EVENT_DATE date"}))
+	})
+
+	It("accepts dates where only the month or year is known", func() {
+		row[PRI_DOB] = "19600000"
+		row[STP_EVENT_DATE] = "19790500"
+		Expect(validateDOJRow(row)).To(BeEmpty())
+
+		row[STP_EVENT_DATE] = "19791300"
+		Expect(validateDOJRow(row)).To(Equal([]string{"invalid STP_EVENT_DATE date"}))
+	})
+
+	It("rejects count orders that are not 12 digits", func() {
+		row[CNT_ORDER] = "1010"
+		Expect(validateDOJRow(row)).To(Equal([]string{"invalid CNT_ORDER"}))
+
+		row[CNT_ORDER] = "10100100100A"
+		Expect(validateDOJRow(row)).To(Equal([]string{"invalid CNT_ORDER"}))
+	})
+})
+
+var _ = Describe("validatingRowReader", func() {
+	It("sets aside rejected rows with their row numbers and keeps reading", func() {
+		fields := make([]string, dojColumnCount)
+		fields[SUBJECT_ID] = "18675309"
+		fields[PRI_DOB] = "19600314"
+		fields[STP_EVENT_DATE] = "19790525"
+		fields[CNT_ORDER] = "101001001000"
+		validRow := strings.Join(fields, ",")
+		input := validRow + "\n" + "bare\"quote,18675309\n" + ",,\n" + validRow + "\n"
+
+		rowReader, layout, err := newDOJRowReader(strings.NewReader(input), CSVFormat, DefaultDOJSchema)
+		Expect(err).ToNot(HaveOccurred())
+		reader := newValidatingRowReader(rowReader, layout)
+
+		rows, err := readAllRows(reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows).To(HaveLen(2))
+		Expect(rows[0][CNT_ORDER]).To(Equal("101001001000"))
+		Expect(reader.rowsRead).To(Equal(4))
+
+		rejectedRows := reader.takeRejectedRows()
+		Expect(rejectedRows).To(HaveLen(2))
+		Expect(rejectedRows[0].RowNumber).To(Equal(2))
+		Expect(rejectedRows[0].Reasons).To(Equal([]string{"malformed CSV"}))
+		Expect(rejectedRows[1].RowNumber).To(Equal(3))
+		Expect(rejectedRows[1].Reasons).To(Equal([]string{"too few columns"}))
+		Expect(reader.takeRejectedRows()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,488 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gogen_pilots/matchers"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EligibilityRules describes an eligibility flow as a decision tree. Each node
+// either checks a condition and moves on to its "then" or "else" node, or is a
+// terminal node that sets the conviction's determination and reason. A branch
+// that is left out ends the flow without a determination.
+type EligibilityRules struct {
+	Name            string              `json:"name"`
+	RelevantCharges string              `json:"relevantCharges"`
+	Start           string              `json:"start"`
+	Nodes           map[string]RuleNode `json:"nodes"`
+}
+
+type RuleNode struct {
+	Condition     *RuleCondition `json:"condition,omitempty"`
+	Then          string         `json:"then,omitempty"`
+	Else          string         `json:"else,omitempty"`
+	Determination string         `json:"determination,omitempty"`
+	Reason        string         `json:"reason,omitempty"`
+}
+
+// RuleCondition is a check against a conviction or its subject. Type selects
+// the check; the other fields are parameters used by some types.
+type RuleCondition struct {
+	Type         string          `json:"type"`
+	Date         string          `json:"date,omitempty"`
+	CodeSection  string          `json:"codeSection,omitempty"`
+	CodeSections []string        `json:"codeSections,omitempty"`
+	Age          int             `json:"age,omitempty"`
+	Years        int             `json:"years,omitempty"`
+	AtLeast      int             `json:"atLeast,omitempty"`
+	Condition    *RuleCondition  `json:"condition,omitempty"`
+	Conditions   []RuleCondition `json:"conditions,omitempty"`
+}
+
+const (
+	prop64Charges           = "prop64"
+	prop64AndRelatedCharges = "prop64AndRelated"
+)
+
+var ruleDeterminations = map[string]func(info *EligibilityInfo, reason string){
+	"Eligible for Dismissal":           (*EligibilityInfo).SetEligibleForDismissal,
+	"Eligible for Reduction":           (*EligibilityInfo).SetEligibleForReduction,
+	"Not eligible":                     (*EligibilityInfo).SetNotEligible,
+	"Maybe Eligible - Flag for Review": (*EligibilityInfo).SetMaybeEligible,
+	"Hand Review":                      (*EligibilityInfo).SetHandReview,
+	"To be reviewed by City Attorneys": (*EligibilityInfo).SetCityAttorneyReview,
+}
+
+// Reasons may refer to the run's --individual-age and --years-conviction-free
+// values as {age} and {yearsConvictionFree}.
+var reasonPlaceholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+var reasonPlaceholders = map[string]bool{
+	"{age}":                 true,
+	"{yearsConvictionFree}": true,
+}
+
+var ruleConditionTypes = []string{
+	"all",
+	"allSentencesCompleted",
+	"any",
+	"codeSectionIs",
+	"codeSectionStartsWith",
+	"convictionBefore",
+	"hasPC290BeforeConviction",
+	"hasSuperstrikeBeforeConviction",
+	"isDeceased",
+	"isFelony",
+	"isMisdemeanorOrInfraction",
+	"isProp64Charge",
+	"isRelatedCharge",
+	"noConvictionsInPastYears",
+	"not",
+	"olderThan",
+	"onlyProp64Convictions",
+	"priorConvictionsOfSameCodeSection",
+	"servingSentence",
+	"youngerThanTwentyOne",
+}
+
+type rulesEligibilityFlow struct {
+	rules EligibilityRules
+}
+
+func LoadEligibilityRules(rulesFilePath string) (EligibilityRules, error) {
+	var rules EligibilityRules
+
+	rulesJSON, err := ioutil.ReadFile(rulesFilePath)
+	if err != nil {
+		return rules, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(rulesJSON))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&rules)
+	if err != nil {
+		return rules, fmt.Errorf("could not parse eligibility rules %s: %v", rulesFilePath, err)
+	}
+	return rules, nil
+}
+
+// NewRulesEligibilityFlow checks that the rules form a complete decision tree
+// before returning a flow that interprets them.
+func NewRulesEligibilityFlow(rules EligibilityRules) (EligibilityFlow, error) {
+	switch rules.RelevantCharges {
+	case prop64Charges, prop64AndRelatedCharges:
+	default:
+		return nil, fmt.Errorf("unknown relevantCharges %q: Must be one of %s or %s", rules.RelevantCharges, prop64Charges, prop64AndRelatedCharges)
+	}
+	if _, ok := rules.Nodes[rules.Start]; !ok {
+		return nil, fmt.Errorf("start node %q is not defined", rules.Start)
+	}
+
+	for _, name := range ruleNodeNames(rules.Nodes) {
+		err := validateRuleNode(rules.Nodes, rules.Nodes[name])
+		if err != nil {
+			return nil, fmt.Errorf("node %q: %v", name, err)
+		}
+	}
+
+	reachable := make(map[string]bool)
+	err := checkForRuleCycles(rules.Nodes, rules.Start, reachable, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range ruleNodeNames(rules.Nodes) {
+		if !reachable[name] {
+			return nil, fmt.Errorf("node %q is not reachable from start node %q", name, rules.Start)
+		}
+	}
+
+	return rulesEligibilityFlow{rules: rules}, nil
+}
+
+func ruleNodeNames(nodes map[string]RuleNode) []string {
+	var names []string
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateRuleNode(nodes map[string]RuleNode, node RuleNode) error {
+	if node.Condition == nil {
+		if node.Then != "" || node.Else != "" {
+			return fmt.Errorf("then and else require a condition")
+		}
+		if _, ok := ruleDeterminations[node.Determination]; !ok {
+			return fmt.Errorf("unknown determination %q: Must be one of %v", node.Determination, ruleDeterminationNames())
+		}
+		for _, placeholder := range reasonPlaceholderPattern.FindAllString(node.Reason, -1) {
+			if !reasonPlaceholders[placeholder] {
+				return fmt.Errorf("unknown placeholder %s in reason: Must be {age} or {yearsConvictionFree}", placeholder)
+			}
+		}
+		return nil
+	}
+
+	if node.Determination != "" || node.Reason != "" {
+		return fmt.Errorf("a node with a condition cannot also have a determination")
+	}
+	for _, next := range []string{node.Then, node.Else} {
+		if _, ok := nodes[next]; next != "" && !ok {
+			return fmt.Errorf("node %q is not defined", next)
+		}
+	}
+	return validateRuleCondition(*node.Condition)
+}
+
+func validateRuleCondition(condition RuleCondition) error {
+	switch condition.Type {
+	case "convictionBefore":
+		if _, err := time.Parse("2006-01-02", condition.Date); err != nil {
+			return fmt.Errorf("%s requires a date in the format YYYY-MM-DD, got %q", condition.Type, condition.Date)
+		}
+	case "codeSectionIs":
+		if ok, codeSection := matchers.ExtractProp64Section(condition.CodeSection); !ok || codeSection != condition.CodeSection {
+			return fmt.Errorf("%s requires a Prop 64 codeSection, ex: 11357, got %q", condition.Type, condition.CodeSection)
+		}
+	case "codeSectionStartsWith":
+		if len(condition.CodeSections) == 0 {
+			return fmt.Errorf("%s requires codeSections", condition.Type)
+		}
+		for _, codeSection := range condition.CodeSections {
+			if _, ok := matchers.ParseCodeSection(codeSection); !ok {
+				return fmt.Errorf("%s could not parse code section %q", condition.Type, codeSection)
+			}
+		}
+	case "priorConvictionsOfSameCodeSection":
+		if condition.AtLeast <= 0 {
+			return fmt.Errorf("%s requires atLeast to be positive, got %d", condition.Type, condition.AtLeast)
+		}
+	case "olderThan", "noConvictionsInPastYears":
+		if condition.Age < 0 || condition.Years < 0 {
+			return fmt.Errorf("%s thresholds must not be negative", condition.Type)
+		}
+	case "not":
+		if condition.Condition == nil {
+			return fmt.Errorf("not requires a condition")
+		}
+		return validateRuleCondition(*condition.Condition)
+	case "all", "any":
+		if len(condition.Conditions) == 0 {
+			return fmt.Errorf("%s requires conditions", condition.Type)
+		}
+		for _, child := range condition.Conditions {
+			err := validateRuleCondition(child)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		if !isRuleConditionType(condition.Type) {
+			return fmt.Errorf("unknown condition type %q: Must be one of %v", condition.Type, ruleConditionTypes)
+		}
+	}
+	return nil
+}
+
+func isRuleConditionType(conditionType string) bool {
+	for _, known := range ruleConditionTypes {
+		if known == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleDeterminationNames() []string {
+	var names []string
+	for name := range ruleDeterminations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func checkForRuleCycles(nodes map[string]RuleNode, name string, reachable map[string]bool, path []string) error {
+	for i, visited := range path {
+		if visited == name {
+			return fmt.Errorf("nodes form a cycle: %s", strings.Join(append(path[i:], name), " > "))
+		}
+	}
+	reachable[name] = true
+
+	node := nodes[name]
+	for _, next := range []string{node.Then, node.Else} {
+		if next == "" {
+			continue
+		}
+		err := checkForRuleCycles(nodes, next, reachable, append(path, name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ef rulesEligibilityFlow) ProcessSubject(subject *Subject, comparisonTime time.Time, flowCounty string, age int, yearsConvictionFree int) map[int]*EligibilityInfo {
+	infos := make(map[int]*EligibilityInfo)
+	for _, conviction := range subject.Convictions {
+		if ef.checkRelevancy(conviction.CodeSection, conviction.County, flowCounty) {
+			info := NewEligibilityInfo(conviction, subject, comparisonTime, flowCounty)
+			if !info.alreadyRelieved(conviction) {
+				ef.BeginEligibilityFlow(info, conviction, subject, age, yearsConvictionFree)
+			}
+			infos[conviction.Index] = info
+		}
+	}
+	return infos
+}
+
+func (ef rulesEligibilityFlow) ChecksRelatedCharges() bool {
+	return ef.rules.RelevantCharges == prop64AndRelatedCharges
+}
+
+func (ef rulesEligibilityFlow) checkRelevancy(codeSection string, convictionCounty string, flowCounty string) bool {
+	if convictionCounty != flowCounty {
+		return false
+	}
+	if ef.ChecksRelatedCharges() {
+		return matchers.IsProp64Charge(codeSection) || matchers.IsRelatedCharge(codeSection)
+	}
+	return matchers.IsProp64Charge(codeSection)
+}
+
+// BeginEligibilityFlow walks the tree from the start node, recording a trace
+// step named after each condition node it visits.
+func (ef rulesEligibilityFlow) BeginEligibilityFlow(info *EligibilityInfo, row *DOJRow, subject *Subject, age int, yearsConvictionFree int) {
+	evaluator := ruleEvaluator{info: info, row: row, subject: subject, age: age, yearsConvictionFree: yearsConvictionFree}
+
+	name := ef.rules.Start
+	for name != "" {
+		node := ef.rules.Nodes[name]
+		if node.Condition == nil {
+			setDetermination := ruleDeterminations[node.Determination]
+			setDetermination(info, evaluator.reason(node.Reason))
+			return
+		}
+
+		values := make(map[string]interface{})
+		result, reviewReason := evaluator.evaluate(*node.Condition, values)
+		if len(values) == 0 {
+			values = nil
+		}
+		info.addTraceStep(name, result, values)
+		if reviewReason != "" {
+			info.SetHandReview(reviewReason)
+			return
+		}
+
+		if result {
+			name = node.Then
+		} else {
+			name = node.Else
+		}
+	}
+}
+
+type ruleEvaluator struct {
+	info                *EligibilityInfo
+	row                 *DOJRow
+	subject             *Subject
+	age                 int
+	yearsConvictionFree int
+}
+
+func (e ruleEvaluator) reason(reason string) string {
+	return strings.NewReplacer(
+		"{age}", strconv.Itoa(e.age),
+		"{yearsConvictionFree}", strconv.Itoa(e.yearsConvictionFree),
+	).Replace(reason)
+}
+
+// evaluate checks a condition and adds the values it compared to values, for
+// the trace. Every condition in an "all" or "any" is evaluated so the trace
+// shows all of their values.
+//
+// When the result depends on how precisely DOJ knows a date, evaluate also
+// returns the reason the conviction needs review for it.
+func (e ruleEvaluator) evaluate(condition RuleCondition, values map[string]interface{}) (bool, string) {
+	info, row, subject := e.info, e.row, e.subject
+
+	switch condition.Type {
+	case "isProp64Charge":
+		values["codeSection"] = row.CodeSection
+		return matchers.IsProp64Charge(row.CodeSection), ""
+	case "isRelatedCharge":
+		values["codeSection"] = row.CodeSection
+		return matchers.IsRelatedCharge(row.CodeSection), ""
+	case "isFelony":
+		values["isFelony"] = row.IsFelony
+		return row.IsFelony, ""
+	case "isMisdemeanorOrInfraction":
+		values["isFelony"] = row.IsFelony
+		return !row.IsFelony, ""
+	case "convictionBefore":
+		cutoff, _ := time.Parse("2006-01-02", condition.Date)
+		values["dateOfConviction"] = traceImpreciseDate(info.DateOfConviction, row.DispositionDatePrecision)
+		values["cutoff"] = traceDate(cutoff)
+		return dateCheck(row.convictionBeforeDate(cutoff))("Imprecise date of conviction")
+	case "codeSectionIs":
+		values["codeSection"] = row.CodeSection
+		return row.ParsedCodeSection().Section == condition.CodeSection, ""
+	case "codeSectionStartsWith":
+		values["codeSection"] = row.CodeSection
+		codeSection := row.ParsedCodeSection()
+		for _, prefix := range condition.CodeSections {
+			parsedPrefix, _ := matchers.ParseCodeSection(prefix)
+			if codeSection.StartsWith(parsedPrefix) {
+				return true, ""
+			}
+		}
+		return false, ""
+	case "hasSuperstrikeBeforeConviction":
+		values["superstrikes"] = info.Superstrikes
+		values["earliestSuperstrike"] = traceDate(info.EarliestSuperstrike)
+		values["dateOfConviction"] = traceImpreciseDate(row.DispositionDate, row.DispositionDatePrecision)
+		return dateCheck(info.hasSuperstrikeBefore(row, subject))("Imprecise conviction dates")
+	case "hasPC290BeforeConviction":
+		values["pc290CodeSections"] = info.PC290CodeSections
+		values["pc290Registration"] = info.PC290Registration
+		values["earliestPC290"] = traceDate(info.EarliestPC290)
+		values["dateOfConviction"] = traceImpreciseDate(row.DispositionDate, row.DispositionDatePrecision)
+		return dateCheck(info.hasPC290Before(row, subject))("Imprecise conviction dates")
+	case "priorConvictionsOfSameCodeSection":
+		priorConvictions, _ := info.priorConvictionsOfSameCodeSection(row, subject)
+		values["priorConvictions"] = priorConvictions
+		values["threshold"] = condition.AtLeast
+		return dateCheck(info.hasPriorConvictionsOfSameCodeSection(row, subject, condition.AtLeast))("Imprecise conviction dates")
+	case "olderThan":
+		age := condition.Age
+		if age == 0 {
+			age = e.age
+		}
+		values["dateOfBirth"] = traceImpreciseDate(subject.DOB, subject.DOBPrecision)
+		values["age"] = wholeYearsBetween(subject.DOB, info.comparisonTime)
+		values["threshold"] = age
+		return dateCheck(subject.olderThan(age, info.comparisonTime))("Imprecise date of birth")
+	case "youngerThanTwentyOne":
+		values["dateOfBirth"] = traceImpreciseDate(subject.DOB, subject.DOBPrecision)
+		values["age"] = wholeYearsBetween(subject.DOB, info.comparisonTime)
+		values["threshold"] = 21
+		return dateCheck(info.youngerThanTwentyOne(row, subject))("Imprecise date of birth")
+	case "onlyProp64Convictions":
+		values["convictions"] = len(subject.Convictions)
+		values["prop64Convictions"] = info.NumberOfProp64Convictions
+		return info.onlyProp64Convictions(row, subject), ""
+	case "allSentencesCompleted":
+		values["latestSentenceEndDate"] = info.traceLatestSentenceEndDate(subject)
+		return info.allSentencesCompleted(row, subject), ""
+	case "servingSentence":
+		values["latestSentenceEndDate"] = info.traceLatestSentenceEndDate(subject)
+		values["comparisonDate"] = traceDate(info.comparisonTime)
+		return !info.allSentencesCompleted(row, subject), ""
+	case "noConvictionsInPastYears":
+		years := condition.Years
+		if years == 0 {
+			years = e.yearsConvictionFree
+		}
+		values["mostRecentConviction"] = traceDate(subject.MostRecentConvictionDate())
+		values["cutoff"] = traceDate(info.comparisonTime.AddDate(-years, 0, 0))
+		values["yearsConvictionFree"] = years
+		return dateCheck(info.noConvictionsInGivenTimePeriod(row, subject, years))("Imprecise conviction dates")
+	case "isDeceased":
+		return subject.IsDeceased, ""
+	case "not":
+		result, reviewReason := e.evaluate(*condition.Condition, values)
+		return !result, reviewReason
+	case "all":
+		// An "all" with a condition that is certainly false is certain,
+		// however precisely the dates of its other conditions are known.
+		result, reviewReason, certainlyFalse := true, "", false
+		for _, child := range condition.Conditions {
+			childResult, childReviewReason := e.evaluate(child, values)
+			result = childResult && result
+			certainlyFalse = certainlyFalse || (!childResult && childReviewReason == "")
+			if reviewReason == "" {
+				reviewReason = childReviewReason
+			}
+		}
+		if certainlyFalse {
+			return false, ""
+		}
+		return result, reviewReason
+	case "any":
+		result, reviewReason, certainlyTrue := false, "", false
+		for _, child := range condition.Conditions {
+			childResult, childReviewReason := e.evaluate(child, values)
+			result = childResult || result
+			certainlyTrue = certainlyTrue || (childResult && childReviewReason == "")
+			if reviewReason == "" {
+				reviewReason = childReviewReason
+			}
+		}
+		if certainlyTrue {
+			return true, ""
+		}
+		return result, reviewReason
+	}
+	return false, ""
+}
+
+// dateCheck turns the result of a date check, and whether it is certain, into
+// the result of evaluate, with reason when it is not certain.
+func dateCheck(result bool, certain bool) func(reason string) (bool, string) {
+	return func(reason string) (bool, string) {
+		if certain {
+			return result, ""
+		}
+		return result, reason
+	}
+}
@@ -0,0 +1,212 @@
+package data_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen_pilots/data"
+	. "gogen_pilots/test_fixtures"
+)
+
+var _ = Describe("rulesEligibilityFlow", func() {
+	const COUNTY = "LOS ANGELES"
+
+	var rules EligibilityRules
+
+	BeforeEach(func() {
+		var err error
+		rules, err = LoadEligibilityRules(path.Join("..", "eligibility_rules", "los_angeles.json"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("Loading eligibility rules", func() {
+		It("accepts every rules file in eligibility_rules", func() {
+			rulesFiles, err := filepath.Glob(path.Join("..", "eligibility_rules", "*.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rulesFiles).ToNot(BeEmpty())
+
+			for _, rulesFile := range rulesFiles {
+				rules, err := LoadEligibilityRules(rulesFile)
+				Expect(err).ToNot(HaveOccurred(), rulesFile)
+				_, err = NewRulesEligibilityFlow(rules)
+				Expect(err).ToNot(HaveOccurred(), rulesFile)
+			}
+		})
+
+		It("rejects rules with unknown fields", func() {
+			rulesFile, err := ioutil.TempFile("", "eligibility_rules")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(rulesFile.Name())
+			rulesFile.WriteString(`{"start": "Begin", "nodes": {"Begin": {"determination": "Hand Review", "otherwise": "End"}}}`)
+			rulesFile.Close()
+
+			_, err = LoadEligibilityRules(rulesFile.Name())
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "otherwise"`)))
+		})
+	})
+
+	Describe("Validating eligibility rules", func() {
+		It("rejects unknown relevant charges", func() {
+			rules.RelevantCharges = "all"
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError(`unknown relevantCharges "all": Must be one of prop64 or prop64AndRelated`))
+		})
+
+		It("rejects a missing start node", func() {
+			rules.Start = "Begin"
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError(`start node "Begin" is not defined`))
+		})
+
+		It("rejects branches to undefined nodes", func() {
+			node := rules.Nodes["IsDeceased"]
+			node.Else = "NoCriteria"
+			rules.Nodes["IsDeceased"] = node
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError(`node "IsDeceased": node "NoCriteria" is not defined`))
+		})
+
+		It("rejects unknown determinations", func() {
+			rules.Nodes["DismissDeceased"] = RuleNode{Determination: "Dismissed", Reason: "Deceased"}
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError(ContainSubstring(`node "DismissDeceased": unknown determination "Dismissed"`)))
+		})
+
+		It("rejects unknown placeholders in reasons", func() {
+			rules.Nodes["DismissDeceased"] = RuleNode{Determination: "Eligible for Dismissal", Reason: "Deceased {dateOfDeath}"}
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError(`node "DismissDeceased": unknown placeholder {dateOfDeath} in reason: Must be {age} or {yearsConvictionFree}`))
+		})
+
+		It("rejects unknown condition types", func() {
+			node := rules.Nodes["IsDeceased"]
+			node.Condition = &RuleCondition{Type: "not", Condition: &RuleCondition{Type: "isAlive"}}
+			rules.Nodes["IsDeceased"] = node
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError(ContainSubstring(`node "IsDeceased": unknown condition type "isAlive"`)))
+		})
+
+		It("rejects conditions with invalid parameters", func() {
+			node := rules.Nodes["ConvictionBeforeNovNine2016"]
+			node.Condition = &RuleCondition{Type: "convictionBefore", Date: "11/09/2016"}
+			rules.Nodes["ConvictionBeforeNovNine2016"] = node
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError(`node "ConvictionBeforeNovNine2016": convictionBefore requires a date in the format YYYY-MM-DD, got "11/09/2016"`))
+		})
+
+		It("rejects nodes that form a cycle", func() {
+			node := rules.Nodes["IsDeceased"]
+			node.Else = "ServingSentence"
+			rules.Nodes["IsDeceased"] = node
+			delete(rules.Nodes, "NoApplicableEligibilityCriteria")
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError("nodes form a cycle: ServingSentence > IsDeceased > ServingSentence"))
+		})
+
+		It("rejects nodes that cannot be reached", func() {
+			rules.Nodes["Unused"] = RuleNode{Determination: "Hand Review", Reason: "Unused"}
+			_, err := NewRulesEligibilityFlow(rules)
+			Expect(err).To(MatchError(`node "Unused" is not reachable from start node "BeginEligibilityFlow"`))
+		})
+	})
+
+	Describe("Processing a subject", func() {
+		comparisonTime := time.Date(2019, 11, 11, 0, 0, 0, 0, time.UTC)
+
+		It("checks related charges when the rules ask for them", func() {
+			rules.RelevantCharges = "prop64AndRelated"
+			flow, err := NewRulesEligibilityFlow(rules)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(flow.ChecksRelatedCharges()).To(BeTrue())
+		})
+
+		It("leaves the determination empty when a branch is left out", func() {
+			conviction := DOJRow{
+				SubjectID:       "1",
+				DOB:             time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC),
+				WasConvicted:    true,
+				CodeSection:     "11364 HS",
+				DispositionDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+				County:          COUNTY,
+				CountOrder:      "101001001000",
+			}
+			rules.RelevantCharges = "prop64AndRelated"
+			flow, err := NewRulesEligibilityFlow(rules)
+			Expect(err).ToNot(HaveOccurred())
+
+			subject := Subject{}
+			subject.PushRow(conviction)
+			infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY, 50, 10)
+			Expect(infos).To(HaveKey(0))
+			Expect(infos[0].EligibilityDetermination).To(BeEmpty())
+			Expect(infos[0].TraceSummary()).To(Equal("BeginEligibilityFlow: no (codeSection=11364 HS)"))
+		})
+
+		It("sends convictions to hand review when a condition depends on an imprecise date, like the Los Angeles flow", func() {
+			conviction := DOJRow{
+				SubjectID:       "1",
+				DOB:             time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+				DOBPrecision:    YearPrecision,
+				WasConvicted:    true,
+				CodeSection:     "11359 HS",
+				DispositionDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+				County:          COUNTY,
+				CountOrder:      "101001001000",
+				IsFelony:        true,
+			}
+			flow, err := NewRulesEligibilityFlow(rules)
+			Expect(err).ToNot(HaveOccurred())
+
+			subject := Subject{}
+			subject.PushRow(conviction)
+			infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY, 49, 10)
+			Expect(infos[0].EligibilityDetermination).To(Equal("Hand Review"))
+			Expect(infos[0].EligibilityReason).To(Equal("Imprecise date of birth"))
+			Expect(infos[0].TraceSummary()).To(HaveSuffix("OlderThanGivenAge: yes (age=49, dateOfBirth=1970, threshold=49)"))
+
+			expected := EligibilityFlows[COUNTY].ProcessSubject(&subject, comparisonTime, COUNTY, 49, 10)
+			Expect(infos[0].TraceSummary()).To(Equal(expected[0].TraceSummary()))
+			Expect(infos[0].EligibilityReason).To(Equal(expected[0].EligibilityReason))
+
+			infos = flow.ProcessSubject(&subject, comparisonTime, COUNTY, 48, 10)
+			Expect(infos[0].EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+			Expect(infos[0].EligibilityReason).To(Equal("48 years or older"))
+		})
+
+		Context("Using the Los Angeles rules on the los_angeles.xlsx fixture", func() {
+			var dojInformation *DOJInformation
+
+			BeforeEach(func() {
+				pathToDOJ, _, err := ExtractFullCSVFixtures(path.Join("..", "test_fixtures", "los_angeles.xlsx"))
+				Expect(err).ToNot(HaveOccurred())
+				dojInformation, err = NewDOJInformation(pathToDOJ, comparisonTime, EligibilityFlows[COUNTY])
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			for _, thresholds := range [][2]int{{50, 10}, {40, 5}} {
+				age, yearsConvictionFree := thresholds[0], thresholds[1]
+
+				It("matches the Los Angeles eligibility flow", func() {
+					flow, err := NewRulesEligibilityFlow(rules)
+					Expect(err).ToNot(HaveOccurred())
+
+					expected := dojInformation.DetermineEligibility(COUNTY, EligibilityFlows[COUNTY], age, yearsConvictionFree)
+					actual := dojInformation.DetermineEligibility(COUNTY, flow, age, yearsConvictionFree)
+
+					Expect(actual).To(HaveLen(len(expected)))
+					for index, expectedInfo := range expected {
+						Expect(actual).To(HaveKey(index))
+						Expect(actual[index].EligibilityDetermination).To(Equal(expectedInfo.EligibilityDetermination), "row %d", index)
+						Expect(actual[index].EligibilityReason).To(Equal(expectedInfo.EligibilityReason), "row %d", index)
+						Expect(actual[index].TraceSummary()).To(Equal(expectedInfo.TraceSummary()), "row %d", index)
+					}
+				})
+			}
+		})
+	})
+})
@@ -0,0 +1,171 @@
+package data
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SentenceType tells the custody of a sentence apart from its probation.
+type SentenceType int
+
+const (
+	NoTermSentence SentenceType = iota
+	CustodySentence
+	ProbationSentence
+)
+
+var sentenceTypeNames = [...]string{
+	NoTermSentence:    "no term",
+	CustodySentence:   "custody",
+	ProbationSentence: "probation",
+}
+
+func (sentenceType SentenceType) String() string {
+	return sentenceTypeNames[sentenceType]
+}
+
+// SentenceSegment is the SENT_* segment of a DOJ row, one part of the sentence
+// of its count, ex: 90 days of jail or 3 years of probation.
+type SentenceSegment struct {
+	Order      string
+	Location   string
+	Type       SentenceType
+	Length     int
+	TimeCode   string
+	Life       bool
+	Concurrent bool
+}
+
+// newSentenceSegment reads the SENT_* segment of a row. Segments whose
+// SENT_LOC_DESCR is probation are probation, and other segments with a term,
+// like jail or prison, are custody. Fines and other segments without a term
+// have no term. Life and indeterminate terms are recognized by a SENT_TIME_CODE
+// of L, or by their description. Terms are consecutive to the ones before
+// them, unless their description says they are concurrent.
+func newSentenceSegment(rawRow []string) SentenceSegment {
+	location := strings.TrimSpace(rawRow[SENT_LOC_DESCR])
+	timeCode := strings.TrimSpace(rawRow[SENT_TIME_CODE])
+	description := strings.ToUpper(location + " " + rawRow[SENT_TIME_DESCR])
+	length, _ := strconv.Atoi(strings.TrimSpace(rawRow[SENT_LENGTH]))
+
+	segment := SentenceSegment{
+		Order:      strings.TrimSpace(rawRow[SENT_ORDER]),
+		Location:   location,
+		Length:     length,
+		TimeCode:   timeCode,
+		Life:       timeCode == "L" || strings.Contains(description, "LIFE") || strings.Contains(description, "INDETERMINATE"),
+		Concurrent: strings.Contains(description, "CONCURRENT"),
+	}
+	switch {
+	case strings.Contains(description, "PROBATION"):
+		segment.Type = ProbationSentence
+	case segment.Life || (length > 0 && isSentenceTimeCode(timeCode)):
+		segment.Type = CustodySentence
+	}
+	return segment
+}
+
+func isSentenceTimeCode(timeCode string) bool {
+	return timeCode == "D" || timeCode == "M" || timeCode == "Y"
+}
+
+// endDate adds the term of the segment to start, by the calendar.
+func (segment SentenceSegment) endDate(start time.Time) time.Time {
+	switch segment.TimeCode {
+	case "D":
+		return start.AddDate(0, 0, segment.Length)
+	case "M":
+		return start.AddDate(0, segment.Length, 0)
+	case "Y":
+		return start.AddDate(segment.Length, 0, 0)
+	}
+	return start
+}
+
+// Sentence is the sentence of a conviction, made of the segments of the rows
+// of its count in SENT_ORDER.
+type Sentence struct {
+	Segments []SentenceSegment
+}
+
+func newSentence(segment SentenceSegment) Sentence {
+	if segment.Type == NoTermSentence {
+		return Sentence{}
+	}
+	return Sentence{Segments: []SentenceSegment{segment}}
+}
+
+// withSegments returns the sentence with more segments, ordered by SENT_ORDER
+// when every segment has one, and in the order they were read otherwise.
+func (sentence Sentence) withSegments(segments ...SentenceSegment) Sentence {
+	combined := append(append([]SentenceSegment{}, sentence.Segments...), segments...)
+	for _, segment := range combined {
+		if _, err := strconv.Atoi(segment.Order); err != nil {
+			return Sentence{Segments: combined}
+		}
+	}
+	sort.SliceStable(combined, func(i, j int) bool {
+		first, _ := strconv.Atoi(combined[i].Order)
+		second, _ := strconv.Atoi(combined[j].Order)
+		return first < second
+	})
+	return Sentence{Segments: combined}
+}
+
+// IsLife is whether the sentence includes a life or indeterminate term, which
+// has no end date.
+func (sentence Sentence) IsLife() bool {
+	for _, segment := range sentence.Segments {
+		if segment.Life {
+			return true
+		}
+	}
+	return false
+}
+
+// CustodyEndDate is the day custody ends for a sentence that starts on start.
+func (sentence Sentence) CustodyEndDate(start time.Time) time.Time {
+	return sentence.termEndDate(start, CustodySentence)
+}
+
+// ProbationEndDate is the day probation ends for a sentence that starts on
+// start. Probation runs from start, alongside any custody.
+func (sentence Sentence) ProbationEndDate(start time.Time) time.Time {
+	return sentence.termEndDate(start, ProbationSentence)
+}
+
+// EndDate is the day both the custody and probation of a sentence that
+// starts on start end. It is not meaningful for a life term; see IsLife.
+func (sentence Sentence) EndDate(start time.Time) time.Time {
+	custodyEndDate := sentence.CustodyEndDate(start)
+	probationEndDate := sentence.ProbationEndDate(start)
+	if probationEndDate.After(custodyEndDate) {
+		return probationEndDate
+	}
+	return custodyEndDate
+}
+
+// termEndDate runs the segments of a type one after another from start,
+// except for concurrent segments, which run from start alongside them.
+func (sentence Sentence) termEndDate(start time.Time, sentenceType SentenceType) time.Time {
+	consecutiveEndDate := start
+	latestEndDate := start
+	for _, segment := range sentence.Segments {
+		if segment.Type != sentenceType {
+			continue
+		}
+		var endDate time.Time
+		if segment.Concurrent {
+			endDate = segment.endDate(start)
+		} else {
+			consecutiveEndDate = segment.endDate(consecutiveEndDate)
+			endDate = consecutiveEndDate
+		}
+		if endDate.After(latestEndDate) {
+			latestEndDate = endDate
+		}
+	}
+	return latestEndDate
+}
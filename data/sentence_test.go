@@ -0,0 +1,90 @@
+package data_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "gogen_pilots/data"
+)
+
+var _ = Describe("Sentence", func() {
+	dispositionDate := time.Date(2016, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	// sentenceRow is a Los Angeles felony conviction of count 101001001000 with
+	// one SENT_* segment.
+	sentenceRow := func(index int, order string, location string, length string, timeCode string, timeDescription string) DOJRow {
+		rawRow := make([]string, len(DOJColumnNames))
+		rawRow[SUBJECT_ID] = "1"
+		rawRow[PRI_DOB] = "19900101"
+		rawRow[STP_EVENT_DATE] = "20160131"
+		rawRow[STP_ORI_CNTY_NAME] = "LOS ANGELES"
+		rawRow[DISP_DESCR] = "CONVICTED"
+		rawRow[CONV_STAT_DESCR] = "FELONY"
+		rawRow[OFFENSE_DESCR] = "11359 HS-POSSESS MARIJUANA FOR SALE"
+		rawRow[CNT_ORDER] = "101001001000"
+		rawRow[SENT_ORDER] = order
+		rawRow[SENT_LOC_DESCR] = location
+		rawRow[SENT_LENGTH] = length
+		rawRow[SENT_TIME_CODE] = timeCode
+		rawRow[SENT_TIME_DESCR] = timeDescription
+		return NewDOJRow(rawRow, index)
+	}
+
+	sentenceOf := func(rows ...DOJRow) *DOJRow {
+		subject := Subject{}
+		for _, row := range rows {
+			subject.PushRow(row)
+		}
+		Expect(subject.Convictions).To(HaveLen(1))
+		return subject.Convictions[0]
+	}
+
+	It("adds months by the calendar", func() {
+		conviction := sentenceOf(sentenceRow(0, "", "JAIL", "1", "M", ""))
+		Expect(conviction.Sentence.Segments).To(Equal([]SentenceSegment{{Location: "JAIL", Type: CustodySentence, Length: 1, TimeCode: "M"}}))
+		Expect(conviction.SentenceEndDate).To(Equal(time.Date(2016, time.March, 2, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("runs probation alongside custody instead of after it", func() {
+		conviction := sentenceOf(
+			sentenceRow(0, "", "JAIL", "90", "D", ""),
+			sentenceRow(1, "", "PROBATION", "3", "Y", ""),
+			sentenceRow(2, "", "FINE", "", "", ""),
+		)
+		Expect(conviction.Sentence.Segments).To(HaveLen(2))
+		Expect(conviction.Sentence.CustodyEndDate(dispositionDate)).To(Equal(time.Date(2016, time.April, 30, 0, 0, 0, 0, time.UTC)))
+		Expect(conviction.Sentence.ProbationEndDate(dispositionDate)).To(Equal(time.Date(2019, time.January, 31, 0, 0, 0, 0, time.UTC)))
+		Expect(conviction.SentenceEndDate).To(Equal(time.Date(2019, time.January, 31, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("runs custody terms consecutively in SENT_ORDER, unless they are concurrent", func() {
+		conviction := sentenceOf(
+			sentenceRow(0, "2", "PRISON", "2", "Y", ""),
+			sentenceRow(1, "1", "JAIL", "6", "M", ""),
+			sentenceRow(2, "3", "PRISON", "1", "Y", "CONCURRENT"),
+		)
+		Expect(conviction.Sentence.Segments[0].Order).To(Equal("1"))
+		Expect(conviction.Sentence.Segments[2].Concurrent).To(BeTrue())
+		Expect(conviction.SentenceEndDate).To(Equal(time.Date(2018, time.July, 31, 0, 0, 0, 0, time.UTC)))
+
+		conviction = sentenceOf(
+			sentenceRow(0, "1", "JAIL", "6", "M", ""),
+			sentenceRow(1, "2", "PRISON", "4", "Y", "CONCURRENT"),
+		)
+		Expect(conviction.SentenceEndDate).To(Equal(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("never completes a life term", func() {
+		row := sentenceRow(0, "", "PRISON", "25", "L", "LIFE")
+		Expect(row.Sentence.IsLife()).To(BeTrue())
+
+		subject := Subject{}
+		subject.PushRow(row)
+		infos := EligibilityFlows["LOS ANGELES"].ProcessSubject(&subject, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), "LOS ANGELES", 200, 100)
+		Expect(infos[0].EligibilityDetermination).To(Equal("Hand Review"))
+		Expect(infos[0].EligibilityReason).To(Equal("Currently serving sentence"))
+		Expect(infos[0].TraceSummary()).To(HaveSuffix("ServingSentence: yes (comparisonDate=2100-01-01, latestSentenceEndDate=life)"))
+	})
+})
@@ -9,8 +9,9 @@ type Subject struct {
 	ID                      string
 	Name                    string
 	DOB                     time.Time
+	DOBPrecision            DatePrecision
 	Convictions             []*DOJRow
-	seenConvictions         map[string]bool
+	convictionsByCount      map[string]*DOJRow
 	PC290Registration       bool
 	CyclesWithProp64Charges map[string]bool
 	CaseNumbers             map[string][]string
@@ -22,14 +23,20 @@ func (subject *Subject) PushRow(row DOJRow) {
 		subject.ID = row.SubjectID
 		subject.Name = row.Name
 		subject.DOB = row.DOB
-		subject.seenConvictions = make(map[string]bool)
+		subject.DOBPrecision = row.DOBPrecision
+		subject.convictionsByCount = make(map[string]*DOJRow)
 		subject.CyclesWithProp64Charges = make(map[string]bool)
 		subject.CaseNumbers = make(map[string][]string)
 	}
-	if row.WasConvicted && subject.seenConvictions[row.CountOrder] {
-		lastConviction := subject.Convictions[len(subject.Convictions)-1]
-		newEndDate := lastConviction.SentenceEndDate.Add(row.SentencePartDuration)
-		lastConviction.SentenceEndDate = newEndDate
+	// Later rows of a convicted count either add to its sentence or are court
+	// actions that relieved the conviction, like a PC 1203.4 dismissal.
+	if conviction := subject.convictionsByCount[row.countKey()]; conviction != nil {
+		if row.Relief.Type != NoRelief {
+			conviction.Relief = row.Relief
+		} else if row.WasConvicted {
+			conviction.Sentence = conviction.Sentence.withSegments(row.Sentence.Segments...)
+			conviction.SentenceEndDate = conviction.Sentence.EndDate(conviction.DispositionDate)
+		}
 	}
 
 	if row.Type == "DECEASED" {
@@ -37,21 +44,23 @@ func (subject *Subject) PushRow(row DOJRow) {
 	}
 
 	if row.Type == "COURT ACTION" && row.OFN != "" {
-		subject.CaseNumbers[row.CountOrder[0:6]] = setAppend(subject.CaseNumbers[row.CountOrder[0:6]], row.OFN)
+		subject.CaseNumbers[row.caseKey()] = setAppend(subject.CaseNumbers[row.caseKey()], row.OFN)
 	}
 	if row.IsPC290Registration {
 		subject.PC290Registration = true
 	}
-	if row.WasConvicted && !subject.seenConvictions[row.CountOrder] {
-		row.HasProp64ChargeInCycle = subject.CyclesWithProp64Charges[row.CountOrder[0:3]]
+	if row.WasConvicted && subject.convictionsByCount[row.countKey()] == nil {
+		// Only later court actions relieve a conviction, not the row entering it.
+		row.Relief = Relief{}
+		row.HasProp64ChargeInCycle = subject.CyclesWithProp64Charges[row.cycleKey()]
 		subject.Convictions = append(subject.Convictions, &row)
-		subject.seenConvictions[row.CountOrder] = true
+		subject.convictionsByCount[row.countKey()] = &row
 	}
 
 	if matchers.IsProp64Charge(row.CodeSection) {
-		subject.CyclesWithProp64Charges[row.CountOrder[0:3]] = true
+		subject.CyclesWithProp64Charges[row.cycleKey()] = true
 		for _, conviction := range subject.Convictions {
-			if conviction.CountOrder[0:3] == row.CountOrder[0:3] {
+			if conviction.cycleKey() == row.cycleKey() {
 				conviction.HasProp64ChargeInCycle = true
 			}
 		}
@@ -80,10 +89,10 @@ func (subject *Subject) SuperstrikeCodeSections() []string {
 			result = append(result, row.CodeSection)
 		}
 		if IsGangEnhancement(row.CodeSection) {
-			gangEnhancementByCase[row.CountOrder[0:6]] = row.CodeSection
+			gangEnhancementByCase[row.caseKey()] = row.CodeSection
 		}
 		if IsEnhanceableOffense(row.CodeSection) {
-			enhanceableOffenseByCase[row.CountOrder[0:6]] = append(enhanceableOffenseByCase[row.CountOrder[0:6]], row.CodeSection)
+			enhanceableOffenseByCase[row.caseKey()] = append(enhanceableOffenseByCase[row.caseKey()], row.CodeSection)
 		}
 	}
 	for caseFromCountOrder, gangEnhancementCodeSection := range gangEnhancementByCase {
@@ -215,6 +224,31 @@ func (subject *Subject) computeEligibilities(infos map[int]*EligibilityInfo, com
 	}
 }
 
-func (subject *Subject) olderThan(years int, t time.Time) bool {
-	return !subject.DOB.AddDate(years, 0, 0).After(t)
+func (subject *Subject) dobBounds() dateBounds {
+	return newDateBounds(subject.DOB, subject.DOBPrecision)
+}
+
+// olderThan reports whether the subject is at least years old at t, and
+// whether that is certain given how precisely their date of birth is known.
+func (subject *Subject) olderThan(years int, t time.Time) (bool, bool) {
+	younger, certain := exactDate(t).before(subject.dobBounds().addYears(years))
+	return !younger, certain
+}
+
+// hasConvictionBefore reports whether any of the subject's convictions that
+// match was before row, and whether that is certain given how precisely the
+// dates of the convictions are known.
+func (subject *Subject) hasConvictionBefore(row *DOJRow, matches func(conviction *DOJRow) bool) (bool, bool) {
+	allCertain := true
+	for _, conviction := range subject.Convictions {
+		if !matches(conviction) {
+			continue
+		}
+		before, certain := conviction.dispositionDateBounds().before(row.dispositionDateBounds())
+		if before && certain {
+			return true, true
+		}
+		allCertain = allCertain && certain
+	}
+	return false, allCertain
 }
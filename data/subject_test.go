@@ -23,7 +23,8 @@ var _ = Describe("Subject", func() {
 		birthDate              time.Time
 	)
 
-	days := time.Duration(24) * (time.Hour)
+	prisonSentence := data.Sentence{Segments: []data.SentenceSegment{{Order: "1", Location: "PRISON", Type: data.CustodySentence, Length: 2, TimeCode: "Y"}}}
+	jailSentence := data.Sentence{Segments: []data.SentenceSegment{{Order: "2", Location: "JAIL", Type: data.CustodySentence, Length: 30, TimeCode: "D"}}}
 
 	BeforeEach(func() {
 		birthDate = time.Date(1994, time.April, 10, 0, 0, 0, 0, time.UTC)
@@ -32,8 +33,8 @@ var _ = Describe("Subject", func() {
 		superstrikeConviction2 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1119999", DOB: birthDate, CodeSection: "286(D)(1) PC", WasConvicted: true, CountOrder: "102001003000", DispositionDate: time.Date(2009, time.May, 4, 0, 0, 0, 0, time.UTC), County: "LOS ANGELES"}
 		superstrikeonviction3 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1118888", DOB: birthDate, CodeSection: "187 PC", WasConvicted: true, CountOrder: "103001004000", DispositionDate: time.Date(2001, time.May, 4, 0, 0, 0, 0, time.UTC), County: "LOS ANGELES"}
 		conviction4 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 12345678-00", DOB: birthDate, CodeSection: "11360 HS", WasConvicted: true,CountOrder: "104001005000", DispositionDate: time.Date(2011, time.May, 12, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO"}
-		pC290Conviction5 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 334455-00", DOB: birthDate, CodeSection: "266J PC", WasConvicted: true, CountOrder: "104001006000", DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO", SentenceEndDate: time.Date(2012, 03, 04, 0, 0, 0, 0, time.UTC)}
-		conviction6Prison = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 334455-00", DOB: birthDate, CodeSection: "11360 HS", WasConvicted: true, CountOrder: "104001006000", DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO", SentencePartDuration: time.Duration(30 * days)}
+		pC290Conviction5 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 334455-00", DOB: birthDate, CodeSection: "266J PC", WasConvicted: true, CountOrder: "104001006000", DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO", Sentence: prisonSentence, SentenceEndDate: time.Date(2011, 12, 05, 0, 0, 0, 0, time.UTC)}
+		conviction6Prison = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 334455-00", DOB: birthDate, CodeSection: "11360 HS", WasConvicted: true, CountOrder: "104001006000", DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO", Sentence: jailSentence}
 		registration := data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 12345678-00", DOB: birthDate, CodeSection: "290 PC", WasConvicted: false, CountOrder: "105001007000", DispositionDate: time.Date(2008, time.June, 19, 0, 0, 0, 0, time.UTC), IsPC290Registration: true}
 
 		rows := []data.DOJRow{conviction1, nonConviction, superstrikeConviction2, registration, superstrikeonviction3, conviction4, pC290Conviction5, conviction6Prison}
@@ -60,7 +61,8 @@ var _ = Describe("Subject", func() {
 			expectedSuperstrikeConviction3.HasProp64ChargeInCycle = false
 			expectedConviction4.HasProp64ChargeInCycle = true
 			expectedPC290Conviction5.HasProp64ChargeInCycle = true
-			expectedPC290Conviction5.SentenceEndDate = time.Date(2012, 04, 03, 0, 0, 0, 0, time.UTC)
+			expectedPC290Conviction5.Sentence = data.Sentence{Segments: append(prisonSentence.Segments, jailSentence.Segments...)}
+			expectedPC290Conviction5.SentenceEndDate = time.Date(2012, 01, 04, 0, 0, 0, 0, time.UTC)
 
 			Expect(subject.Convictions).To(ConsistOf(
 				&expectedConviction1,
@@ -71,7 +73,7 @@ var _ = Describe("Subject", func() {
 			))
 
 			Expect(subject.Convictions).ToNot(ConsistOf(&conviction6Prison))
-			Expect(subject.Convictions[4].SentenceEndDate).To(Equal(time.Date(2012, 04, 03, 0, 0, 0, 0, time.UTC)))
+			Expect(subject.Convictions[4].SentenceEndDate).To(Equal(time.Date(2012, 01, 04, 0, 0, 0, 0, time.UTC)))
 		})
 	})
 
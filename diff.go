@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gogen_pilots/exporter"
+	"gogen_pilots/utilities"
+)
+
+type diffOpts struct {
+	Before       string `long:"before" description:"The output folder of the earlier run"`
+	After        string `long:"after" description:"The output folder of the later run"`
+	OutputFolder string `long:"outputs" description:"The folder in which to place the CSV of changed determinations"`
+}
+
+func (d diffOpts) Execute(args []string) error {
+	err := d.diff(os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if _, ok := err.(diffOptionError); ok {
+			os.Exit(utilities.INVALID_RUN_OPTION_ERROR)
+		}
+		os.Exit(utilities.FILE_PROCESSING_ERROR)
+	}
+	return nil
+}
+
+type diffOptionError struct {
+	error
+}
+
+// diff compares the results of two runs, writes every conviction whose
+// determination or reason changed to gogen_pilots_diff.csv, and prints the
+// determination transitions and the changes to the summary JSON.
+func (d diffOpts) diff(w io.Writer) error {
+	if d.Before == "" || d.After == "" || d.OutputFolder == "" {
+		return diffOptionError{errors.New("missing required field: diff needs --before, --after and --outputs")}
+	}
+
+	resultsDiff, err := exporter.DiffResults(d.Before, d.After)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(d.OutputFolder, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	diffFilePath := filepath.Join(d.OutputFolder, "gogen_pilots_diff.csv")
+	diffWriter, err := exporter.NewWriter(diffFilePath, exporter.ResultsDiffHeaders)
+	if err != nil {
+		return err
+	}
+	resultsDiff.WriteChanges(diffWriter)
+	err = diffWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%d convictions changed eligibility determination or reason, written to %s\n", len(resultsDiff.Changes), diffFilePath)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Eligibility determinations, before → after:")
+	resultsDiff.PrintTransitions(w)
+	fmt.Fprintln(w)
+	if len(resultsDiff.SummaryChanges) == 0 {
+		fmt.Fprintln(w, "The summaries are the same")
+		return nil
+	}
+	fmt.Fprintln(w, "Summary changes, before → after:")
+	resultsDiff.PrintSummaryChanges(w)
+	return nil
+}
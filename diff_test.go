@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	path "path/filepath"
+	"regexp"
+
+	. "gogen_pilots/test_fixtures"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("diff", func() {
+	var pathToGogen string
+
+	BeforeEach(func() {
+		var err error
+		pathToGogen, err = gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	start := func(args ...string) *gexec.Session {
+		session, err := gexec.Start(exec.Command(pathToGogen, args...), GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		return session
+	}
+
+	It("reports the convictions whose eligibility changed between two runs", func() {
+		pathToDOJ, _, err := ExtractFullCSVFixtures(path.Join("test_fixtures", "los_angeles.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+		beforeDir, err := ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		afterDir, err := ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		diffDir, err := ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		session := start("run", fmt.Sprintf("--input-doj=%s", pathToDOJ), fmt.Sprintf("--outputs=%s", beforeDir), "--compute-at=2019-11-11")
+		Eventually(session, "10s").Should(gexec.Exit(0))
+		session = start("run", fmt.Sprintf("--input-doj=%s", pathToDOJ), fmt.Sprintf("--outputs=%s", afterDir), "--compute-at=2019-11-11",
+			fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json")))
+		Eventually(session, "10s").Should(gexec.Exit(0))
+
+		session = start("diff", fmt.Sprintf("--before=%s", beforeDir), fmt.Sprintf("--after=%s", afterDir), fmt.Sprintf("--outputs=%s", diffDir))
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Out).To(gbytes.Say("16 convictions changed eligibility determination or reason"))
+		Expect(session.Out).To(gbytes.Say("Hand Review → Eligible for Dismissal: 3"))
+		Expect(session.Out).To(gbytes.Say("Not eligible → Eligible for Reduction: 1"))
+		Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("subjectsWithSomeReliefCount: 7 → 10")))
+
+		diffCSV, err := ioutil.ReadFile(path.Join(diffDir, "gogen_pilots_diff.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(diffCSV)).To(ContainSubstring("1,84734892,101001024000,11359HS,Not eligible,PC 667(e)(2)(c)(iv),Eligible for Reduction,Reduce all HS 11359 convictions\n"))
+	})
+
+	It("fails when an output folder has no results", func() {
+		emptyDir, err := ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		session := start("diff", fmt.Sprintf("--before=%s", emptyDir), fmt.Sprintf("--after=%s", emptyDir), fmt.Sprintf("--outputs=%s", emptyDir))
+		Eventually(session).Should(gexec.Exit(2))
+		Expect(session.Err).To(gbytes.Say("has no DOJ_Input_File_N_Results folders"))
+
+		session = start("diff", fmt.Sprintf("--before=%s", emptyDir))
+		Eventually(session).Should(gexec.Exit(3))
+		Expect(session.Err).To(gbytes.Say("diff needs --before, --after and --outputs"))
+	})
+})
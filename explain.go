@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gogen_pilots/data"
+	"gogen_pilots/utilities"
+)
+
+type explainOpts struct {
+	DOJFile     string `long:"input-doj" description:"The file containing criminal histories from CA DOJ"`
+	SubjectID   string `long:"subject-id" description:"The SUBJECT_ID of the subject to explain"`
+	CII         string `long:"cii" description:"The CII_NUMBER of the subject to explain, used instead of --subject-id"`
+	ComputeAt   string `long:"compute-at" description:"The date for which eligibility will be evaluated, ex: 2020-10-31"`
+	County      string `long:"county" default:"LOS ANGELES" description:"The county whose convictions are evaluated, ex: LOS ANGELES"`
+	InputFormat string `long:"input-format" default:"auto" description:"The format of the DOJ file: auto, csv or dat (fixed-width)"`
+	DOJSchema   string `long:"doj-schema" default:"v1" description:"The column order of a DOJ file without a header row, ex: v1"`
+}
+
+// The age and years conviction free thresholds the run command defaults to.
+const (
+	explainAge                 = 50
+	explainYearsConvictionFree = 10
+)
+
+const explainDateFormat = "2006-01-02"
+
+func (e explainOpts) Execute(args []string) error {
+	err := e.explain(os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if _, ok := err.(explainOptionError); ok {
+			os.Exit(utilities.INVALID_RUN_OPTION_ERROR)
+		}
+		os.Exit(utilities.FILE_PROCESSING_ERROR)
+	}
+	return nil
+}
+
+type explainOptionError struct {
+	error
+}
+
+// explain streams the DOJ file and writes the history and eligibility of each
+// subject that matches --subject-id or --cii. More than one SUBJECT_ID can
+// share a CII_NUMBER, so a CII can match several subjects.
+func (e explainOpts) explain(w io.Writer) error {
+	if e.DOJFile == "" || (e.SubjectID == "") == (e.CII == "") {
+		return explainOptionError{errors.New("missing required field: explain needs --input-doj and one of --subject-id or --cii")}
+	}
+
+	computeAtDate := time.Now()
+	if e.ComputeAt != "" {
+		computeAtOption, err := time.Parse("2006-01-02", e.ComputeAt)
+		if err != nil {
+			return explainOptionError{errors.New("invalid --compute-at date: Must be a valid date in the format YYYY-MM-DD")}
+		}
+		computeAtDate = computeAtOption
+	}
+
+	inputFormat, err := data.ParseDOJFileFormat(e.InputFormat)
+	if err != nil {
+		return explainOptionError{fmt.Errorf("invalid --input-format: %v", err)}
+	}
+
+	dojSchema, err := data.ParseDOJSchema(e.DOJSchema)
+	if err != nil {
+		return explainOptionError{fmt.Errorf("invalid --doj-schema: %v", err)}
+	}
+
+	county := strings.ToUpper(strings.TrimSpace(e.County))
+
+	dojReader, err := data.NewDOJReader(e.DOJFile, inputFormat, dojSchema, computeAtDate, data.EligibilityFlows["LOS ANGELES"])
+	if err != nil {
+		return err
+	}
+	defer dojReader.Close()
+
+	found := false
+	for {
+		dojInformation, err := dojReader.NextSubject()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !e.matches(dojInformation) {
+			continue
+		}
+		if found {
+			fmt.Fprintln(w)
+		}
+		found = true
+		for _, subject := range dojInformation.Subjects {
+			writeSubjectExplanation(w, dojInformation, subject, county)
+		}
+	}
+
+	if !found {
+		if e.SubjectID != "" {
+			return fmt.Errorf("no subject with SUBJECT_ID %s in %s", e.SubjectID, e.DOJFile)
+		}
+		return fmt.Errorf("no subject with CII_NUMBER %s in %s", e.CII, e.DOJFile)
+	}
+	return nil
+}
+
+func (e explainOpts) matches(dojInformation *data.DOJInformation) bool {
+	for _, row := range dojInformation.Rows {
+		if e.SubjectID != "" && row[data.SUBJECT_ID] == e.SubjectID {
+			return true
+		}
+		if e.CII != "" && (row[data.CII_NUMBER] == e.CII || row[data.REQ_CII_NUMBER] == e.CII) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeSubjectExplanation(w io.Writer, dojInformation *data.DOJInformation, subject *data.Subject, county string) {
+	fmt.Fprintf(w, "SUBJECT_ID %s: %s, born %s\n", subject.ID, subject.Name, formatExplainDateOfPrecision(subject.DOB, subject.DOBPrecision))
+	fmt.Fprintf(w, "Deceased: %s\n", yesOrNo(subject.IsDeceased))
+	fmt.Fprintf(w, "PC 290 registration: %s\n", yesOrNo(subject.PC290Registration))
+	fmt.Fprintf(w, "PC 290 code sections: %s\n", listOrNone(subject.PC290CodeSections()))
+	superstrikes := subject.SuperstrikeCodeSections()
+	sort.Strings(superstrikes)
+	fmt.Fprintf(w, "Superstrikes: %s\n", listOrNone(superstrikes))
+
+	fmt.Fprintln(w, "Case numbers:")
+	var cases []string
+	for caseKey := range subject.CaseNumbers {
+		cases = append(cases, caseKey)
+	}
+	sort.Strings(cases)
+	if len(cases) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, caseKey := range cases {
+		// Case keys are the SUBJECT_ID and the first six digits of CNT_ORDER.
+		caseOrder := caseKey[strings.LastIndex(caseKey, ":")+1:]
+		fmt.Fprintf(w, "  %s: %s\n", caseOrder, strings.Join(subject.CaseNumbers[caseKey], ", "))
+	}
+
+	flowNames := data.EligibilityFlowNames()
+	eligibilitiesByFlow := make(map[string]map[int]*data.EligibilityInfo)
+	for _, flowName := range flowNames {
+		eligibilitiesByFlow[flowName] = dojInformation.DetermineEligibility(county, data.EligibilityFlows[flowName], explainAge, explainYearsConvictionFree)
+	}
+
+	fmt.Fprintf(w, "Convictions (%d):\n", len(subject.Convictions))
+	for _, conviction := range subject.Convictions {
+		level := "misdemeanor"
+		if conviction.IsFelony {
+			level = "felony"
+		}
+		fmt.Fprintf(w, "  CNT_ORDER %s: %s, %s, %s, convicted %s, sentence ends %s\n",
+			conviction.CountOrder,
+			conviction.CodeSection,
+			level,
+			conviction.County,
+			formatExplainDateOfPrecision(conviction.DispositionDate, conviction.DispositionDatePrecision),
+			formatSentenceEndDate(conviction))
+		for _, flowName := range flowNames {
+			info, ok := eligibilitiesByFlow[flowName][conviction.Index]
+			if !ok && conviction.County != county {
+				fmt.Fprintf(w, "    %s: not evaluated (conviction is in %s, not %s)\n", flowName, conviction.County, county)
+				continue
+			}
+			if !ok {
+				fmt.Fprintf(w, "    %s: not evaluated (not a charge this flow evaluates)\n", flowName)
+				continue
+			}
+			fmt.Fprintf(w, "    %s: %s (%s)\n", flowName, info.EligibilityDetermination, info.EligibilityReason)
+		}
+	}
+}
+
+func formatExplainDate(date time.Time) string {
+	if date.IsZero() {
+		return "unknown"
+	}
+	return date.Format(explainDateFormat)
+}
+
+// formatExplainDateOfPrecision leaves out the parts of a DOJ date that DOJ
+// does not know, ex: "1960-05" or "1960".
+func formatExplainDateOfPrecision(date time.Time, precision data.DatePrecision) string {
+	switch precision {
+	case data.MonthPrecision:
+		return date.Format("2006-01")
+	case data.YearPrecision:
+		return date.Format("2006")
+	case data.NoDate:
+		return "unknown"
+	}
+	return formatExplainDate(date)
+}
+
+func formatSentenceEndDate(conviction *data.DOJRow) string {
+	if conviction.Sentence.IsLife() {
+		return "never (life term)"
+	}
+	return formatExplainDate(conviction.SentenceEndDate)
+}
+
+func yesOrNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+func listOrNone(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
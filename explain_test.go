@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	path "path/filepath"
+	"regexp"
+
+	. "gogen_pilots/test_fixtures"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("explain", func() {
+	var (
+		pathToGogen string
+		pathToDOJ   string
+	)
+
+	BeforeEach(func() {
+		var err error
+		pathToGogen, err = gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		pathToDOJ, _, err = ExtractFullCSVFixtures(path.Join("test_fixtures", "los_angeles.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	explain := func(args ...string) *gexec.Session {
+		command := exec.Command(pathToGogen, append([]string{"explain", fmt.Sprintf("--input-doj=%s", pathToDOJ), "--compute-at=2019-11-11"}, args...)...)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		return session
+	}
+
+	It("prints a subject's history and the eligibility of each conviction under every flow", func() {
+		session := explain("--subject-id=90675321")
+		Eventually(session).Should(gexec.Exit(0))
+
+		output := string(session.Out.Contents())
+		Expect(output).To(ContainSubstring("SUBJECT_ID 90675321: GROUCH,OSCAR THE, born 1960-05-14\n"))
+		Expect(output).To(ContainSubstring("PC 290 code sections: 266 PC, 314(1) PC\n"))
+		Expect(output).To(ContainSubstring("Superstrikes: none\n"))
+		Expect(output).To(ContainSubstring("  101001: 140196, 140197\n"))
+		Expect(output).To(ContainSubstring("Convictions (4):\n"))
+		Expect(output).To(ContainSubstring(
+			"  CNT_ORDER 101001019000: 11358 HS, misdemeanor, LOS ANGELES, convicted 2017-03-12, sentence ends 2017-09-12\n" +
+				"    DISMISS ALL PROP 64: Eligible for Dismissal (Dismiss all Prop 64 charges)\n" +
+				"    DISMISS ALL PROP 64 AND RELATED: Eligible for Dismissal (Dismiss all Prop 64 and related charges)\n" +
+				"    LOS ANGELES: To be reviewed by City Attorneys (Misdemeanor or Infraction)\n"))
+		Expect(output).To(ContainSubstring("    LOS ANGELES: not evaluated (not a charge this flow evaluates)\n"))
+		Expect(output).ToNot(ContainSubstring("SUBJECT_ID 23675654"))
+	})
+
+	It("prints every subject with a CII_NUMBER", func() {
+		session := explain("--cii=1008675309")
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Out).To(gbytes.Say("SUBJECT_ID 18675309: "))
+		Expect(session.Out).To(gbytes.Say("SUBJECT_ID 23675654: "))
+		Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("    LOS ANGELES: not evaluated (conviction is in YOLO, not LOS ANGELES)")))
+		Expect(session.Out).To(gbytes.Say("SUBJECT_ID 90675321: "))
+		Expect(session.Out).To(gbytes.Say("SUBJECT_ID 14575654: "))
+	})
+
+	It("fails when the subject is not in the file or the options are invalid", func() {
+		session := explain("--subject-id=12345")
+		Eventually(session).Should(gexec.Exit(2))
+		Expect(session.Err).To(gbytes.Say("no subject with SUBJECT_ID 12345 in "))
+
+		session = explain("--subject-id=90675321", "--cii=1008675309")
+		Eventually(session).Should(gexec.Exit(3))
+		Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("explain needs --input-doj and one of --subject-id or --cii")))
+	})
+})
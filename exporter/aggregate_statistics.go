@@ -0,0 +1,139 @@
+package exporter
+
+import (
+	"gogen_pilots/data"
+	"gogen_pilots/utilities"
+	"time"
+)
+
+// aggregateStatistics holds everything printed to the .out file and recorded
+// in the summary JSON. Statistics for separate sets of subjects can be added
+// together, so a file can be summarized one subject at a time.
+type aggregateStatistics struct {
+	totalRows                                            int
+	rejectedRows                                         int
+	rejectedRowsByReason                                 map[string]int
+	totalIndividuals                                     int
+	totalConvictions                                     int
+	totalConvictionsInCounty                             int
+	overallProp64ConvictionsByCodeSection                map[string]int
+	prop64ConvictionsInCountyByCodeSection               map[string]int
+	earliestProp64ConvictionInCounty                     time.Time
+	prop64ConvictionsInCountyByCodeSectionByEligibility  map[string]map[string]int
+	prop64ConvictionsInCountyByEligibilityByReason       map[string]map[string]int
+	overallRelatedConvictionsByCodeSection               map[string]int
+	relatedConvictionsInCountyByCodeSectionByEligibility map[string]map[string]int
+	individualsWithFelony                                int
+	individualsWithConviction                            int
+	individualsWithConvictionInLast7Years                int
+	individualsWithProp64ConvictionInCounty              int
+	prop64FelonyConvictionsInCounty                      int
+	prop64MisdemeanorConvictionsInCounty                 int
+	individualsWithSomeRelief                            int
+	currentEligibilityChoicesRelief                      reliefStatistics
+	dismissAllProp64Relief                               reliefStatistics
+	dismissAllProp64AndRelatedRelief                     reliefStatistics
+}
+
+type reliefStatistics struct {
+	noLongerHaveFelony                 int
+	noLongerHaveConviction             int
+	noLongerHaveConvictionInLast7Years int
+}
+
+func newAggregateStatistics(
+	dojInformation *data.DOJInformation,
+	county string,
+	countyEligibilities map[int]*data.EligibilityInfo,
+	dismissAllProp64Eligibilities map[int]*data.EligibilityInfo,
+	dismissAllProp64AndRelatedEligibilities map[int]*data.EligibilityInfo,
+) aggregateStatistics {
+	stats := aggregateStatistics{
+		totalRows:                                            dojInformation.TotalRows(),
+		totalIndividuals:                                     dojInformation.TotalIndividuals(),
+		totalConvictions:                                     dojInformation.TotalConvictions(),
+		totalConvictionsInCounty:                             dojInformation.TotalConvictionsInCounty(county),
+		overallProp64ConvictionsByCodeSection:                dojInformation.OverallProp64ConvictionsByCodeSection(),
+		prop64ConvictionsInCountyByCodeSection:               dojInformation.Prop64ConvictionsInThisCountyByCodeSection(county),
+		earliestProp64ConvictionInCounty:                     dojInformation.EarliestProp64ConvictionDateInThisCounty(county),
+		prop64ConvictionsInCountyByCodeSectionByEligibility:  dojInformation.Prop64ConvictionsInThisCountyByCodeSectionByEligibility(county, countyEligibilities),
+		prop64ConvictionsInCountyByEligibilityByReason:       dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, countyEligibilities),
+		overallRelatedConvictionsByCodeSection:               dojInformation.OverallRelatedConvictionsByCodeSection(),
+		relatedConvictionsInCountyByCodeSectionByEligibility: dojInformation.RelatedConvictionsInThisCountyByCodeSectionByEligibility(county, countyEligibilities),
+		individualsWithFelony:                                dojInformation.CountIndividualsWithFelony(),
+		individualsWithConviction:                            dojInformation.CountIndividualsWithConviction(),
+		individualsWithConvictionInLast7Years:                dojInformation.CountIndividualsWithConvictionInLast7Years(),
+		individualsWithProp64ConvictionInCounty:              dojInformation.CountIndividualsWithProp64ConvictionInCounty(county),
+		prop64FelonyConvictionsInCounty:                      dojInformation.CountProp64FelonyConvictionsInCounty(county),
+		prop64MisdemeanorConvictionsInCounty:                 dojInformation.CountProp64MisdemeanorConvictionsInCounty(county),
+		individualsWithSomeRelief:                            dojInformation.CountIndividualsWithSomeRelief(countyEligibilities),
+		currentEligibilityChoicesRelief:                      newReliefStatistics(dojInformation, countyEligibilities),
+		dismissAllProp64Relief:                               newReliefStatistics(dojInformation, dismissAllProp64Eligibilities),
+		dismissAllProp64AndRelatedRelief:                     newReliefStatistics(dojInformation, dismissAllProp64AndRelatedEligibilities),
+	}
+	stats.addRejectedRows(dojInformation.RejectedRows)
+	return stats
+}
+
+func newReliefStatistics(dojInformation *data.DOJInformation, eligibilities map[int]*data.EligibilityInfo) reliefStatistics {
+	return reliefStatistics{
+		noLongerHaveFelony:                 dojInformation.CountIndividualsNoLongerHaveFelony(eligibilities),
+		noLongerHaveConviction:             dojInformation.CountIndividualsNoLongerHaveConviction(eligibilities),
+		noLongerHaveConvictionInLast7Years: dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(eligibilities),
+	}
+}
+
+func (s *aggregateStatistics) add(other aggregateStatistics) {
+	s.totalRows += other.totalRows
+	s.rejectedRows += other.rejectedRows
+	s.rejectedRowsByReason = utilities.AddMaps(s.rejectedRowsByReason, other.rejectedRowsByReason)
+	s.totalIndividuals += other.totalIndividuals
+	s.totalConvictions += other.totalConvictions
+	s.totalConvictionsInCounty += other.totalConvictionsInCounty
+	s.overallProp64ConvictionsByCodeSection = utilities.AddMaps(s.overallProp64ConvictionsByCodeSection, other.overallProp64ConvictionsByCodeSection)
+	s.prop64ConvictionsInCountyByCodeSection = utilities.AddMaps(s.prop64ConvictionsInCountyByCodeSection, other.prop64ConvictionsInCountyByCodeSection)
+	s.earliestProp64ConvictionInCounty = findEarliest(s.earliestProp64ConvictionInCounty, other.earliestProp64ConvictionInCounty)
+	s.prop64ConvictionsInCountyByCodeSectionByEligibility = addNestedMaps(s.prop64ConvictionsInCountyByCodeSectionByEligibility, other.prop64ConvictionsInCountyByCodeSectionByEligibility)
+	s.prop64ConvictionsInCountyByEligibilityByReason = addNestedMaps(s.prop64ConvictionsInCountyByEligibilityByReason, other.prop64ConvictionsInCountyByEligibilityByReason)
+	s.overallRelatedConvictionsByCodeSection = utilities.AddMaps(s.overallRelatedConvictionsByCodeSection, other.overallRelatedConvictionsByCodeSection)
+	s.relatedConvictionsInCountyByCodeSectionByEligibility = addNestedMaps(s.relatedConvictionsInCountyByCodeSectionByEligibility, other.relatedConvictionsInCountyByCodeSectionByEligibility)
+	s.individualsWithFelony += other.individualsWithFelony
+	s.individualsWithConviction += other.individualsWithConviction
+	s.individualsWithConvictionInLast7Years += other.individualsWithConvictionInLast7Years
+	s.individualsWithProp64ConvictionInCounty += other.individualsWithProp64ConvictionInCounty
+	s.prop64FelonyConvictionsInCounty += other.prop64FelonyConvictionsInCounty
+	s.prop64MisdemeanorConvictionsInCounty += other.prop64MisdemeanorConvictionsInCounty
+	s.individualsWithSomeRelief += other.individualsWithSomeRelief
+	s.currentEligibilityChoicesRelief.add(other.currentEligibilityChoicesRelief)
+	s.dismissAllProp64Relief.add(other.dismissAllProp64Relief)
+	s.dismissAllProp64AndRelatedRelief.add(other.dismissAllProp64AndRelatedRelief)
+}
+
+func (s *aggregateStatistics) addRejectedRows(rejectedRows []data.RejectedRow) {
+	if s.rejectedRowsByReason == nil {
+		s.rejectedRowsByReason = make(map[string]int)
+	}
+	for _, rejectedRow := range rejectedRows {
+		s.rejectedRows++
+		for _, reason := range rejectedRow.Reasons {
+			s.rejectedRowsByReason[reason]++
+		}
+	}
+}
+
+func (r *reliefStatistics) add(other reliefStatistics) {
+	r.noLongerHaveFelony += other.noLongerHaveFelony
+	r.noLongerHaveConviction += other.noLongerHaveConviction
+	r.noLongerHaveConvictionInLast7Years += other.noLongerHaveConvictionInLast7Years
+}
+
+func addNestedMaps(map1 map[string]map[string]int, map2 map[string]map[string]int) map[string]map[string]int {
+	if map1 == nil {
+		map1 = make(map[string]map[string]int)
+	}
+
+	for key := range map2 {
+		map1[key] = utilities.AddMaps(map1[key], map2[key])
+	}
+	return map1
+}
@@ -27,21 +27,23 @@ type Summary struct {
 	County                                      string         `json:"county"`
 	IndividualDismissAge                        int            `json:"individualDismissAge"`
 	YearsConvictionFree                         int            `json:"yearsConvictionFree"`
+	StatuteCatalogVersion                       string         `json:"statuteCatalogVersion"`
 	EarliestConviction                          time.Time      `json:"earliestConviction"`
 	LineCount                                   int            `json:"lineCount"`
+	RejectedRowCount                            int            `json:"rejectedRowCount"`
+	RejectedRowCountByReason                    map[string]int `json:"rejectedRowCountByReason"`
 	ProcessingTimeInSeconds                     float64        `json:"processingTimeInSeconds"`
 	ReliefWithCurrentEligibilityChoices         map[string]int `json:"reliefWithCurrentEligibilityChoices"`
 	ReliefWithDismissAllProp64                  map[string]int `json:"reliefWithDismissAllProp64"`
 	Prop64ConvictionsCountInCountyByCodeSection map[string]int `json:"prop64ConvictionsCountInCountyByCodeSection"`
-
-	// TODO
-	SubjectsWithProp64ConvictionCountInCounty  int            `json:"subjectsWithProp64ConvictionCountInCounty"`
-	Prop64FelonyConvictionsCountInCounty       int            `json:"prop64FelonyConvictionsCountInCounty"`
-	Prop64MisdemeanorConvictionsCountInCounty  int            `json:"prop64MisdemeanorConvictionsCountInCounty"`
-	SubjectsWithSomeReliefCount                int            `json:"subjectsWithSomeReliefCount"`
-	ConvictionDismissalCountByCodeSection      map[string]int `json:"convictionDismissalCountByCodeSection"`
-	ConvictionReductionCountByCodeSection      map[string]int `json:"convictionReductionCountByCodeSection"`
-	ConvictionDismissalCountByAdditionalRelief map[string]int `json:"convictionDismissalCountByAdditionalRelief"`
+	SubjectsWithProp64ConvictionCountInCounty   int            `json:"subjectsWithProp64ConvictionCountInCounty"`
+	Prop64FelonyConvictionsCountInCounty        int            `json:"prop64FelonyConvictionsCountInCounty"`
+	Prop64MisdemeanorConvictionsCountInCounty   int            `json:"prop64MisdemeanorConvictionsCountInCounty"`
+	SubjectsWithSomeReliefCount                 int            `json:"subjectsWithSomeReliefCount"`
+	ConvictionDismissalCountByCodeSection       map[string]int `json:"convictionDismissalCountByCodeSection"`
+	ConvictionReductionCountByCodeSection       map[string]int `json:"convictionReductionCountByCodeSection"`
+	ConvictionDismissalCountByAdditionalRelief  map[string]int `json:"convictionDismissalCountByAdditionalRelief"`
+	ConvictionAlreadyRelievedCountByReason      map[string]int `json:"convictionAlreadyRelievedCountByReason"`
 }
 
 func NewDataExporter(
@@ -68,6 +70,16 @@ func NewDataExporter(
 }
 
 func (d *DataExporter) Export(county string, startTime time.Time) Summary {
+	d.writeRows()
+
+	d.outputDOJWriter.Flush()
+	d.outputCondensedDOJWriter.Flush()
+	d.outputProp64ConvictionsDOJWriter.Flush()
+	d.PrintAggregateStatistics(county, startTime)
+	return d.NewFileSummary(county)
+}
+
+func (d *DataExporter) writeRows() {
 	for i, row := range d.dojInformation.Rows {
 		possibleOtherP64Charges := PossibleP64ChargeOnlyInComment(row[data.OFFENSE_DESCR], row[data.COMMENT_TEXT])
 		d.outputDOJWriter.WriteEntryWithEligibilityInfo(row, d.normalFlowEligibilities[i], possibleOtherP64Charges)
@@ -76,12 +88,16 @@ func (d *DataExporter) Export(county string, startTime time.Time) Summary {
 			d.outputProp64ConvictionsDOJWriter.WriteEntryWithEligibilityInfo(row, d.normalFlowEligibilities[i], possibleOtherP64Charges)
 		}
 	}
+}
 
-	d.outputDOJWriter.Flush()
-	d.outputCondensedDOJWriter.Flush()
-	d.outputProp64ConvictionsDOJWriter.Flush()
-	d.PrintAggregateStatistics(county, startTime)
-	return d.NewFileSummary(county)
+func (d *DataExporter) aggregateStatistics(county string) aggregateStatistics {
+	return newAggregateStatistics(
+		d.dojInformation,
+		county,
+		d.normalFlowEligibilities,
+		d.dismissAllProp64Eligibilities,
+		d.dismissAllProp64AndRelatedEligibilities,
+	)
 }
 
 func PossibleP64ChargeOnlyInComment(offenseDescription, commentText string) string {
@@ -116,62 +132,67 @@ func PossibleP64ChargeOnlyInComment(offenseDescription, commentText string) stri
 }
 
 func (d *DataExporter) PrintAggregateStatistics(county string, startTime time.Time) {
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- Overall summary of DOJ file --------------------\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "Found %d Total rows in DOJ file\n", d.dojInformation.TotalRows())
-	fmt.Fprintf(d.aggregateStatsWriter, "Based on your office’s eligibility choices, this application processed the data in %v seconds\n", time.Since(startTime).Seconds())
-	fmt.Fprintf(d.aggregateStatsWriter, "Found %d Total individuals in DOJ file\n", d.dojInformation.TotalIndividuals())
-	fmt.Fprintf(d.aggregateStatsWriter, "Found %d Total convictions in DOJ file\n", d.dojInformation.TotalConvictions())
-	fmt.Fprintf(d.aggregateStatsWriter, "Found %d convictions in this county\n", d.dojInformation.TotalConvictionsInCounty(county))
-
-	fmt.Fprintf(d.aggregateStatsWriter, "\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- Prop64 Convictions Overall--------------------")
-	d.printSummaryByCodeSection("total", d.dojInformation.OverallProp64ConvictionsByCodeSection())
-	fmt.Fprintf(d.aggregateStatsWriter, "\n")
-
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- Prop64 Convictions In This County --------------------")
-	d.printSummaryByCodeSection("in this county", d.dojInformation.Prop64ConvictionsInThisCountyByCodeSection(county))
-	fmt.Fprintf(d.aggregateStatsWriter, "Date of earliest Prop 64 conviction: %s", d.dojInformation.EarliestProp64ConvictionDateInThisCounty(county).Format("January 2006"))
-	d.printSummaryByCodeSectionByEligibility(d.dojInformation.Prop64ConvictionsInThisCountyByCodeSectionByEligibility(county, d.normalFlowEligibilities))
-
-	fmt.Fprintf(d.aggregateStatsWriter, "\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- Eligibility Reasons --------------------\n")
-	d.printSummaryByEligibilityByReason(d.dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, d.normalFlowEligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "\n\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- Prop64 Related Convictions In This County --------------------")
-	d.printSummaryByCodeSection("in this county", d.dojInformation.OverallRelatedConvictionsByCodeSection())
-	fmt.Fprintf(d.aggregateStatsWriter, "\n")
-	d.printSummaryByCodeSectionByEligibility(d.dojInformation.RelatedConvictionsInThisCountyByCodeSectionByEligibility(county, d.normalFlowEligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "\n")
-
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- Impact to individuals --------------------\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals currently have a felony on their record\n", d.dojInformation.CountIndividualsWithFelony())
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals currently have convictions on their record\n", d.dojInformation.CountIndividualsWithConviction())
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals currently have convictions on their record in the last 7 years\n", d.dojInformation.CountIndividualsWithConvictionInLast7Years())
-	fmt.Fprintf(d.aggregateStatsWriter, "\n")
-
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- Eligibility is run as specified for Prop 64 and Related Charges --------------------\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had a felony will no longer have a felony on their record\n", d.dojInformation.CountIndividualsNoLongerHaveFelony(d.normalFlowEligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had convictions will no longer have any convictions on their record\n", d.dojInformation.CountIndividualsNoLongerHaveConviction(d.normalFlowEligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had convictions in the last 7 years will no longer have any convictions on their record in the last 7 years\n", d.dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(d.normalFlowEligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- If ALL Prop 64 convictions are dismissed and sealed --------------------\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had a felony will no longer have a felony on their record\n", d.dojInformation.CountIndividualsNoLongerHaveFelony(d.dismissAllProp64Eligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had convictions will no longer have any convictions on their record\n", d.dojInformation.CountIndividualsNoLongerHaveConviction(d.dismissAllProp64Eligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had convictions in the last 7 years will no longer have any convictions on their record in the last 7 years\n", d.dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(d.dismissAllProp64Eligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "----------- If all Prop 64 AND related convictions are dismissed and sealed --------------------\n")
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had a felony will no longer have a felony on their record\n", d.dojInformation.CountIndividualsNoLongerHaveFelony(d.dismissAllProp64AndRelatedEligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had convictions will no longer have any convictions on their record\n", d.dojInformation.CountIndividualsNoLongerHaveConviction(d.dismissAllProp64AndRelatedEligibilities))
-	fmt.Fprintf(d.aggregateStatsWriter, "%d individuals who had convictions in the last 7 years will no longer have any convictions on their record in the last 7 years\n", d.dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(d.dismissAllProp64AndRelatedEligibilities))
+	printAggregateStatistics(d.aggregateStatsWriter, d.aggregateStatistics(county), startTime)
+}
+
+func printAggregateStatistics(w io.Writer, stats aggregateStatistics, startTime time.Time) {
+	fmt.Fprintf(w, "----------- Overall summary of DOJ file --------------------\n")
+	fmt.Fprintf(w, "Found %d Total rows in DOJ file\n", stats.totalRows)
+	fmt.Fprintf(w, "Rejected %d rows that failed validation\n", stats.rejectedRows)
+	fmt.Fprintf(w, "Based on your office’s eligibility choices, this application processed the data in %v seconds\n", time.Since(startTime).Seconds())
+	fmt.Fprintf(w, "Found %d Total individuals in DOJ file\n", stats.totalIndividuals)
+	fmt.Fprintf(w, "Found %d Total convictions in DOJ file\n", stats.totalConvictions)
+	fmt.Fprintf(w, "Found %d convictions in this county\n", stats.totalConvictionsInCounty)
+
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "----------- Prop64 Convictions Overall--------------------")
+	printSummaryByCodeSection(w, "total", stats.overallProp64ConvictionsByCodeSection)
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "----------- Prop64 Convictions In This County --------------------")
+	printSummaryByCodeSection(w, "in this county", stats.prop64ConvictionsInCountyByCodeSection)
+	fmt.Fprintf(w, "Date of earliest Prop 64 conviction: %s", stats.earliestProp64ConvictionInCounty.Format("January 2006"))
+	printSummaryByCodeSectionByEligibility(w, stats.prop64ConvictionsInCountyByCodeSectionByEligibility)
+
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "----------- Eligibility Reasons --------------------\n")
+	printSummaryByEligibilityByReason(w, stats.prop64ConvictionsInCountyByEligibilityByReason)
+	fmt.Fprintf(w, "\n\n")
+	fmt.Fprintf(w, "----------- Prop64 Related Convictions In This County --------------------")
+	printSummaryByCodeSection(w, "in this county", stats.overallRelatedConvictionsByCodeSection)
+	fmt.Fprintf(w, "\n")
+	printSummaryByCodeSectionByEligibility(w, stats.relatedConvictionsInCountyByCodeSectionByEligibility)
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "----------- Impact to individuals --------------------\n")
+	fmt.Fprintf(w, "%d individuals currently have a felony on their record\n", stats.individualsWithFelony)
+	fmt.Fprintf(w, "%d individuals currently have convictions on their record\n", stats.individualsWithConviction)
+	fmt.Fprintf(w, "%d individuals currently have convictions on their record in the last 7 years\n", stats.individualsWithConvictionInLast7Years)
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "----------- Eligibility is run as specified for Prop 64 and Related Charges --------------------\n")
+	fmt.Fprintf(w, "%d individuals who had a felony will no longer have a felony on their record\n", stats.currentEligibilityChoicesRelief.noLongerHaveFelony)
+	fmt.Fprintf(w, "%d individuals who had convictions will no longer have any convictions on their record\n", stats.currentEligibilityChoicesRelief.noLongerHaveConviction)
+	fmt.Fprintf(w, "%d individuals who had convictions in the last 7 years will no longer have any convictions on their record in the last 7 years\n", stats.currentEligibilityChoicesRelief.noLongerHaveConvictionInLast7Years)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "----------- If ALL Prop 64 convictions are dismissed and sealed --------------------\n")
+	fmt.Fprintf(w, "%d individuals who had a felony will no longer have a felony on their record\n", stats.dismissAllProp64Relief.noLongerHaveFelony)
+	fmt.Fprintf(w, "%d individuals who had convictions will no longer have any convictions on their record\n", stats.dismissAllProp64Relief.noLongerHaveConviction)
+	fmt.Fprintf(w, "%d individuals who had convictions in the last 7 years will no longer have any convictions on their record in the last 7 years\n", stats.dismissAllProp64Relief.noLongerHaveConvictionInLast7Years)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "----------- If all Prop 64 AND related convictions are dismissed and sealed --------------------\n")
+	fmt.Fprintf(w, "%d individuals who had a felony will no longer have a felony on their record\n", stats.dismissAllProp64AndRelatedRelief.noLongerHaveFelony)
+	fmt.Fprintf(w, "%d individuals who had convictions will no longer have any convictions on their record\n", stats.dismissAllProp64AndRelatedRelief.noLongerHaveConviction)
+	fmt.Fprintf(w, "%d individuals who had convictions in the last 7 years will no longer have any convictions on their record in the last 7 years\n", stats.dismissAllProp64AndRelatedRelief.noLongerHaveConvictionInLast7Years)
 }
 
-func (d *DataExporter) printSummaryByCodeSection(description string, resultsByCodeSection map[string]int) {
-	fmt.Fprintf(d.aggregateStatsWriter, "\nFound %d convictions %s\n", sumValues(resultsByCodeSection), description)
+func printSummaryByCodeSection(w io.Writer, description string, resultsByCodeSection map[string]int) {
+	fmt.Fprintf(w, "\nFound %d convictions %s\n", sumValues(resultsByCodeSection), description)
 	formatString := fmt.Sprintf("Found %%d %%s convictions %s\n", description)
-	d.printMap(formatString, resultsByCodeSection)
+	printMap(w, formatString, resultsByCodeSection)
 }
 
-func (d *DataExporter) printSummaryByCodeSectionByEligibility(resultsByCodeSectionByEligibility map[string]map[string]int) {
+func printSummaryByCodeSectionByEligibility(w io.Writer, resultsByCodeSectionByEligibility map[string]map[string]int) {
 	codeSections := make([]string, 0, len(resultsByCodeSectionByEligibility))
 	for key := range resultsByCodeSectionByEligibility {
 		codeSections = append(codeSections, key)
@@ -179,7 +200,7 @@ func (d *DataExporter) printSummaryByCodeSectionByEligibility(resultsByCodeSecti
 	sort.Strings(codeSections)
 
 	for _, codeSection := range codeSections {
-		fmt.Fprintf(d.aggregateStatsWriter, "\n%v", codeSection)
+		fmt.Fprintf(w, "\n%v", codeSection)
 
 		total := 0
 		eligibilityMap := resultsByCodeSectionByEligibility[codeSection]
@@ -192,13 +213,13 @@ func (d *DataExporter) printSummaryByCodeSectionByEligibility(resultsByCodeSecti
 		for _, eligibility := range eligibilities {
 
 			total += eligibilityMap[eligibility]
-			fmt.Fprintf(d.aggregateStatsWriter, "\nFound %v %v convictions that are %v", eligibilityMap[eligibility], eligibility, codeSection)
+			fmt.Fprintf(w, "\nFound %v %v convictions that are %v", eligibilityMap[eligibility], eligibility, codeSection)
 		}
-		fmt.Fprintf(d.aggregateStatsWriter, "\nFound %v convictions total that are %v\n", total, codeSection)
+		fmt.Fprintf(w, "\nFound %v convictions total that are %v\n", total, codeSection)
 	}
 }
 
-func (d *DataExporter) printSummaryByEligibilityByReason(resultsByEligibilityByReason map[string]map[string]int) {
+func printSummaryByEligibilityByReason(w io.Writer, resultsByEligibilityByReason map[string]map[string]int) {
 	determinations := make([]string, 0, len(resultsByEligibilityByReason))
 	for key := range resultsByEligibilityByReason {
 		determinations = append(determinations, key)
@@ -206,7 +227,7 @@ func (d *DataExporter) printSummaryByEligibilityByReason(resultsByEligibilityByR
 	sort.Strings(determinations)
 
 	for _, determination := range determinations {
-		fmt.Fprintf(d.aggregateStatsWriter, "\n%v", determination)
+		fmt.Fprintf(w, "\n%v", determination)
 
 		total := 0
 		reasonMap := resultsByEligibilityByReason[determination]
@@ -218,62 +239,82 @@ func (d *DataExporter) printSummaryByEligibilityByReason(resultsByEligibilityByR
 
 		for _, reason := range reasons {
 			total += reasonMap[reason]
-			fmt.Fprintf(d.aggregateStatsWriter, "\nFound %v convictions with eligibility reason %v", reasonMap[reason], reason)
+			fmt.Fprintf(w, "\nFound %v convictions with eligibility reason %v", reasonMap[reason], reason)
 		}
-		fmt.Fprintf(d.aggregateStatsWriter, "\n")
+		fmt.Fprintf(w, "\n")
 	}
 }
 
-func (d *DataExporter) printMap(formatString string, values map[string]int) {
+func printMap(w io.Writer, formatString string, values map[string]int) {
 	keys := getSortedKeys(values)
 
 	for _, key := range keys {
-		fmt.Fprintf(d.aggregateStatsWriter, formatString, values[key], key)
+		fmt.Fprintf(w, formatString, values[key], key)
 	}
 }
 
 func (d *DataExporter) AccumulateSummaryData(runSummary Summary, fileSummary Summary) Summary {
+	return AccumulateSummaryData(runSummary, fileSummary)
+}
+
+func AccumulateSummaryData(runSummary Summary, fileSummary Summary) Summary {
 	return Summary{
 		County:                              runSummary.County,
 		IndividualDismissAge:                runSummary.IndividualDismissAge,
 		YearsConvictionFree:                 runSummary.YearsConvictionFree,
+		StatuteCatalogVersion:               runSummary.StatuteCatalogVersion,
 		LineCount:                           runSummary.LineCount + fileSummary.LineCount,
+		RejectedRowCount:                    runSummary.RejectedRowCount + fileSummary.RejectedRowCount,
+		RejectedRowCountByReason:            utilities.AddMaps(runSummary.RejectedRowCountByReason, fileSummary.RejectedRowCountByReason),
 		EarliestConviction:                  findEarliest(runSummary.EarliestConviction, fileSummary.EarliestConviction),
 		ReliefWithCurrentEligibilityChoices: utilities.AddMaps(runSummary.ReliefWithCurrentEligibilityChoices, fileSummary.ReliefWithCurrentEligibilityChoices),
 		ReliefWithDismissAllProp64:          utilities.AddMaps(runSummary.ReliefWithDismissAllProp64, fileSummary.ReliefWithDismissAllProp64),
 		Prop64ConvictionsCountInCountyByCodeSection: utilities.AddMaps(runSummary.Prop64ConvictionsCountInCountyByCodeSection, fileSummary.Prop64ConvictionsCountInCountyByCodeSection),
-		Prop64FelonyConvictionsCountInCounty:        0,
-		Prop64MisdemeanorConvictionsCountInCounty:   0,
-		SubjectsWithSomeReliefCount:                 0,
+		SubjectsWithProp64ConvictionCountInCounty:   runSummary.SubjectsWithProp64ConvictionCountInCounty + fileSummary.SubjectsWithProp64ConvictionCountInCounty,
+		Prop64FelonyConvictionsCountInCounty:        runSummary.Prop64FelonyConvictionsCountInCounty + fileSummary.Prop64FelonyConvictionsCountInCounty,
+		Prop64MisdemeanorConvictionsCountInCounty:   runSummary.Prop64MisdemeanorConvictionsCountInCounty + fileSummary.Prop64MisdemeanorConvictionsCountInCounty,
+		SubjectsWithSomeReliefCount:                 runSummary.SubjectsWithSomeReliefCount + fileSummary.SubjectsWithSomeReliefCount,
 		ConvictionDismissalCountByAdditionalRelief:  utilities.AddMaps(runSummary.ConvictionDismissalCountByAdditionalRelief, fileSummary.ConvictionDismissalCountByAdditionalRelief),
 		ConvictionDismissalCountByCodeSection:       utilities.AddMaps(runSummary.ConvictionDismissalCountByCodeSection, fileSummary.ConvictionDismissalCountByCodeSection),
 		ConvictionReductionCountByCodeSection:       utilities.AddMaps(runSummary.ConvictionReductionCountByCodeSection, fileSummary.ConvictionReductionCountByCodeSection),
+		ConvictionAlreadyRelievedCountByReason:      utilities.AddMaps(runSummary.ConvictionAlreadyRelievedCountByReason, fileSummary.ConvictionAlreadyRelievedCountByReason),
 	}
 }
 
 func (d *DataExporter) NewFileSummary(county string) Summary {
+	return newFileSummary(d.aggregateStatistics(county))
+}
+
+func newFileSummary(stats aggregateStatistics) Summary {
 	return Summary{
-		LineCount:          d.dojInformation.TotalRows(),
-		EarliestConviction: d.dojInformation.EarliestProp64ConvictionDateInThisCounty(county),
+		LineCount:                stats.totalRows,
+		RejectedRowCount:         stats.rejectedRows,
+		RejectedRowCountByReason: stats.rejectedRowsByReason,
+		EarliestConviction:       stats.earliestProp64ConvictionInCounty,
 		ReliefWithCurrentEligibilityChoices: map[string]int{
-			"CountSubjectsNoFelony":               d.dojInformation.CountIndividualsNoLongerHaveFelony(d.normalFlowEligibilities),
-			"CountSubjectsNoConvictionLast7Years": d.dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(d.normalFlowEligibilities),
-			"CountSubjectsNoConviction":           d.dojInformation.CountIndividualsNoLongerHaveConviction(d.normalFlowEligibilities),
+			"CountSubjectsNoFelony":               stats.currentEligibilityChoicesRelief.noLongerHaveFelony,
+			"CountSubjectsNoConvictionLast7Years": stats.currentEligibilityChoicesRelief.noLongerHaveConvictionInLast7Years,
+			"CountSubjectsNoConviction":           stats.currentEligibilityChoicesRelief.noLongerHaveConviction,
 		},
 		ReliefWithDismissAllProp64: map[string]int{
-			"CountSubjectsNoFelony":               d.dojInformation.CountIndividualsNoLongerHaveFelony(d.dismissAllProp64Eligibilities),
-			"CountSubjectsNoConvictionLast7Years": d.dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(d.dismissAllProp64Eligibilities),
-			"CountSubjectsNoConviction":           d.dojInformation.CountIndividualsNoLongerHaveConviction(d.dismissAllProp64Eligibilities),
+			"CountSubjectsNoFelony":               stats.dismissAllProp64Relief.noLongerHaveFelony,
+			"CountSubjectsNoConvictionLast7Years": stats.dismissAllProp64Relief.noLongerHaveConvictionInLast7Years,
+			"CountSubjectsNoConviction":           stats.dismissAllProp64Relief.noLongerHaveConviction,
 		},
-		Prop64ConvictionsCountInCountyByCodeSection: d.dojInformation.Prop64ConvictionsInThisCountyByCodeSection(county),
-		ConvictionDismissalCountByCodeSection:       d.getDismissalsByCodeSection(county),
-		ConvictionReductionCountByCodeSection:       d.getReductionsByCodeSection(county),
-		ConvictionDismissalCountByAdditionalRelief:  d.getDismissalsByAdditionalRelief(county),
+		Prop64ConvictionsCountInCountyByCodeSection: stats.prop64ConvictionsInCountyByCodeSection,
+		SubjectsWithProp64ConvictionCountInCounty:   stats.individualsWithProp64ConvictionInCounty,
+		Prop64FelonyConvictionsCountInCounty:        stats.prop64FelonyConvictionsInCounty,
+		Prop64MisdemeanorConvictionsCountInCounty:   stats.prop64MisdemeanorConvictionsInCounty,
+		SubjectsWithSomeReliefCount:                 stats.individualsWithSomeRelief,
+		ConvictionDismissalCountByCodeSection:       getDismissalsByCodeSection(stats.prop64ConvictionsInCountyByEligibilityByReason),
+		ConvictionReductionCountByCodeSection:       getReductionsByCodeSection(stats.prop64ConvictionsInCountyByEligibilityByReason),
+		ConvictionDismissalCountByAdditionalRelief:  getDismissalsByAdditionalRelief(stats.prop64ConvictionsInCountyByEligibilityByReason),
+		ConvictionAlreadyRelievedCountByReason:      getAlreadyRelievedByReason(stats.prop64ConvictionsInCountyByEligibilityByReason),
 	}
 }
 
 func findEarliest(time1 time.Time, time2 time.Time) time.Time {
-	if !time1.IsZero() && time1.Before(time2) {
+	if time2.IsZero() || (!time1.IsZero() && time1.Before(time2)) {
 		return time1
 	}
 	return time2
@@ -296,10 +337,10 @@ func sumValues(mapOfInts map[string]int) int {
 	return total
 }
 
-func (d *DataExporter) getDismissalsByCodeSection(county string) map[string]int {
+func getDismissalsByCodeSection(convictionsByEligibilityByReason map[string]map[string]int) map[string]int {
 	var codeSection string
 	result := make(map[string]int)
-	for key, value := range d.dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, d.normalFlowEligibilities)["Eligible for Dismissal"] {
+	for key, value := range convictionsByEligibilityByReason["Eligible for Dismissal"] {
 		_, err := fmt.Sscanf(key, "Dismiss all HS %s convictions", &codeSection)
 		if err == nil {
 			if strings.HasSuffix(key, "(when no sub-section is specified)") {
@@ -312,10 +353,10 @@ func (d *DataExporter) getDismissalsByCodeSection(county string) map[string]int
 	return result
 }
 
-func (d *DataExporter) getReductionsByCodeSection(county string) map[string]int {
+func getReductionsByCodeSection(convictionsByEligibilityByReason map[string]map[string]int) map[string]int {
 	var codeSection string
 	result := make(map[string]int)
-	for key, value := range d.dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, d.normalFlowEligibilities)["Eligible for Reduction"] {
+	for key, value := range convictionsByEligibilityByReason["Eligible for Reduction"] {
 		_, err := fmt.Sscanf(key, "Reduce all HS %s convictions", &codeSection)
 		if err == nil {
 			result[codeSection] = value
@@ -324,12 +365,20 @@ func (d *DataExporter) getReductionsByCodeSection(county string) map[string]int
 	return result
 }
 
-func (d *DataExporter) getDismissalsByAdditionalRelief(county string) map[string]int {
+func getDismissalsByAdditionalRelief(convictionsByEligibilityByReason map[string]map[string]int) map[string]int {
 	result := make(map[string]int)
-	for key, value := range d.dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, d.normalFlowEligibilities)["Eligible for Dismissal"] {
+	for key, value := range convictionsByEligibilityByReason["Eligible for Dismissal"] {
 		if !strings.HasPrefix(key, "Dismiss all HS") {
 			result[key] = value
 		}
 	}
 	return result
 }
+
+func getAlreadyRelievedByReason(convictionsByEligibilityByReason map[string]map[string]int) map[string]int {
+	result := make(map[string]int)
+	for key, value := range convictionsByEligibilityByReason["Already Relieved"] {
+		result[key] = value
+	}
+	return result
+}
@@ -207,11 +207,12 @@ var _ = Describe("DataExporter", func() {
 	Describe("AccumulateSummaryData", func() {
 		It("adds new stats to stats already accumulated", func() {
 			existingStats := Summary{
-				County:               "SANTA CARLA",
-				IndividualDismissAge: 50,
-				YearsConvictionFree:  10,
-				LineCount:            21,
-				EarliestConviction:   time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC),
+				County:                "SANTA CARLA",
+				IndividualDismissAge:  50,
+				YearsConvictionFree:   10,
+				StatuteCatalogVersion: "2019-11-01",
+				LineCount:             21,
+				EarliestConviction:    time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC),
 				ReliefWithCurrentEligibilityChoices: map[string]int{
 					"CountSubjectsNoFelony":               2,
 					"CountSubjectsNoConvictionLast7Years": 3,
@@ -227,6 +228,13 @@ var _ = Describe("DataExporter", func() {
 					"11358": 6,
 					"11359": 7,
 				},
+				SubjectsWithProp64ConvictionCountInCounty: 8,
+				Prop64FelonyConvictionsCountInCounty:      12,
+				Prop64MisdemeanorConvictionsCountInCounty: 5,
+				SubjectsWithSomeReliefCount:               6,
+				ConvictionAlreadyRelievedCountByReason: map[string]int{
+					"Dismissed under PC 1203.4": 2,
+				},
 			}
 
 			newStats := Summary{
@@ -247,16 +255,25 @@ var _ = Describe("DataExporter", func() {
 					"11358": 7,
 					"11359": 8,
 				},
+				SubjectsWithProp64ConvictionCountInCounty: 9,
+				Prop64FelonyConvictionsCountInCounty:      10,
+				Prop64MisdemeanorConvictionsCountInCounty: 2,
+				SubjectsWithSomeReliefCount:               4,
+				ConvictionAlreadyRelievedCountByReason: map[string]int{
+					"Dismissed under PC 1203.4": 1,
+					"Reduced under PC 17(b)":    3,
+				},
 			}
 
 			cumulativeStats := dataExporter.AccumulateSummaryData(existingStats, newStats)
 
 			Expect(cumulativeStats).To(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
-				"County":               Equal("SANTA CARLA"),
-				"IndividualDismissAge": Equal(50),
-				"YearsConvictionFree":  Equal(10),
-				"LineCount":            Equal(46),
-				"EarliestConviction":   Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
+				"County":                Equal("SANTA CARLA"),
+				"IndividualDismissAge":  Equal(50),
+				"YearsConvictionFree":   Equal(10),
+				"StatuteCatalogVersion": Equal("2019-11-01"),
+				"LineCount":             Equal(46),
+				"EarliestConviction":    Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
 				"ReliefWithCurrentEligibilityChoices": gstruct.MatchAllKeys(gstruct.Keys{
 					"CountSubjectsNoFelony":               Equal(3),
 					"CountSubjectsNoConvictionLast7Years": Equal(8),
@@ -272,6 +289,14 @@ var _ = Describe("DataExporter", func() {
 					"11358": Equal(13),
 					"11359": Equal(15),
 				}),
+				"SubjectsWithProp64ConvictionCountInCounty": Equal(17),
+				"Prop64FelonyConvictionsCountInCounty":      Equal(22),
+				"Prop64MisdemeanorConvictionsCountInCounty": Equal(7),
+				"SubjectsWithSomeReliefCount":               Equal(10),
+				"ConvictionAlreadyRelievedCountByReason": gstruct.MatchAllKeys(gstruct.Keys{
+					"Dismissed under PC 1203.4": Equal(3),
+					"Reduced under PC 17(b)":    Equal(3),
+				}),
 			}))
 		})
 
@@ -297,6 +322,10 @@ var _ = Describe("DataExporter", func() {
 					"11358": 7,
 					"11359": 8,
 				},
+				SubjectsWithProp64ConvictionCountInCounty: 9,
+				Prop64FelonyConvictionsCountInCounty:      10,
+				Prop64MisdemeanorConvictionsCountInCounty: 2,
+				SubjectsWithSomeReliefCount:               4,
 			}
 
 			cumulativeStats := dataExporter.AccumulateSummaryData(existingStats, newStats)
@@ -332,7 +361,10 @@ var _ = Describe("PossibleP64ChargeOnlyInComment", func() {
 
 })
 
+// expectCSVsToBeEqual compares output against the spreadsheet fixtures, which
+// do not include the eligibility trace column.
 func expectCSVsToBeEqual(expectedCSV [][]string, actualCSV [][]string) {
+	actualCSV = withoutTraceColumn(actualCSV)
 	for i, row := range actualCSV {
 		for j, item := range row {
 			Expect(item).To(Equal(expectedCSV[i][j]), fmt.Sprintf("Failed on row %d, col %d\n", i+2, j+1))
@@ -341,6 +373,14 @@ func expectCSVsToBeEqual(expectedCSV [][]string, actualCSV [][]string) {
 	Expect(actualCSV).To(Equal(expectedCSV))
 }
 
+func withoutTraceColumn(csv [][]string) [][]string {
+	rows := make([][]string, len(csv))
+	for i, row := range csv {
+		rows[i] = row[:len(row)-1]
+	}
+	return rows
+}
+
 func createFlow() data.EligibilityFlow {
 	flow, _ := data.EligibilityFlows["LOS ANGELES"]
 	return flow
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"gogen_pilots/data"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -26,105 +28,11 @@ var EligiblityHeaders = []string{
 	"Deceased",
 	"Eligibility Determination",
 	"Eligibility Reason",
+	"Eligibility Trace",
 }
 
-var DojFullHeaders = []string{
-	"RECORD_ID",
-	"SUBJECT_STATUS",
-	"SUBJECT_ID",
-	"REQ_SEG_SEP",
-	"REQ_CII_NUMBER",
-	"REQ_NAME",
-	"REQ_GENDER",
-	"REQ_DOB",
-	"REQ_CDL",
-	"REQ_SSN",
-	"PII_SEG_SEP",
-	"CII_NUMBER",
-	"PRI_NAME",
-	"GENDER",
-	"PRI_DOB",
-	"PRI_SSN",
-	"PRI_CDL",
-	"PRI_IDN",
-	"PRI_INN",
-	"FBI_NUMBER",
-	"PDR_SEG_SEP",
-	"RACE_CODE",
-	"RACE_DESCR",
-	"EYE_COLOR_CODE",
-	"EYE_COLOR_DESCR",
-	"HAIR_COLOR_CODE",
-	"HAIR_COLOR_DESCR",
-	"HEIGHT",
-	"WEIGHT",
-	"SINGLE_SOURCE",
-	"MULTI_SOURCE",
-	"POB_CODE",
-	"POB_NAME",
-	"POB_TYPE",
-	"CITIZENSHIP_LIST",
-	"CYC_SEG_SEP",
-	"CYC_ORDER",
-	"CYC_DATE",
-	"STP_SEG_SEP",
-	"STP_ORDER",
-	"STP_EVENT_DATE",
-	"STP_TYPE_CODE",
-	"STP_TYPE_DESCR",
-	"STP_ORI_TYPE",
-	"STP_ORI_TYPE_DESCR",
-	"STP_ORI_CODE",
-	"STP_ORI_DESCR",
-	"STP_ORI_CNTY_CODE",
-	"STP_ORI_CNTY_NAME",
-	"CNT_SEG_SEP",
-	"CNT_ORDER",
-	"DISP_DATE",
-	"OFN",
-	"OFFENSE_CODE",
-	"OFFENSE_DESCR",
-	"OFFENSE_TOC",
-	"OFFENSE_QUAL_LST",
-	"DISP_OFFENSE_CODE",
-	"DISP_OFFENSE_DESCR",
-	"DISP_OFFENSE_TOC",
-	"DISP_OFFENSE_QUAL_LST",
-	"CONV_OFFENSE_ORDER",
-	"CONV_OFFENSE_CODE",
-	"CONV_OFFENSE_DESCR",
-	"CONV_OFFENSE_TOC",
-	"CONV_OFFENSE_QUAL_LST",
-	"FE_NUM_ORDER",
-	"FE_NUM_ARR_AGY",
-	"FE_NUM_BNCH_WARR",
-	"FE_NUM_CITE",
-	"FE_NUM_DOCKET",
-	"FE_NUM_INCIDENT",
-	"FE_NUM_BOOKING",
-	"FE_NUM_NUMBER",
-	"FE_NUM_REMAND",
-	"FE_NUM_OOS_INN",
-	"FE_NUM_CRT_CASE",
-	"FE_NUM_WARRANT",
-	"DISP_ORDER",
-	"DISP_CODE",
-	"DISP_DESCR",
-	"CONV_STAT_CODE",
-	"CONV_STAT_DESCR",
-	"SENT_SEG_SEP",
-	"SENT_ORDER",
-	"SENT_LOC_CODE",
-	"SENT_LOC_DESCR",
-	"SENT_LENGTH",
-	"SENT_TIME_CODE",
-	"SENT_TIME_DESCR",
-	"CYC_AGE",
-	"CII_TYPE",
-	"CII_TYPE_ALPHA",
-	"COMMENT_TEXT",
-	"END_OF_REC",
-}
+var DojFullHeaders = data.DOJColumnNames[:]
+
 var DojCondensedHeaders = []string{
 	"CII_NUMBER",
 	"PRI_NAME",
@@ -153,9 +61,11 @@ type DOJWriter interface {
 	WriteCondensedEntryWithEligibilityInfo([]string, *data.EligibilityInfo, string)
 	Write([]string)
 	Flush()
+	Close() error
 }
 
 type csvWriter struct {
+	outputFile       *os.File
 	outputFileWriter *csv.Writer
 	filename         string
 }
@@ -167,20 +77,29 @@ func NewWriter(outputFilePath string, headers []string) (DOJWriter, error) {
 	}
 
 	w := new(csvWriter)
+	w.outputFile = outputFile
 	w.outputFileWriter = csv.NewWriter(outputFile)
 	w.filename = outputFilePath
 
 	err = w.outputFileWriter.Write(headers)
 	if err != nil {
+		outputFile.Close()
 		return nil, err
 	}
 
 	return w, nil
 }
 
-func NewDOJWriter(outputFilePath string) (DOJWriter, error) {
-	headers := append(DojFullHeaders, EligiblityHeaders...)
-	return NewWriter(outputFilePath, headers)
+// NewDOJWriter writes the full results. extraColumns are the names of the
+// columns a DOJ file has beyond the columns defined in the data package, which
+// are written after END_OF_REC.
+func NewDOJWriter(outputFilePath string, extraColumns ...string) (DOJWriter, error) {
+	return NewWriter(outputFilePath, fullResultsHeaders(extraColumns))
+}
+
+func fullResultsHeaders(extraColumns []string) []string {
+	headers := append(DojFullHeaders, extraColumns...)
+	return append(headers, EligiblityHeaders...)
 }
 
 func NewCondensedDOJWriter(outputFilePath string) (DOJWriter, error) {
@@ -188,6 +107,18 @@ func NewCondensedDOJWriter(outputFilePath string) (DOJWriter, error) {
 	return NewWriter(outputFilePath, headers)
 }
 
+// NewRejectedRowsWriter writes rows that failed validation, prefixed with
+// their row number in the input file and the reasons they were rejected.
+func NewRejectedRowsWriter(outputFilePath string, extraColumns ...string) (DOJWriter, error) {
+	headers := append([]string{"ROW_NUMBER", "REJECTION_REASONS"}, DojFullHeaders...)
+	return NewWriter(outputFilePath, append(headers, extraColumns...))
+}
+
+func rejectedRowEntry(rejectedRow data.RejectedRow) []string {
+	entry := []string{strconv.Itoa(rejectedRow.RowNumber), strings.Join(rejectedRow.Reasons, "; ")}
+	return append(entry, rejectedRow.Row...)
+}
+
 func (cw csvWriter) WriteEntryWithEligibilityInfo(entry []string, info *data.EligibilityInfo, possibleOtherP64Charges string) {
 	var eligibilityCols []string
 
@@ -210,6 +141,7 @@ func (cw csvWriter) WriteEntryWithEligibilityInfo(entry []string, info *data.Eli
 			info.Deceased,
 			info.EligibilityDetermination,
 			info.EligibilityReason,
+			info.TraceSummary(),
 		}
 	} else {
 		eligibilityCols = make([]string, len(EligiblityHeaders))
@@ -220,6 +152,12 @@ func (cw csvWriter) WriteEntryWithEligibilityInfo(entry []string, info *data.Eli
 }
 
 func (cw csvWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	cw.WriteEntryWithEligibilityInfo(condensedEntry(entry), info, possibleOtherP64Charges)
+}
+
+// condensedEntry picks the DOJ columns written to the condensed results, in
+// the order of DojCondensedHeaders.
+func condensedEntry(entry []string) []string {
 	var condensedRow []string
 
 	includedColumns := []int{
@@ -248,8 +186,7 @@ func (cw csvWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, info
 	for _, col := range includedColumns {
 		condensedRow = append(condensedRow, entry[col])
 	}
-
-	cw.WriteEntryWithEligibilityInfo(condensedRow, info, possibleOtherP64Charges)
+	return condensedRow
 }
 
 func writeDate(val time.Time) string {
@@ -260,6 +197,17 @@ func (cw csvWriter) Flush() {
 	cw.outputFileWriter.Flush()
 }
 
+// Close flushes the rows written so far and closes the file.
+func (cw csvWriter) Close() error {
+	cw.outputFileWriter.Flush()
+	err := cw.outputFileWriter.Error()
+	closeErr := cw.outputFile.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
 func (cw csvWriter) Write(line []string) {
 	_ = cw.outputFileWriter.Write(line)
 }
@@ -271,3 +219,34 @@ func writeFloat(val float64) string {
 func writeInt(val int) string {
 	return fmt.Sprintf("%d", val)
 }
+
+// NewIdentityReportWriter writes the SUBJECT_IDs merged by identity
+// resolution and the probable duplicates flagged for review.
+func NewIdentityReportWriter(outputFilePath string) (DOJWriter, error) {
+	headers := []string{"ACTION", "RESOLVED_SUBJECT_ID", "SUBJECT_IDS", "MATCHED_ON", "PRI_NAME", "PRI_DOB"}
+	return NewWriter(outputFilePath, headers)
+}
+
+func WriteIdentityReport(writer DOJWriter, identities *data.IdentityResolution) {
+	for _, merge := range identities.Merges {
+		writer.Write([]string{
+			"MERGED",
+			merge.ResolvedSubjectID,
+			strings.Join(merge.SubjectIDs, "; "),
+			strings.Join(merge.MatchedOn, "; "),
+			"",
+			"",
+		})
+	}
+	for _, duplicate := range identities.ProbableDuplicates {
+		writer.Write([]string{
+			"REVIEW",
+			"",
+			strings.Join(duplicate.SubjectIDs, "; "),
+			"PRI_NAME and PRI_DOB",
+			duplicate.Name,
+			duplicate.DOB,
+		})
+	}
+	writer.Flush()
+}
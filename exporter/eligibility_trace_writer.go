@@ -0,0 +1,104 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"gogen_pilots/data"
+	"os"
+)
+
+// EligibilityTraceWriter writes the decision trace of every conviction the
+// eligibility flow evaluated to a JSON array, one subject at a time.
+type EligibilityTraceWriter struct {
+	outputFile     *os.File
+	writer         *bufio.Writer
+	entriesWritten int
+}
+
+type eligibilityTraceEntry struct {
+	SubjectID                string           `json:"subjectId"`
+	CountOrder               string           `json:"countOrder"`
+	CodeSection              string           `json:"codeSection"`
+	DateOfConviction         string           `json:"dateOfConviction"`
+	EligibilityDetermination string           `json:"eligibilityDetermination"`
+	EligibilityReason        string           `json:"eligibilityReason"`
+	Trace                    []data.TraceStep `json:"trace"`
+}
+
+func NewEligibilityTraceWriter(outputFilePath string) (*EligibilityTraceWriter, error) {
+	outputFile, err := os.Create(outputFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	w := EligibilityTraceWriter{
+		outputFile: outputFile,
+		writer:     bufio.NewWriter(outputFile),
+	}
+	_, err = w.writer.WriteString("[")
+	if err != nil {
+		outputFile.Close()
+		return nil, err
+	}
+	return &w, nil
+}
+
+// WriteEligibilities writes an entry for each row that has eligibility info,
+// in row order.
+func (w *EligibilityTraceWriter) WriteEligibilities(dojInformation *data.DOJInformation, eligibilities map[int]*data.EligibilityInfo) error {
+	for i, row := range dojInformation.Rows {
+		info := eligibilities[i]
+		if info == nil {
+			continue
+		}
+
+		trace := info.Trace
+		if trace == nil {
+			trace = []data.TraceStep{}
+		}
+
+		conviction := data.NewDOJRow(row, i)
+		entry, err := json.Marshal(eligibilityTraceEntry{
+			SubjectID:                conviction.SubjectID,
+			CountOrder:               conviction.CountOrder,
+			CodeSection:              conviction.CodeSection,
+			DateOfConviction:         info.DateOfConviction.Format("2006-01-02"),
+			EligibilityDetermination: info.EligibilityDetermination,
+			EligibilityReason:        info.EligibilityReason,
+			Trace:                    trace,
+		})
+		if err != nil {
+			return err
+		}
+
+		separator := ",\n"
+		if w.entriesWritten == 0 {
+			separator = "\n"
+		}
+		_, err = w.writer.WriteString(separator)
+		if err != nil {
+			return err
+		}
+		_, err = w.writer.Write(entry)
+		if err != nil {
+			return err
+		}
+		w.entriesWritten++
+	}
+	return nil
+}
+
+// Close ends the JSON array and closes the file.
+func (w *EligibilityTraceWriter) Close() error {
+	_, err := w.writer.WriteString("\n]\n")
+	if err != nil {
+		w.outputFile.Close()
+		return err
+	}
+	err = w.writer.Flush()
+	if err != nil {
+		w.outputFile.Close()
+		return err
+	}
+	return w.outputFile.Close()
+}
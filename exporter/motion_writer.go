@@ -0,0 +1,218 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	textTemplate "text/template"
+	"time"
+
+	"gogen_pilots/data"
+)
+
+// MotionCase is the data a motion template is executed with: one court case
+// of a subject and its convictions that are eligible for relief.
+type MotionCase struct {
+	SubjectID   string
+	Name        string
+	DOB         time.Time
+	CaseNumber  string
+	County      string
+	Convictions []MotionConviction
+}
+
+type MotionConviction struct {
+	CountOrder               string
+	CodeSection              string
+	DispositionDate          time.Time
+	IsFelony                 bool
+	EligibilityDetermination string
+	EligibilityReason        string
+}
+
+// CodeSections lists the code sections of the case's convictions, ex: for a
+// template to write "11357(A) HS, 11359 HS".
+func (c MotionCase) CodeSections() []string {
+	var codeSections []string
+	for _, conviction := range c.Convictions {
+		codeSections = append(codeSections, conviction.CodeSection)
+	}
+	return codeSections
+}
+
+type motionTemplate interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// MotionTemplate renders motions as plain text, or as HTML when the template
+// file ends in .html or .htm, in which case values are escaped.
+type MotionTemplate struct {
+	template  motionTemplate
+	extension string
+}
+
+var motionTemplateFuncs = map[string]interface{}{
+	"formatDate": func(layout string, date time.Time) string {
+		if date.IsZero() {
+			return ""
+		}
+		return date.Format(layout)
+	},
+	"join": strings.Join,
+}
+
+func LoadMotionTemplate(templatePath string) (MotionTemplate, error) {
+	contents, err := ioutil.ReadFile(templatePath)
+	if err != nil {
+		return MotionTemplate{}, err
+	}
+
+	var motion MotionTemplate
+	switch extension := strings.ToLower(path.Ext(templatePath)); extension {
+	case ".html", ".htm":
+		motion.extension = extension
+		motion.template, err = htmlTemplate.New(path.Base(templatePath)).Funcs(motionTemplateFuncs).Parse(string(contents))
+	default:
+		motion.extension = ".txt"
+		motion.template, err = textTemplate.New(path.Base(templatePath)).Funcs(motionTemplateFuncs).Parse(string(contents))
+	}
+	if err != nil {
+		return MotionTemplate{}, err
+	}
+
+	// Execute the template once before any file is processed, so a field that
+	// does not exist is reported up front instead of for every case.
+	sample := MotionCase{Convictions: []MotionConviction{{}}}
+	if err = motion.template.Execute(ioutil.Discard, sample); err != nil {
+		return MotionTemplate{}, err
+	}
+	return motion, nil
+}
+
+// MotionWriter writes a motion for each case with a conviction eligible for
+// dismissal or reduction, and an index CSV of the motions it wrote.
+type MotionWriter struct {
+	motionTemplate MotionTemplate
+	outputFolder   string
+	indexWriter    DOJWriter
+	motionsWritten int
+}
+
+func NewMotionWriter(motionTemplate MotionTemplate, outputFolder string, indexFilePath string) (*MotionWriter, error) {
+	err := os.MkdirAll(outputFolder, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	indexWriter, err := NewWriter(indexFilePath, []string{"DOCUMENT", "SUBJECT_ID", "PRI_NAME", "CASE_NUMBER", "CNT_ORDERS", "CODE_SECTIONS", "ELIGIBILITY_DETERMINATIONS"})
+	if err != nil {
+		return nil, err
+	}
+	return &MotionWriter{
+		motionTemplate: motionTemplate,
+		outputFolder:   outputFolder,
+		indexWriter:    indexWriter,
+	}, nil
+}
+
+// WriteMotions writes the motions for a subject's eligible convictions,
+// grouping convictions into cases by the case part of their CNT_ORDER.
+func (w *MotionWriter) WriteMotions(dojInformation *data.DOJInformation, eligibilities map[int]*data.EligibilityInfo) error {
+	var cases []*MotionCase
+	casesByKey := make(map[string]*MotionCase)
+
+	for i, row := range dojInformation.Rows {
+		info := eligibilities[i]
+		if info == nil || !isEligibleForMotion(info.EligibilityDetermination) {
+			continue
+		}
+
+		conviction := data.NewDOJRow(row, i)
+		key := conviction.SubjectID + "_" + conviction.CountOrder[0:6]
+		motionCase := casesByKey[key]
+		if motionCase == nil {
+			motionCase = &MotionCase{
+				SubjectID:  conviction.SubjectID,
+				Name:       conviction.Name,
+				DOB:        conviction.DOB,
+				CaseNumber: info.CaseNumber,
+				County:     conviction.County,
+			}
+			casesByKey[key] = motionCase
+			cases = append(cases, motionCase)
+		}
+		motionCase.Convictions = append(motionCase.Convictions, MotionConviction{
+			CountOrder:               conviction.CountOrder,
+			CodeSection:              conviction.CodeSection,
+			DispositionDate:          conviction.DispositionDate,
+			IsFelony:                 conviction.IsFelony,
+			EligibilityDetermination: info.EligibilityDetermination,
+			EligibilityReason:        info.EligibilityReason,
+		})
+	}
+
+	for _, motionCase := range cases {
+		err := w.writeMotion(motionCase)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func (w *MotionWriter) writeMotion(motionCase *MotionCase) error {
+	var document bytes.Buffer
+	err := w.motionTemplate.template.Execute(&document, motionCase)
+	if err != nil {
+		return fmt.Errorf("motion for subject %s: %v", motionCase.SubjectID, err)
+	}
+
+	caseOrder := motionCase.Convictions[0].CountOrder[0:6]
+	fileName := unsafeFileNameCharacters.ReplaceAllString(fmt.Sprintf("motion_%s_%s", motionCase.SubjectID, caseOrder), "_") + w.motionTemplate.extension
+	err = ioutil.WriteFile(path.Join(w.outputFolder, fileName), document.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+
+	var countOrders, determinations []string
+	for _, conviction := range motionCase.Convictions {
+		countOrders = append(countOrders, conviction.CountOrder)
+		determinations = append(determinations, conviction.EligibilityDetermination)
+	}
+	w.indexWriter.Write([]string{
+		fileName,
+		motionCase.SubjectID,
+		motionCase.Name,
+		motionCase.CaseNumber,
+		strings.Join(countOrders, "; "),
+		strings.Join(motionCase.CodeSections(), "; "),
+		strings.Join(determinations, "; "),
+	})
+	w.motionsWritten++
+	return nil
+}
+
+// MotionsWritten is the number of motions written so far.
+func (w *MotionWriter) MotionsWritten() int {
+	return w.motionsWritten
+}
+
+func (w *MotionWriter) Flush() {
+	w.indexWriter.Flush()
+}
+
+// Close closes the index CSV.
+func (w *MotionWriter) Close() error {
+	return w.indexWriter.Close()
+}
+
+func isEligibleForMotion(determination string) bool {
+	return determination == "Eligible for Dismissal" || determination == "Eligible for Reduction"
+}
@@ -0,0 +1,128 @@
+package exporter_test
+
+import (
+	"encoding/csv"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+	. "gogen_pilots/exporter"
+	. "gogen_pilots/test_fixtures"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"time"
+)
+
+var _ = Describe("MotionWriter", func() {
+	const COUNTY = "LOS ANGELES"
+
+	var (
+		outputDir string
+		err       error
+	)
+
+	BeforeEach(func() {
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	writeTemplate := func(fileName string, contents string) string {
+		templatePath := path.Join(outputDir, fileName)
+		Expect(ioutil.WriteFile(templatePath, []byte(contents), 0644)).To(Succeed())
+		return templatePath
+	}
+
+	readCSV := func(filePath string) [][]string {
+		file, err := os.Open(filePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+		rows, err := csv.NewReader(file).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		return rows
+	}
+
+	Describe("LoadMotionTemplate", func() {
+		It("loads the example motion template", func() {
+			_, err := LoadMotionTemplate(path.Join("..", "motion_templates", "prop64_motion.txt"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("rejects templates that do not parse", func() {
+			_, err := LoadMotionTemplate(writeTemplate("motion.txt", "{{range .Convictions}}"))
+			Expect(err).To(MatchError(ContainSubstring("unexpected EOF")))
+		})
+
+		It("rejects templates that use fields a motion does not have", func() {
+			_, err := LoadMotionTemplate(writeTemplate("motion.txt", "{{.Name}} {{.CaseNumbr}}"))
+			Expect(err).To(MatchError(ContainSubstring("can't evaluate field CaseNumbr")))
+		})
+	})
+
+	Describe("Writing motions", func() {
+		var (
+			dojInformation *data.DOJInformation
+			eligibilities  map[int]*data.EligibilityInfo
+		)
+
+		BeforeEach(func() {
+			pathToDOJ, _, err := ExtractFullCSVFixtures(path.Join("..", "test_fixtures", "los_angeles.xlsx"))
+			Expect(err).ToNot(HaveOccurred())
+			comparisonTime := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+			dojInformation, err = data.NewDOJInformation(pathToDOJ, comparisonTime, data.EligibilityFlows[COUNTY])
+			Expect(err).ToNot(HaveOccurred())
+			eligibilities = dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows[COUNTY], 50, 10)
+		})
+
+		It("writes a motion for each case with an eligible conviction and an index of the motions", func() {
+			motionTemplate, err := LoadMotionTemplate(writeTemplate("motion.txt",
+				`{{.Name}} ({{formatDate "2006-01-02" .DOB}}) case {{.CaseNumber}}: {{join .CodeSections ", "}}{{range .Convictions}} [{{.EligibilityDetermination}}: {{.EligibilityReason}}]{{end}}`))
+			Expect(err).ToNot(HaveOccurred())
+			motionWriter, err := NewMotionWriter(motionTemplate, path.Join(outputDir, "motions"), path.Join(outputDir, "index.csv"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(motionWriter.WriteMotions(dojInformation, eligibilities)).To(Succeed())
+			motionWriter.Flush()
+
+			index := readCSV(path.Join(outputDir, "index.csv"))
+			Expect(index[0]).To(Equal([]string{"DOCUMENT", "SUBJECT_ID", "PRI_NAME", "CASE_NUMBER", "CNT_ORDERS", "CODE_SECTIONS", "ELIGIBILITY_DETERMINATIONS"}))
+			Expect(index).To(HaveLen(8))
+			Expect(motionWriter.MotionsWritten()).To(Equal(7))
+			Expect(index).To(ContainElement([]string{
+				"motion_23675654_101001.txt",
+				"23675654",
+				"MONSTER,ELMO",
+				"140194; 140195",
+				"101001012000; 101001015000",
+				"11358 HS; 11359(C) HS",
+				"Eligible for Dismissal; Eligible for Dismissal",
+			}))
+
+			motion, err := ioutil.ReadFile(path.Join(outputDir, "motions", "motion_23675654_101001.txt"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(motion)).To(Equal("MONSTER,ELMO (1960-03-14) case 140194; 140195: 11358 HS, 11359(C) HS [Eligible for Dismissal: 50 years or older] [Eligible for Dismissal: 50 years or older]"))
+
+			for _, entry := range index[1:] {
+				Expect(path.Join(outputDir, "motions", entry[0])).To(BeAnExistingFile())
+				Expect(entry[6]).ToNot(ContainSubstring("Not eligible"))
+				Expect(entry[6]).ToNot(ContainSubstring("Hand Review"))
+			}
+		})
+
+		It("escapes values in HTML templates", func() {
+			motionTemplate, err := LoadMotionTemplate(writeTemplate("motion.html", `<p>{{.Name}}</p>`))
+			Expect(err).ToNot(HaveOccurred())
+			motionWriter, err := NewMotionWriter(motionTemplate, path.Join(outputDir, "motions"), path.Join(outputDir, "index.csv"))
+			Expect(err).ToNot(HaveOccurred())
+
+			for i := range dojInformation.Rows {
+				dojInformation.Rows[i][data.PRI_NAME] = "<b>BIRD,BIG</b>"
+			}
+			Expect(motionWriter.WriteMotions(dojInformation, eligibilities)).To(Succeed())
+			motionWriter.Flush()
+
+			motion, err := ioutil.ReadFile(path.Join(outputDir, "motions", "motion_17954908_101001.html"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(motion)).To(Equal("<p>&lt;b&gt;BIRD,BIG&lt;/b&gt;</p>"))
+		})
+	})
+})
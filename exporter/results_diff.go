@@ -0,0 +1,422 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gogen_pilots/data"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ResultsDiffHeaders = []string{
+	"DOJ Input File",
+	"SUBJECT_ID",
+	"CNT_ORDER",
+	"Code Section",
+	"Before Eligibility Determination",
+	"Before Eligibility Reason",
+	"After Eligibility Determination",
+	"After Eligibility Reason",
+}
+
+// NotEvaluated and NotInResults stand in for a determination in the diff of
+// two runs, when a conviction was not evaluated by a run's eligibility flow or
+// its row is missing from a run's results.
+const (
+	NotEvaluated = "Not evaluated"
+	NotInResults = "Not in results"
+)
+
+var (
+	resultsFolderPattern = regexp.MustCompile(`^DOJ_Input_File_(\d+)_Results`)
+	resultsFilePattern   = regexp.MustCompile(`^doj_results_\d+(_.*)?\.csv$`)
+	summaryFilePattern   = regexp.MustCompile(`^gogen_pilots(_.*)?\.json$`)
+)
+
+// ResultsDiff is the difference between the results of two runs over the same
+// DOJ files, ex: before and after a change to the eligibility options.
+type ResultsDiff struct {
+	Changes        []DeterminationChange
+	Transitions    map[DeterminationTransition]int
+	SummaryChanges []SummaryChange
+}
+
+// DeterminationChange is a conviction whose eligibility determination or
+// reason changed between the runs.
+type DeterminationChange struct {
+	FileIndex           int
+	SubjectID           string
+	CountOrder          string
+	CodeSection         string
+	BeforeDetermination string
+	BeforeReason        string
+	AfterDetermination  string
+	AfterReason         string
+}
+
+type DeterminationTransition struct {
+	Before string
+	After  string
+}
+
+// SummaryChange is a value of the summary JSON that changed between the runs.
+// Nested values are named by their path, ex: "prop64ConvictionsCountInCountyByCodeSection.11357".
+type SummaryChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// resultsRow is a row of a full results CSV, matched between runs by its
+// SUBJECT_ID, CNT_ORDER and, when rows share those, by their order.
+type resultsRow struct {
+	key           string
+	subjectID     string
+	countOrder    string
+	codeSection   string
+	determination string
+	reason        string
+}
+
+// DiffResults compares the output folders of two runs. Both must have results
+// for the same DOJ input files, written as CSV.
+func DiffResults(beforeFolder string, afterFolder string) (ResultsDiff, error) {
+	diff := ResultsDiff{Transitions: make(map[DeterminationTransition]int)}
+
+	beforeFiles, err := findResultsFiles(beforeFolder)
+	if err != nil {
+		return ResultsDiff{}, err
+	}
+	afterFiles, err := findResultsFiles(afterFolder)
+	if err != nil {
+		return ResultsDiff{}, err
+	}
+	if len(beforeFiles) != len(afterFiles) {
+		return ResultsDiff{}, fmt.Errorf("%s has results for %d DOJ files and %s has results for %d", beforeFolder, len(beforeFiles), afterFolder, len(afterFiles))
+	}
+
+	var fileIndexes []int
+	for fileIndex := range beforeFiles {
+		if _, ok := afterFiles[fileIndex]; !ok {
+			return ResultsDiff{}, fmt.Errorf("%s has no results for DOJ input file %d", afterFolder, fileIndex)
+		}
+		fileIndexes = append(fileIndexes, fileIndex)
+	}
+	sort.Ints(fileIndexes)
+
+	for _, fileIndex := range fileIndexes {
+		beforeRows, err := readResultsRows(beforeFiles[fileIndex])
+		if err != nil {
+			return ResultsDiff{}, err
+		}
+		afterRows, err := readResultsRows(afterFiles[fileIndex])
+		if err != nil {
+			return ResultsDiff{}, err
+		}
+		diff.addFile(fileIndex, beforeRows, afterRows)
+	}
+
+	diff.SummaryChanges, err = diffSummaries(beforeFolder, afterFolder)
+	if err != nil {
+		return ResultsDiff{}, err
+	}
+	return diff, nil
+}
+
+func (diff *ResultsDiff) addFile(fileIndex int, beforeRows []resultsRow, afterRows []resultsRow) {
+	afterRowsByKey := make(map[string]resultsRow)
+	for _, row := range afterRows {
+		afterRowsByKey[row.key] = row
+	}
+	beforeKeys := make(map[string]bool)
+
+	compare := func(before resultsRow, after resultsRow) {
+		if before.determination == "" && after.determination == "" {
+			return
+		}
+		beforeDetermination := displayedDetermination(before)
+		afterDetermination := displayedDetermination(after)
+		diff.Transitions[DeterminationTransition{beforeDetermination, afterDetermination}]++
+		if beforeDetermination == afterDetermination && before.reason == after.reason {
+			return
+		}
+		row := before
+		if row.key == "" {
+			row = after
+		}
+		diff.Changes = append(diff.Changes, DeterminationChange{
+			FileIndex:           fileIndex,
+			SubjectID:           row.subjectID,
+			CountOrder:          row.countOrder,
+			CodeSection:         row.codeSection,
+			BeforeDetermination: beforeDetermination,
+			BeforeReason:        before.reason,
+			AfterDetermination:  afterDetermination,
+			AfterReason:         after.reason,
+		})
+	}
+
+	for _, before := range beforeRows {
+		beforeKeys[before.key] = true
+		compare(before, afterRowsByKey[before.key])
+	}
+	for _, after := range afterRows {
+		if !beforeKeys[after.key] {
+			compare(resultsRow{}, after)
+		}
+	}
+}
+
+func displayedDetermination(row resultsRow) string {
+	if row.key == "" {
+		return NotInResults
+	}
+	if row.determination == "" {
+		return NotEvaluated
+	}
+	return row.determination
+}
+
+// findResultsFiles finds the full results CSV of each DOJ input file of a run,
+// by the index of the input file.
+func findResultsFiles(outputFolder string) (map[int]string, error) {
+	entries, err := ioutil.ReadDir(outputFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsFiles := make(map[int]string)
+	for _, entry := range entries {
+		match := resultsFolderPattern.FindStringSubmatch(entry.Name())
+		if !entry.IsDir() || match == nil {
+			continue
+		}
+		fileIndex, _ := strconv.Atoi(match[1])
+		resultsFolder := filepath.Join(outputFolder, entry.Name())
+		resultsFile, err := findFile(resultsFolder, resultsFilePattern)
+		if err != nil {
+			return nil, err
+		}
+		if resultsFile == "" {
+			return nil, fmt.Errorf("%s has no doj_results CSV: Runs written with --output-format xlsx cannot be compared", resultsFolder)
+		}
+		resultsFiles[fileIndex] = resultsFile
+	}
+	if len(resultsFiles) == 0 {
+		return nil, fmt.Errorf("%s has no DOJ_Input_File_N_Results folders", outputFolder)
+	}
+	return resultsFiles, nil
+}
+
+// findFile returns the one file in folder whose name matches pattern, or ""
+// when there is none.
+func findFile(folder string, pattern *regexp.Regexp) (string, error) {
+	entries, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return "", err
+	}
+	var found []string
+	for _, entry := range entries {
+		if !entry.IsDir() && pattern.MatchString(entry.Name()) {
+			found = append(found, filepath.Join(folder, entry.Name()))
+		}
+	}
+	if len(found) > 1 {
+		return "", fmt.Errorf("%s has more than one results file: %s", folder, strings.Join(found, ", "))
+	}
+	if len(found) == 0 {
+		return "", nil
+	}
+	return found[0], nil
+}
+
+func readResultsRows(resultsFile string) ([]resultsRow, error) {
+	file, err := os.Open(resultsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", resultsFile, err)
+	}
+	columns := make(map[string]int)
+	for i, header := range headers {
+		columns[header] = i
+	}
+	for _, header := range []string{"SUBJECT_ID", "CNT_ORDER", "OFFENSE_DESCR", "COMMENT_TEXT", "Eligibility Determination", "Eligibility Reason"} {
+		if _, ok := columns[header]; !ok {
+			return nil, fmt.Errorf("%s has no %s column", resultsFile, header)
+		}
+	}
+	value := func(record []string, header string) string {
+		if columns[header] < len(record) {
+			return record[columns[header]]
+		}
+		return ""
+	}
+
+	var rows []resultsRow
+	occurrences := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", resultsFile, err)
+		}
+		row := resultsRow{
+			subjectID:     value(record, "SUBJECT_ID"),
+			countOrder:    value(record, "CNT_ORDER"),
+			determination: value(record, "Eligibility Determination"),
+			reason:        value(record, "Eligibility Reason"),
+		}
+		row.codeSection = value(record, "OFFENSE_DESCR")
+		if data.IsCodeSectionInComment(row.codeSection) {
+			row.codeSection = value(record, "COMMENT_TEXT")
+		}
+		row.codeSection = strings.Split(row.codeSection, "-")[0]
+
+		subjectCount := row.subjectID + ":" + row.countOrder
+		occurrences[subjectCount]++
+		row.key = fmt.Sprintf("%s:%d", subjectCount, occurrences[subjectCount])
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// diffSummaries compares the summary JSON of the runs value by value. The
+// processing time is left out, since it changes with every run.
+func diffSummaries(beforeFolder string, afterFolder string) ([]SummaryChange, error) {
+	before, err := readSummaryValues(beforeFolder)
+	if err != nil {
+		return nil, err
+	}
+	after, err := readSummaryValues(afterFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool)
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+	var sortedFields []string
+	for field := range fields {
+		if field != "processingTimeInSeconds" {
+			sortedFields = append(sortedFields, field)
+		}
+	}
+	sort.Strings(sortedFields)
+
+	var changes []SummaryChange
+	for _, field := range sortedFields {
+		beforeValue, inBefore := before[field]
+		afterValue, inAfter := after[field]
+		if inBefore && inAfter && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		changes = append(changes, SummaryChange{
+			Field:  field,
+			Before: summaryValueString(beforeValue, inBefore),
+			After:  summaryValueString(afterValue, inAfter),
+		})
+	}
+	return changes, nil
+}
+
+func readSummaryValues(outputFolder string) (map[string]interface{}, error) {
+	summaryFile, err := findFile(outputFolder, summaryFilePattern)
+	if err != nil {
+		return nil, err
+	}
+	if summaryFile == "" {
+		return nil, fmt.Errorf("%s has no gogen_pilots.json summary", outputFolder)
+	}
+	summaryJSON, err := ioutil.ReadFile(summaryFile)
+	if err != nil {
+		return nil, err
+	}
+	var summary map[string]interface{}
+	err = json.Unmarshal(summaryJSON, &summary)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", summaryFile, err)
+	}
+
+	values := make(map[string]interface{})
+	flattenSummary("", summary, values)
+	return values, nil
+}
+
+func flattenSummary(prefix string, summary map[string]interface{}, values map[string]interface{}) {
+	for key, value := range summary {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenSummary(prefix+key+".", nested, values)
+			continue
+		}
+		values[prefix+key] = value
+	}
+}
+
+func summaryValueString(value interface{}, present bool) string {
+	if !present {
+		return "none"
+	}
+	return fmt.Sprint(value)
+}
+
+// WriteChanges writes each changed conviction as a row of ResultsDiffHeaders.
+func (diff ResultsDiff) WriteChanges(w DOJWriter) {
+	for _, change := range diff.Changes {
+		w.Write([]string{
+			strconv.Itoa(change.FileIndex),
+			change.SubjectID,
+			change.CountOrder,
+			change.CodeSection,
+			change.BeforeDetermination,
+			change.BeforeReason,
+			change.AfterDetermination,
+			change.AfterReason,
+		})
+	}
+	w.Flush()
+}
+
+// PrintTransitions prints how many convictions went from each determination
+// to each other, including those whose determination stayed the same.
+func (diff ResultsDiff) PrintTransitions(w io.Writer) {
+	var transitions []DeterminationTransition
+	for transition := range diff.Transitions {
+		transitions = append(transitions, transition)
+	}
+	sort.Slice(transitions, func(i, j int) bool {
+		if transitions[i].Before != transitions[j].Before {
+			return transitions[i].Before < transitions[j].Before
+		}
+		return transitions[i].After < transitions[j].After
+	})
+	for _, transition := range transitions {
+		fmt.Fprintf(w, "%s → %s: %d\n", transition.Before, transition.After, diff.Transitions[transition])
+	}
+}
+
+func (diff ResultsDiff) PrintSummaryChanges(w io.Writer) {
+	for _, change := range diff.SummaryChanges {
+		fmt.Fprintf(w, "%s: %s → %s\n", change.Field, change.Before, change.After)
+	}
+}
@@ -0,0 +1,122 @@
+package exporter_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen_pilots/exporter"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"strings"
+)
+
+var _ = Describe("ResultsDiff", func() {
+	var (
+		beforeDir string
+		afterDir  string
+		err       error
+	)
+
+	resultsHeaders := []string{"SUBJECT_ID", "CNT_ORDER", "OFFENSE_DESCR", "COMMENT_TEXT", "Eligibility Determination", "Eligibility Reason"}
+
+	writeRun := func(outputDir string, summary string, rows ...[]string) {
+		resultsDir := path.Join(outputDir, "DOJ_Input_File_1_Results")
+		Expect(os.MkdirAll(resultsDir, os.ModePerm)).To(Succeed())
+		var results bytes.Buffer
+		writer := csv.NewWriter(&results)
+		Expect(writer.WriteAll(append([][]string{resultsHeaders}, rows...))).To(Succeed())
+		Expect(ioutil.WriteFile(path.Join(resultsDir, "doj_results_1.csv"), results.Bytes(), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(path.Join(resultsDir, "doj_results_condensed_1.csv"), []byte("ignored\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(path.Join(outputDir, "gogen_pilots.json"), []byte(summary), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		beforeDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		afterDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("reports the convictions whose determination or reason changed, matched by SUBJECT_ID and CNT_ORDER", func() {
+		writeRun(beforeDir, `{"county":"LOS ANGELES","lineCount":4,"processingTimeInSeconds":1.5,"convictionDismissalCountByAdditionalRelief":{"50 years or older":2}}`,
+			[]string{"1", "101001001000", "11357(A) HS-POSSESS", "", "Eligible for Dismissal", "11357(a) or 11357(b)"},
+			[]string{"1", "101001002000", "", "11358 HS-CULTIVATE", "Hand Review", "No applicable eligibility criteria"},
+			[]string{"1", "101001002000", "", "", "", ""},
+			[]string{"2", "101001001000", "11359 HS-SELL", "", "Eligible for Dismissal", "50 years or older"},
+		)
+		writeRun(afterDir, `{"county":"LOS ANGELES","lineCount":4,"processingTimeInSeconds":2.5,"convictionDismissalCountByAdditionalRelief":{"50 years or older":1}}`,
+			[]string{"2", "101001001000", "11359 HS-SELL", "", "Eligible for Dismissal", "21 years or younger"},
+			[]string{"1", "101001001000", "11357(A) HS-POSSESS", "", "Eligible for Dismissal", "11357(a) or 11357(b)"},
+			[]string{"1", "101001002000", "", "11358 HS-CULTIVATE", "Eligible for Dismissal", "50 years or older"},
+			[]string{"1", "101001002000", "", "", "", ""},
+		)
+
+		diff, err := DiffResults(beforeDir, afterDir)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(diff.Changes).To(Equal([]DeterminationChange{
+			{FileIndex: 1, SubjectID: "1", CountOrder: "101001002000", CodeSection: "11358 HS", BeforeDetermination: "Hand Review", BeforeReason: "No applicable eligibility criteria", AfterDetermination: "Eligible for Dismissal", AfterReason: "50 years or older"},
+			{FileIndex: 1, SubjectID: "2", CountOrder: "101001001000", CodeSection: "11359 HS", BeforeDetermination: "Eligible for Dismissal", BeforeReason: "50 years or older", AfterDetermination: "Eligible for Dismissal", AfterReason: "21 years or younger"},
+		}))
+		Expect(diff.Transitions).To(Equal(map[DeterminationTransition]int{
+			{Before: "Eligible for Dismissal", After: "Eligible for Dismissal"}: 2,
+			{Before: "Hand Review", After: "Eligible for Dismissal"}:            1,
+		}))
+		Expect(diff.SummaryChanges).To(Equal([]SummaryChange{
+			{Field: "convictionDismissalCountByAdditionalRelief.50 years or older", Before: "2", After: "1"},
+		}))
+
+		var transitions bytes.Buffer
+		diff.PrintTransitions(&transitions)
+		Expect(transitions.String()).To(Equal("Eligible for Dismissal → Eligible for Dismissal: 2\nHand Review → Eligible for Dismissal: 1\n"))
+	})
+
+	It("reports convictions that were not evaluated or are missing from a run", func() {
+		writeRun(beforeDir, `{"county":"LOS ANGELES"}`,
+			[]string{"1", "101001001000", "11357(A) HS-POSSESS", "", "", ""},
+			[]string{"1", "101001002000", "11358 HS-CULTIVATE", "", "Hand Review", "Other 11358"},
+		)
+		writeRun(afterDir, `{"county":"YOLO"}`,
+			[]string{"1", "101001001000", "11357(A) HS-POSSESS", "", "Eligible for Dismissal", "Dismiss all Prop 64 charges"},
+		)
+
+		diff, err := DiffResults(beforeDir, afterDir)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(diff.Transitions).To(Equal(map[DeterminationTransition]int{
+			{Before: NotEvaluated, After: "Eligible for Dismissal"}: 1,
+			{Before: "Hand Review", After: NotInResults}:            1,
+		}))
+		Expect(diff.Changes).To(HaveLen(2))
+		Expect(diff.SummaryChanges).To(Equal([]SummaryChange{{Field: "county", Before: "LOS ANGELES", After: "YOLO"}}))
+	})
+
+	It("fails when the runs do not have CSV results for the same DOJ files", func() {
+		writeRun(beforeDir, `{}`)
+		Expect(os.MkdirAll(path.Join(afterDir, "DOJ_Input_File_1_Results"), os.ModePerm)).To(Succeed())
+
+		_, err := DiffResults(beforeDir, afterDir)
+		Expect(err).To(MatchError(ContainSubstring("Runs written with --output-format xlsx cannot be compared")))
+
+		_, err = DiffResults(beforeDir, path.Join(afterDir, "DOJ_Input_File_1_Results"))
+		Expect(err).To(MatchError(ContainSubstring("has no DOJ_Input_File_N_Results folders")))
+	})
+
+	It("writes the changes as CSV", func() {
+		diffFilePath := path.Join(afterDir, "gogen_pilots_diff.csv")
+		diffWriter, err := NewWriter(diffFilePath, ResultsDiffHeaders)
+		Expect(err).ToNot(HaveOccurred())
+		ResultsDiff{Changes: []DeterminationChange{
+			{FileIndex: 2, SubjectID: "1", CountOrder: "101001001000", CodeSection: "11357 HS", BeforeDetermination: "Hand Review", BeforeReason: "Other 11357", AfterDetermination: "Eligible for Dismissal", AfterReason: "Dismiss all HS 11357 convictions"},
+		}}.WriteChanges(diffWriter)
+
+		contents, err := ioutil.ReadFile(diffFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Split(strings.TrimSpace(string(contents)), "\n")).To(Equal([]string{
+			"DOJ Input File,SUBJECT_ID,CNT_ORDER,Code Section,Before Eligibility Determination,Before Eligibility Reason,After Eligibility Determination,After Eligibility Reason",
+			"2,1,101001001000,11357 HS,Hand Review,Other 11357,Eligible for Dismissal,Dismiss all HS 11357 convictions",
+		}))
+	})
+})
@@ -0,0 +1,161 @@
+package exporter
+
+import (
+	"fmt"
+	"gogen_pilots/data"
+	"gogen_pilots/utilities"
+	"io"
+	"time"
+)
+
+// StreamingDataExporter evaluates and writes a DOJ file one subject at a
+// time, so only the current subject's history is held in memory.
+type StreamingDataExporter struct {
+	dojReader                        *data.DOJReader
+	totalRows                        int
+	countyEligibilityFlow            data.EligibilityFlow
+	age                              int
+	yearsConvictionFree              int
+	outputDOJWriter                  DOJWriter
+	outputCondensedDOJWriter         DOJWriter
+	outputProp64ConvictionsDOJWriter DOJWriter
+	rejectedRowsWriter               DOJWriter
+	eligibilityTraceWriter           *EligibilityTraceWriter
+	motionWriter                     *MotionWriter
+	resultsWorkbook                  *ResultsWorkbook
+	aggregateStatsWriter             io.Writer
+	consoleWriter                    io.Writer
+	progressReporter                 utilities.ProgressReporter
+}
+
+func NewStreamingDataExporter(
+	dojReader *data.DOJReader,
+	totalRows int,
+	countyEligibilityFlow data.EligibilityFlow,
+	age int,
+	yearsConvictionFree int,
+	outputDOJWriter DOJWriter,
+	outputCondensedDOJWriter DOJWriter,
+	outputProp64ConvictionsDOJWriter DOJWriter,
+	rejectedRowsWriter DOJWriter,
+	eligibilityTraceWriter *EligibilityTraceWriter,
+	motionWriter *MotionWriter,
+	resultsWorkbook *ResultsWorkbook,
+	aggregateStatsWriter io.Writer,
+	consoleWriter io.Writer,
+	progressReporter utilities.ProgressReporter,
+) StreamingDataExporter {
+
+	return StreamingDataExporter{
+		dojReader:                        dojReader,
+		totalRows:                        totalRows,
+		countyEligibilityFlow:            countyEligibilityFlow,
+		age:                              age,
+		yearsConvictionFree:              yearsConvictionFree,
+		outputDOJWriter:                  outputDOJWriter,
+		outputCondensedDOJWriter:         outputCondensedDOJWriter,
+		outputProp64ConvictionsDOJWriter: outputProp64ConvictionsDOJWriter,
+		rejectedRowsWriter:               rejectedRowsWriter,
+		eligibilityTraceWriter:           eligibilityTraceWriter,
+		motionWriter:                     motionWriter,
+		resultsWorkbook:                  resultsWorkbook,
+		aggregateStatsWriter:             aggregateStatsWriter,
+		consoleWriter:                    consoleWriter,
+		progressReporter:                 progressReporter,
+	}
+}
+
+func (d *StreamingDataExporter) Export(county string, startTime time.Time) (Summary, error) {
+	var stats aggregateStatistics
+	var totalTime time.Duration = 0
+
+	defer d.flush()
+
+	fmt.Fprintln(d.consoleWriter, "Processing DOJ Data")
+
+	for {
+		subjectStartTime := time.Now()
+
+		dojInformation, err := d.dojReader.NextSubject()
+		d.writeRejectedRows(&stats)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Fprintln(d.consoleWriter)
+			return Summary{}, err
+		}
+
+		countyEligibilities := dojInformation.DetermineEligibility(county, d.countyEligibilityFlow, d.age, d.yearsConvictionFree)
+		subjectExporter := NewDataExporter(
+			dojInformation,
+			countyEligibilities,
+			dojInformation.DetermineEligibility(county, data.EligibilityFlows["DISMISS ALL PROP 64"], d.age, d.yearsConvictionFree),
+			dojInformation.DetermineEligibility(county, data.EligibilityFlows["DISMISS ALL PROP 64 AND RELATED"], d.age, d.yearsConvictionFree),
+			d.outputDOJWriter,
+			d.outputCondensedDOJWriter,
+			d.outputProp64ConvictionsDOJWriter,
+			d.aggregateStatsWriter)
+		subjectExporter.writeRows()
+		err = d.eligibilityTraceWriter.WriteEligibilities(dojInformation, countyEligibilities)
+		if err != nil {
+			fmt.Fprintln(d.consoleWriter)
+			return Summary{}, err
+		}
+		if d.motionWriter != nil {
+			err = d.motionWriter.WriteMotions(dojInformation, countyEligibilities)
+			if err != nil {
+				fmt.Fprintln(d.consoleWriter)
+				return Summary{}, err
+			}
+		}
+		if d.resultsWorkbook != nil {
+			err = d.resultsWorkbook.checkRowLimit()
+			if err != nil {
+				fmt.Fprintln(d.consoleWriter)
+				return Summary{}, err
+			}
+		}
+		stats.add(subjectExporter.aggregateStatistics(county))
+
+		totalTime += time.Since(subjectStartTime)
+		if d.progressReporter != nil {
+			d.progressReporter.ReportProgress(d.dojReader.RowsRead(), d.totalRows, totalTime)
+		}
+	}
+	fmt.Fprintln(d.consoleWriter, "\nComplete...")
+	if stats.rejectedRows > 0 {
+		fmt.Fprintf(d.consoleWriter, "Rejected %d rows that failed validation\n", stats.rejectedRows)
+	}
+	if d.motionWriter != nil {
+		fmt.Fprintf(d.consoleWriter, "Wrote %d motions\n", d.motionWriter.MotionsWritten())
+	}
+
+	printAggregateStatistics(d.aggregateStatsWriter, stats, startTime)
+	if d.resultsWorkbook != nil {
+		d.resultsWorkbook.writeAggregateStatistics(stats, startTime)
+		err := d.resultsWorkbook.save()
+		if err != nil {
+			return Summary{}, err
+		}
+	}
+	return newFileSummary(stats), nil
+}
+
+func (d *StreamingDataExporter) writeRejectedRows(stats *aggregateStatistics) {
+	rejectedRows := d.dojReader.TakeRejectedRows()
+	for _, rejectedRow := range rejectedRows {
+		d.rejectedRowsWriter.Write(rejectedRowEntry(rejectedRow))
+	}
+	stats.addRejectedRows(rejectedRows)
+}
+
+func (d *StreamingDataExporter) flush() {
+	d.outputDOJWriter.Flush()
+	d.outputCondensedDOJWriter.Flush()
+	d.outputProp64ConvictionsDOJWriter.Flush()
+	d.rejectedRowsWriter.Flush()
+	if d.motionWriter != nil {
+		d.motionWriter.Flush()
+	}
+}
@@ -0,0 +1,247 @@
+package exporter_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+	. "gogen_pilots/exporter"
+	. "gogen_pilots/test_fixtures"
+	"gogen_pilots/utilities"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"regexp"
+	"time"
+)
+
+var _ = Describe("StreamingDataExporter", func() {
+	const COUNTY = "LOS ANGELES"
+
+	var (
+		inMemoryOutputDir   string
+		streamingOutputDir  string
+		pathToDOJ           string
+		comparisonTime      time.Time
+		age                 int
+		yearsConvictionFree int
+		err                 error
+	)
+
+	readCSV := func(filePath string) [][]string {
+		file, err := os.Open(filePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		return rows
+	}
+
+	processingTime := regexp.MustCompile(`processed the data in .* seconds`)
+
+	BeforeEach(func() {
+		inMemoryOutputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		streamingOutputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		inputPath := path.Join("..", "test_fixtures", "los_angeles.xlsx")
+		pathToDOJ, _, err = ExtractFullCSVFixtures(inputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		comparisonTime = time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+		age = 50
+		yearsConvictionFree = 10
+	})
+
+	It("produces the same output as exporting the file from memory", func() {
+		flow := data.EligibilityFlows[COUNTY]
+
+		dojInformation, err := data.NewDOJInformation(pathToDOJ, comparisonTime, flow)
+		Expect(err).ToNot(HaveOccurred())
+		dojWriter, _ := NewDOJWriter(path.Join(inMemoryOutputDir, "results.csv"))
+		dojCondensedWriter, _ := NewCondensedDOJWriter(path.Join(inMemoryOutputDir, "condensed.csv"))
+		dojProp64ConvictionsWriter, _ := NewDOJWriter(path.Join(inMemoryOutputDir, "convictions.csv"))
+		var inMemoryStats bytes.Buffer
+		inMemoryExporter := NewDataExporter(
+			dojInformation,
+			dojInformation.DetermineEligibility(COUNTY, flow, age, yearsConvictionFree),
+			dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows["DISMISS ALL PROP 64"], age, yearsConvictionFree),
+			dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows["DISMISS ALL PROP 64 AND RELATED"], age, yearsConvictionFree),
+			dojWriter,
+			dojCondensedWriter,
+			dojProp64ConvictionsWriter,
+			&inMemoryStats)
+		inMemorySummary := inMemoryExporter.Export(COUNTY, time.Now())
+
+		totalRows, err := data.CountDOJRows(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		dojReader, err := data.NewDOJReader(pathToDOJ, data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, flow)
+		Expect(err).ToNot(HaveOccurred())
+		defer dojReader.Close()
+		dojWriter, _ = NewDOJWriter(path.Join(streamingOutputDir, "results.csv"))
+		dojCondensedWriter, _ = NewCondensedDOJWriter(path.Join(streamingOutputDir, "condensed.csv"))
+		dojProp64ConvictionsWriter, _ = NewDOJWriter(path.Join(streamingOutputDir, "convictions.csv"))
+		rejectedRowsWriter, _ := NewRejectedRowsWriter(path.Join(streamingOutputDir, "rejected.csv"))
+		eligibilityTraceWriter, _ := NewEligibilityTraceWriter(path.Join(streamingOutputDir, "trace.json"))
+		var streamingStats bytes.Buffer
+		streamingExporter := NewStreamingDataExporter(
+			dojReader,
+			totalRows,
+			flow,
+			age,
+			yearsConvictionFree,
+			dojWriter,
+			dojCondensedWriter,
+			dojProp64ConvictionsWriter,
+			rejectedRowsWriter,
+			eligibilityTraceWriter,
+			nil,
+			nil,
+			&streamingStats,
+			GinkgoWriter,
+			utilities.ProgressBar{Writer: GinkgoWriter})
+		streamingSummary, err := streamingExporter.Export(COUNTY, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(eligibilityTraceWriter.Close()).To(Succeed())
+
+		Expect(streamingSummary).To(Equal(inMemorySummary))
+		Expect(processingTime.ReplaceAllString(streamingStats.String(), "")).To(Equal(processingTime.ReplaceAllString(inMemoryStats.String(), "")))
+		for _, fileName := range []string{"results.csv", "condensed.csv", "convictions.csv"} {
+			Expect(readCSV(path.Join(streamingOutputDir, fileName))).To(Equal(readCSV(path.Join(inMemoryOutputDir, fileName))), fileName)
+		}
+	})
+
+	It("writes rows that fail validation to the rejected rows file and processes the rest", func() {
+		rows := readCSV(pathToDOJ)
+		header, dataRows := rows[0], rows[1:]
+
+		withValue := func(row []string, column int, value string) []string {
+			badRow := append([]string{}, row...)
+			badRow[column] = value
+			return badRow
+		}
+		badDate := withValue(dataRows[0], data.STP_EVENT_DATE, "19791340")
+		badCountOrder := withValue(dataRows[0], data.CNT_ORDER, "1010")
+		shortRow := dataRows[0][:40]
+
+		var inputRows [][]string
+		inputRows = append(inputRows, header)
+		inputRows = append(inputRows, dataRows[0:6]...)
+		inputRows = append(inputRows, badDate, badCountOrder, shortRow)
+		inputRows = append(inputRows, dataRows[6:]...)
+
+		pathToBadDOJ := path.Join(streamingOutputDir, "input.csv")
+		inputFile, err := os.Create(pathToBadDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(csv.NewWriter(inputFile).WriteAll(inputRows)).To(Succeed())
+		inputFile.Close()
+
+		dojReader, err := data.NewDOJReader(pathToBadDOJ, data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows[COUNTY])
+		Expect(err).ToNot(HaveOccurred())
+		defer dojReader.Close()
+		dojWriter, _ := NewDOJWriter(path.Join(streamingOutputDir, "results.csv"))
+		dojCondensedWriter, _ := NewCondensedDOJWriter(path.Join(streamingOutputDir, "condensed.csv"))
+		dojProp64ConvictionsWriter, _ := NewDOJWriter(path.Join(streamingOutputDir, "convictions.csv"))
+		rejectedRowsWriter, _ := NewRejectedRowsWriter(path.Join(streamingOutputDir, "rejected.csv"))
+		eligibilityTraceWriter, _ := NewEligibilityTraceWriter(path.Join(streamingOutputDir, "trace.json"))
+		defer eligibilityTraceWriter.Close()
+		var console bytes.Buffer
+
+		streamingExporter := NewStreamingDataExporter(
+			dojReader,
+			len(inputRows)-1,
+			data.EligibilityFlows[COUNTY],
+			age,
+			yearsConvictionFree,
+			dojWriter,
+			dojCondensedWriter,
+			dojProp64ConvictionsWriter,
+			rejectedRowsWriter,
+			eligibilityTraceWriter,
+			nil,
+			nil,
+			ioutil.Discard,
+			&console,
+			nil)
+		summary, err := streamingExporter.Export(COUNTY, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(console.String()).To(Equal("Processing DOJ Data\n\nComplete...\nRejected 3 rows that failed validation\n"))
+
+		Expect(summary.LineCount).To(Equal(35))
+		Expect(summary.RejectedRowCount).To(Equal(3))
+		Expect(summary.RejectedRowCountByReason).To(Equal(map[string]int{
+			"invalid STP_EVENT_DATE date": 1,
+			"invalid CNT_ORDER":           1,
+			"too few columns":             1,
+		}))
+		Expect(readCSV(path.Join(streamingOutputDir, "results.csv"))).To(HaveLen(36))
+
+		rejectedRows := readCSV(path.Join(streamingOutputDir, "rejected.csv"))
+		Expect(rejectedRows).To(HaveLen(4))
+		Expect(rejectedRows[0][0:3]).To(Equal([]string{"ROW_NUMBER", "REJECTION_REASONS", "RECORD_ID"}))
+		Expect(rejectedRows[1][0:2]).To(Equal([]string{"8", "invalid STP_EVENT_DATE date"}))
+		Expect(rejectedRows[1][2:]).To(Equal(badDate))
+		Expect(rejectedRows[2][0:2]).To(Equal([]string{"9", "invalid CNT_ORDER"}))
+		Expect(rejectedRows[3][0:2]).To(Equal([]string{"10", "too few columns"}))
+		Expect(rejectedRows[3][2:]).To(Equal(shortRow))
+	})
+
+	It("writes the eligibility trace of each conviction to a JSON file", func() {
+		totalRows, err := data.CountDOJRows(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		dojReader, err := data.NewDOJReader(pathToDOJ, data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows[COUNTY])
+		Expect(err).ToNot(HaveOccurred())
+		defer dojReader.Close()
+		dojWriter, _ := NewDOJWriter(path.Join(streamingOutputDir, "results.csv"))
+		dojCondensedWriter, _ := NewCondensedDOJWriter(path.Join(streamingOutputDir, "condensed.csv"))
+		dojProp64ConvictionsWriter, _ := NewDOJWriter(path.Join(streamingOutputDir, "convictions.csv"))
+		rejectedRowsWriter, _ := NewRejectedRowsWriter(path.Join(streamingOutputDir, "rejected.csv"))
+		eligibilityTraceWriter, _ := NewEligibilityTraceWriter(path.Join(streamingOutputDir, "trace.json"))
+
+		streamingExporter := NewStreamingDataExporter(
+			dojReader,
+			totalRows,
+			data.EligibilityFlows[COUNTY],
+			age,
+			yearsConvictionFree,
+			dojWriter,
+			dojCondensedWriter,
+			dojProp64ConvictionsWriter,
+			rejectedRowsWriter,
+			eligibilityTraceWriter,
+			nil,
+			nil,
+			ioutil.Discard,
+			ioutil.Discard,
+			nil)
+		_, err = streamingExporter.Export(COUNTY, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(eligibilityTraceWriter.Close()).To(Succeed())
+
+		traceJSON, err := ioutil.ReadFile(path.Join(streamingOutputDir, "trace.json"))
+		Expect(err).ToNot(HaveOccurred())
+		var entries []struct {
+			SubjectID                string           `json:"subjectId"`
+			CountOrder               string           `json:"countOrder"`
+			EligibilityDetermination string           `json:"eligibilityDetermination"`
+			EligibilityReason        string           `json:"eligibilityReason"`
+			Trace                    []data.TraceStep `json:"trace"`
+		}
+		Expect(json.Unmarshal(traceJSON, &entries)).To(Succeed())
+		Expect(entries).To(HaveLen(16))
+
+		convictions := readCSV(path.Join(streamingOutputDir, "convictions.csv"))[1:]
+		for i, entry := range entries {
+			Expect(entry.SubjectID).To(Equal(convictions[i][data.SUBJECT_ID]))
+			Expect(entry.CountOrder).To(Equal(convictions[i][data.CNT_ORDER]))
+			Expect(entry.EligibilityReason).To(Equal(convictions[i][len(convictions[i])-2]))
+			Expect(entry.Trace).ToNot(BeEmpty())
+			Expect(entry.Trace[0].Node).To(Equal("BeginEligibilityFlow"))
+		}
+	})
+})
@@ -0,0 +1,62 @@
+package exporter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen_pilots/test_fixtures"
+	"io/ioutil"
+	path "path/filepath"
+	"strings"
+	"time"
+)
+
+var _ = Describe("Workbook verification", func() {
+	computeAt := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+
+	// Every county workbook in test_fixtures is a golden file: the pipeline's
+	// results must match it cell by cell. Workbooks are named after the county
+	// whose eligibility flow they test, ex: los_angeles.xlsx.
+	workbooks, _ := path.Glob(path.Join("..", "test_fixtures", "*.xlsx"))
+
+	for _, workbook := range workbooks {
+		workbook := workbook
+		county := strings.ToUpper(strings.Replace(strings.TrimSuffix(path.Base(workbook), ".xlsx"), "_", " ", -1))
+
+		It("matches "+path.Base(workbook), func() {
+			outputDir, err := ioutil.TempDir("/tmp", "gogen_pilots")
+			Expect(err).ToNot(HaveOccurred())
+
+			verification, err := VerifyWorkbook(workbook, outputDir, county, computeAt)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(verification.Mismatches).To(BeEmpty())
+			Expect(verification.RowsCompared).To(HaveKeyWithValue("doj_results_1.csv", BeNumerically(">", 0)))
+		})
+	}
+
+	It("reports each cell that does not match the workbook", func() {
+		outputDir, err := ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		verification, err := VerifyWorkbook(path.Join("..", "test_fixtures", "los_angeles.xlsx"), outputDir, "LOS ANGELES", computeAt.AddDate(2, 0, 0))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(verification.Passed()).To(BeFalse())
+		Expect(verification.RowsCompared).To(Equal(map[string]int{
+			"doj_results_1.csv":             35,
+			"doj_results_condensed_1.csv":   35,
+			"doj_results_convictions_1.csv": 16,
+		}))
+		Expect(verification.Mismatches).To(ContainElement(CellMismatch{
+			File:     "doj_results_1.csv",
+			Row:      33,
+			Column:   "Eligibility Determination",
+			Expected: "Hand Review",
+			Actual:   "Eligible for Dismissal",
+		}))
+	})
+
+	It("fails for workbooks that cannot be opened", func() {
+		_, err := VerifyWorkbook(path.Join("..", "test_fixtures", "missing.xlsx"), "/tmp", "LOS ANGELES", computeAt)
+		Expect(err).To(MatchError(ContainSubstring("cannot open workbook")))
+	})
+})
@@ -0,0 +1,290 @@
+package exporter
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/tealeg/xlsx"
+	"gogen_pilots/data"
+)
+
+// MaxWorkbookDOJRows is the most DOJ rows a file can have for its results to
+// be written to a workbook. The workbook is built in memory, at about 16 KB per
+// DOJ row, until every row has been added, so larger files must use csv
+// output. It is well under the 1,048,576 rows Excel will open in a sheet.
+const MaxWorkbookDOJRows = 50000
+
+const (
+	workbookDateFormat  = "mm/dd/yyyy"
+	workbookYearsFormat = "0.0"
+)
+
+// dojDateHeaders are the DOJ columns holding YYYYMMDD dates, which are written
+// to workbooks as date cells.
+var dojDateHeaders = map[string]bool{
+	"REQ_DOB":        true,
+	"PRI_DOB":        true,
+	"CYC_DATE":       true,
+	"STP_EVENT_DATE": true,
+	"DISP_DATE":      true,
+}
+
+// ResultsWorkbook writes the results of a DOJ file to an Excel workbook, with
+// a sheet each for the full results, the condensed results, the Prop 64
+// convictions and the aggregate statistics. DOJ values are written as text so
+// leading zeros are kept, and dates and counts as date and number cells.
+type ResultsWorkbook struct {
+	file                *xlsx.File
+	filePath            string
+	fullResults         *xlsxSheetWriter
+	condensedResults    *xlsxSheetWriter
+	prop64Convictions   *xlsxSheetWriter
+	aggregateStatistics *xlsx.Sheet
+}
+
+// NewResultsWorkbook creates a workbook whose full results and Prop 64
+// convictions sheets have a column for each of extraColumns after END_OF_REC.
+// totalRows is the number of rows in the DOJ file, or 0 when it is not known
+// up front, in which case the limit is checked as rows are added.
+func NewResultsWorkbook(outputFilePath string, totalRows int, extraColumns ...string) (*ResultsWorkbook, error) {
+	if totalRows > MaxWorkbookDOJRows {
+		return nil, workbookTooLargeError(outputFilePath)
+	}
+
+	// The workbook is only written once every row has been added, so check up
+	// front that the file can be created.
+	outputFile, err := os.Create(outputFilePath)
+	if err != nil {
+		return nil, err
+	}
+	outputFile.Close()
+
+	w := &ResultsWorkbook{file: xlsx.NewFile(), filePath: outputFilePath}
+	w.fullResults, err = newXlsxSheetWriter(w.file, "Full Results", fullResultsHeaders(extraColumns))
+	if err != nil {
+		return nil, err
+	}
+	w.condensedResults, err = newXlsxSheetWriter(w.file, "Condensed Results", append(DojCondensedHeaders, EligiblityHeaders...))
+	if err != nil {
+		return nil, err
+	}
+	w.prop64Convictions, err = newXlsxSheetWriter(w.file, "Prop 64 Convictions", fullResultsHeaders(extraColumns))
+	if err != nil {
+		return nil, err
+	}
+	aggregateStatistics, err := newXlsxSheetWriter(w.file, "Aggregate Statistics", []string{"Section", "Statistic", "Value"})
+	if err != nil {
+		return nil, err
+	}
+	w.aggregateStatistics = aggregateStatistics.sheet
+	return w, nil
+}
+
+func (w *ResultsWorkbook) FullResultsWriter() DOJWriter {
+	return w.fullResults
+}
+
+func (w *ResultsWorkbook) CondensedResultsWriter() DOJWriter {
+	return w.condensedResults
+}
+
+func (w *ResultsWorkbook) Prop64ConvictionsWriter() DOJWriter {
+	return w.prop64Convictions
+}
+
+// checkRowLimit fails once the full results, which have a row for each DOJ
+// row read, grow past MaxWorkbookDOJRows.
+func (w *ResultsWorkbook) checkRowLimit() error {
+	if len(w.fullResults.sheet.Rows)-1 > MaxWorkbookDOJRows {
+		return workbookTooLargeError(w.filePath)
+	}
+	return nil
+}
+
+func workbookTooLargeError(filePath string) error {
+	return fmt.Errorf("cannot write %s: xlsx output is limited to DOJ files of at most %d rows, as workbooks are built in memory: Use --output-format=csv", filePath, MaxWorkbookDOJRows)
+}
+
+// save writes the workbook to its file.
+func (w *ResultsWorkbook) save() error {
+	err := w.checkRowLimit()
+	if err != nil {
+		return err
+	}
+	return w.file.Save(w.filePath)
+}
+
+// writeAggregateStatistics writes the statistics printed to the .out file, one
+// row per statistic.
+func (w *ResultsWorkbook) writeAggregateStatistics(stats aggregateStatistics, startTime time.Time) {
+	sheet := w.aggregateStatistics
+	addRow := func(section string, statistic string) *xlsx.Cell {
+		row := sheet.AddRow()
+		row.AddCell().SetString(section)
+		row.AddCell().SetString(statistic)
+		return row.AddCell()
+	}
+	addCountsByKey := func(section string, format string, counts map[string]int) {
+		for _, key := range getSortedKeys(counts) {
+			addRow(section, fmt.Sprintf(format, key)).SetInt(counts[key])
+		}
+	}
+	addCountsByKeyByKey := func(section string, format string, counts map[string]map[string]int) {
+		outerKeys := make([]string, 0, len(counts))
+		for key := range counts {
+			outerKeys = append(outerKeys, key)
+		}
+		sort.Strings(outerKeys)
+		for _, outerKey := range outerKeys {
+			for _, innerKey := range getSortedKeys(counts[outerKey]) {
+				addRow(section, fmt.Sprintf(format, outerKey, innerKey)).SetInt(counts[outerKey][innerKey])
+			}
+		}
+	}
+
+	section := "Overall summary of DOJ file"
+	addRow(section, "Total rows in DOJ file").SetInt(stats.totalRows)
+	addRow(section, "Rows that failed validation").SetInt(stats.rejectedRows)
+	addRow(section, "Processing time in seconds").SetFloatWithFormat(time.Since(startTime).Seconds(), "0.00")
+	addRow(section, "Total individuals in DOJ file").SetInt(stats.totalIndividuals)
+	addRow(section, "Total convictions in DOJ file").SetInt(stats.totalConvictions)
+	addRow(section, "Convictions in this county").SetInt(stats.totalConvictionsInCounty)
+
+	section = "Prop64 Convictions Overall"
+	addRow(section, "Convictions total").SetInt(sumValues(stats.overallProp64ConvictionsByCodeSection))
+	addCountsByKey(section, "%s convictions total", stats.overallProp64ConvictionsByCodeSection)
+
+	section = "Prop64 Convictions In This County"
+	addRow(section, "Convictions in this county").SetInt(sumValues(stats.prop64ConvictionsInCountyByCodeSection))
+	addCountsByKey(section, "%s convictions in this county", stats.prop64ConvictionsInCountyByCodeSection)
+	setDateCell(addRow(section, "Date of earliest Prop 64 conviction"), stats.earliestProp64ConvictionInCounty)
+	addCountsByKeyByKey(section, "%s convictions that are %s", stats.prop64ConvictionsInCountyByCodeSectionByEligibility)
+
+	addCountsByKeyByKey("Eligibility Reasons", "%s: %s", stats.prop64ConvictionsInCountyByEligibilityByReason)
+
+	section = "Prop64 Related Convictions In This County"
+	addRow(section, "Convictions in this county").SetInt(sumValues(stats.overallRelatedConvictionsByCodeSection))
+	addCountsByKey(section, "%s convictions in this county", stats.overallRelatedConvictionsByCodeSection)
+	addCountsByKeyByKey(section, "%s convictions that are %s", stats.relatedConvictionsInCountyByCodeSectionByEligibility)
+
+	section = "Impact to individuals"
+	addRow(section, "Individuals who currently have a felony on their record").SetInt(stats.individualsWithFelony)
+	addRow(section, "Individuals who currently have convictions on their record").SetInt(stats.individualsWithConviction)
+	addRow(section, "Individuals who currently have convictions on their record in the last 7 years").SetInt(stats.individualsWithConvictionInLast7Years)
+
+	for _, relief := range []struct {
+		section    string
+		statistics reliefStatistics
+	}{
+		{"Eligibility is run as specified for Prop 64 and Related Charges", stats.currentEligibilityChoicesRelief},
+		{"If ALL Prop 64 convictions are dismissed and sealed", stats.dismissAllProp64Relief},
+		{"If all Prop 64 AND related convictions are dismissed and sealed", stats.dismissAllProp64AndRelatedRelief},
+	} {
+		addRow(relief.section, "Individuals who had a felony who will no longer have a felony on their record").SetInt(relief.statistics.noLongerHaveFelony)
+		addRow(relief.section, "Individuals who had convictions who will no longer have any convictions on their record").SetInt(relief.statistics.noLongerHaveConviction)
+		addRow(relief.section, "Individuals who had convictions in the last 7 years who will no longer have any convictions on their record in the last 7 years").SetInt(relief.statistics.noLongerHaveConvictionInLast7Years)
+	}
+}
+
+// xlsxSheetWriter is a DOJWriter that adds rows to a workbook sheet. Rows are
+// only written to disk when the workbook is saved, so Flush and Close do
+// nothing.
+type xlsxSheetWriter struct {
+	sheet       *xlsx.Sheet
+	dateColumns map[int]bool
+}
+
+func newXlsxSheetWriter(file *xlsx.File, sheetName string, headers []string) (*xlsxSheetWriter, error) {
+	sheet, err := file.AddSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	sheet.SheetViews = []xlsx.SheetView{{
+		Pane: &xlsx.Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft", State: "frozen"},
+	}}
+
+	w := &xlsxSheetWriter{sheet: sheet, dateColumns: make(map[int]bool)}
+	row := sheet.AddRow()
+	for i, header := range headers {
+		row.AddCell().SetString(header)
+		if dojDateHeaders[header] {
+			w.dateColumns[i] = true
+		}
+	}
+	return w, nil
+}
+
+func (sw *xlsxSheetWriter) WriteEntryWithEligibilityInfo(entry []string, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	row := sw.writeEntry(entry)
+
+	if info == nil {
+		for i := range EligiblityHeaders {
+			if i == 2 {
+				row.AddCell().SetString(possibleOtherP64Charges)
+			} else {
+				row.AddCell().SetString("")
+			}
+		}
+		return
+	}
+
+	row.AddCell().SetString(info.CaseNumber)
+	row.AddCell().SetInt(info.NumberOfConvictionsOnRecord)
+	row.AddCell().SetString(possibleOtherP64Charges)
+	row.AddCell().SetString(info.Superstrikes)
+	row.AddCell().SetString(info.PC290CodeSections)
+	row.AddCell().SetString(info.PC290Registration)
+	setDateCell(row.AddCell(), info.DateOfConviction)
+	row.AddCell().SetFloatWithFormat(info.YearsSinceThisConviction, workbookYearsFormat)
+	row.AddCell().SetFloatWithFormat(info.YearsSinceMostRecentConviction, workbookYearsFormat)
+	row.AddCell().SetInt(info.NumberOfProp64Convictions)
+	row.AddCell().SetInt(info.NumberOf11357Convictions)
+	row.AddCell().SetInt(info.NumberOf11358Convictions)
+	row.AddCell().SetInt(info.NumberOf11359Convictions)
+	row.AddCell().SetInt(info.NumberOf11360Convictions)
+	row.AddCell().SetString(info.Deceased)
+	row.AddCell().SetString(info.EligibilityDetermination)
+	row.AddCell().SetString(info.EligibilityReason)
+	row.AddCell().SetString(info.TraceSummary())
+}
+
+func (sw *xlsxSheetWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	sw.WriteEntryWithEligibilityInfo(condensedEntry(entry), info, possibleOtherP64Charges)
+}
+
+func (sw *xlsxSheetWriter) Write(line []string) {
+	sw.writeEntry(line)
+}
+
+func (sw *xlsxSheetWriter) Flush() {}
+
+func (sw *xlsxSheetWriter) Close() error {
+	return nil
+}
+
+// writeEntry adds a row of DOJ values. Values in date columns that are not
+// valid dates are kept as text, so nothing in the DOJ file is lost.
+func (sw *xlsxSheetWriter) writeEntry(entry []string) *xlsx.Row {
+	row := sw.sheet.AddRow()
+	for i, value := range entry {
+		cell := row.AddCell()
+		if sw.dateColumns[i] {
+			if date, err := time.Parse("20060102", value); err == nil {
+				setDateCell(cell, date)
+				continue
+			}
+		}
+		cell.SetString(value)
+	}
+	return row
+}
+
+func setDateCell(cell *xlsx.Cell, date time.Time) {
+	if date.IsZero() {
+		cell.SetString("")
+		return
+	}
+	cell.SetDateWithOptions(date, xlsx.DateTimeOptions{Location: time.UTC, ExcelTimeFormat: workbookDateFormat})
+}
@@ -0,0 +1,169 @@
+package exporter_test
+
+import (
+	"encoding/csv"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/data"
+	. "gogen_pilots/exporter"
+	. "gogen_pilots/test_fixtures"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+var _ = Describe("ResultsWorkbook", func() {
+	const COUNTY = "LOS ANGELES"
+
+	var (
+		outputDir      string
+		workbook       *xlsx.File
+		csvResults     [][]string
+		comparisonTime time.Time
+	)
+
+	export := func(pathToDOJ string, dojWriter DOJWriter, condensedWriter DOJWriter, prop64ConvictionsWriter DOJWriter, resultsWorkbook *ResultsWorkbook) {
+		totalRows, err := data.CountDOJRows(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		dojReader, err := data.NewDOJReader(pathToDOJ, data.AutoDetectFormat, data.DefaultDOJSchema, comparisonTime, data.EligibilityFlows[COUNTY])
+		Expect(err).ToNot(HaveOccurred())
+		defer dojReader.Close()
+		rejectedRowsWriter, _ := NewRejectedRowsWriter(path.Join(outputDir, "rejected.csv"))
+		eligibilityTraceWriter, _ := NewEligibilityTraceWriter(path.Join(outputDir, "trace.json"))
+		defer eligibilityTraceWriter.Close()
+
+		streamingExporter := NewStreamingDataExporter(
+			dojReader,
+			totalRows,
+			data.EligibilityFlows[COUNTY],
+			50,
+			10,
+			dojWriter,
+			condensedWriter,
+			prop64ConvictionsWriter,
+			rejectedRowsWriter,
+			eligibilityTraceWriter,
+			nil,
+			resultsWorkbook,
+			ioutil.Discard,
+			ioutil.Discard,
+			nil)
+		_, err = streamingExporter.Export(COUNTY, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		pathToDOJ, _, err := ExtractFullCSVFixtures(path.Join("..", "test_fixtures", "los_angeles.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+		comparisonTime = time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+
+		totalRows, err := data.CountDOJRows(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		resultsWorkbook, err := NewResultsWorkbook(path.Join(outputDir, "results.xlsx"), totalRows)
+		Expect(err).ToNot(HaveOccurred())
+		export(pathToDOJ, resultsWorkbook.FullResultsWriter(), resultsWorkbook.CondensedResultsWriter(), resultsWorkbook.Prop64ConvictionsWriter(), resultsWorkbook)
+		workbook, err = xlsx.OpenFile(path.Join(outputDir, "results.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+
+		dojWriter, _ := NewDOJWriter(path.Join(outputDir, "results.csv"))
+		condensedWriter, _ := NewCondensedDOJWriter(path.Join(outputDir, "condensed.csv"))
+		prop64ConvictionsWriter, _ := NewDOJWriter(path.Join(outputDir, "convictions.csv"))
+		export(pathToDOJ, dojWriter, condensedWriter, prop64ConvictionsWriter, nil)
+		csvFile, err := os.Open(path.Join(outputDir, "results.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		defer csvFile.Close()
+		csvResults, err = csv.NewReader(csvFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	columnOf := func(sheet *xlsx.Sheet, header string) int {
+		for i, cell := range sheet.Rows[0].Cells {
+			if cell.String() == header {
+				return i
+			}
+		}
+		Fail("no column " + header)
+		return -1
+	}
+
+	It("writes a sheet for each results file with a frozen header row", func() {
+		var sheetNames []string
+		for _, sheet := range workbook.Sheets {
+			sheetNames = append(sheetNames, sheet.Name)
+			Expect(sheet.SheetViews).To(HaveLen(1))
+			Expect(sheet.SheetViews[0].Pane.YSplit).To(Equal(1.0))
+			Expect(sheet.SheetViews[0].Pane.State).To(Equal("frozen"))
+		}
+		Expect(sheetNames).To(Equal([]string{"Full Results", "Condensed Results", "Prop 64 Convictions", "Aggregate Statistics"}))
+
+		Expect(workbook.Sheet["Full Results"].Rows).To(HaveLen(36))
+		Expect(workbook.Sheet["Condensed Results"].Rows).To(HaveLen(36))
+		Expect(workbook.Sheet["Prop 64 Convictions"].Rows).To(HaveLen(17))
+		Expect(workbook.Sheet["Condensed Results"].Rows[0].Cells[0].String()).To(Equal("CII_NUMBER"))
+	})
+
+	It("writes DOJ values as text, and dates and counts as typed cells", func() {
+		sheet := workbook.Sheet["Full Results"]
+		subjectID := columnOf(sheet, "SUBJECT_ID")
+		priDOB := columnOf(sheet, "PRI_DOB")
+		convictions := columnOf(sheet, "# of convictions on record")
+		yearsSinceConviction := columnOf(sheet, "Years Since This Conviction")
+		dateOfConviction := columnOf(sheet, "Date of Conviction")
+
+		for i, row := range sheet.Rows[1:] {
+			Expect(row.Cells[subjectID].Type()).To(Equal(xlsx.CellTypeString))
+			Expect(row.Cells[subjectID].String()).To(Equal(csvResults[i+1][subjectID]))
+		}
+
+		row := sheet.Rows[1]
+		Expect(row.Cells[priDOB].NumFmt).To(Equal("mm/dd/yyyy"))
+		dob, err := row.Cells[priDOB].GetTime(false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dob).To(Equal(time.Date(1960, time.March, 14, 0, 0, 0, 0, time.UTC)))
+
+		var typedRows int
+		for i, row := range sheet.Rows[1:] {
+			if csvResults[i+1][dateOfConviction] == "" {
+				Expect(row.Cells[convictions].String()).To(Equal(""))
+				continue
+			}
+			typedRows++
+			Expect(row.Cells[convictions].Type()).To(Equal(xlsx.CellTypeNumeric))
+			Expect(row.Cells[convictions].String()).To(Equal(csvResults[i+1][convictions]))
+			Expect(row.Cells[yearsSinceConviction].NumFmt).To(Equal("0.0"))
+			Expect(row.Cells[dateOfConviction].NumFmt).To(Equal("mm/dd/yyyy"))
+			convictionDate, err := row.Cells[dateOfConviction].GetTime(false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(convictionDate.Format("01/02/2006")).To(Equal(csvResults[i+1][dateOfConviction]))
+		}
+		Expect(typedRows).To(Equal(16))
+	})
+
+	It("writes the aggregate statistics as numbers", func() {
+		statistics := make(map[string]*xlsx.Cell)
+		for _, row := range workbook.Sheet["Aggregate Statistics"].Rows[1:] {
+			statistics[row.Cells[0].String()+": "+row.Cells[1].String()] = row.Cells[2]
+		}
+
+		totalRows := statistics["Overall summary of DOJ file: Total rows in DOJ file"]
+		Expect(totalRows.Type()).To(Equal(xlsx.CellTypeNumeric))
+		Expect(totalRows.Int()).To(Equal(35))
+		Expect(statistics["Overall summary of DOJ file: Total individuals in DOJ file"].Int()).To(Equal(10))
+		Expect(statistics["Prop64 Convictions In This County: 11357 convictions in this county"]).ToNot(BeNil())
+		Expect(statistics["Prop64 Convictions In This County: Date of earliest Prop 64 conviction"].NumFmt).To(Equal("mm/dd/yyyy"))
+	})
+
+	It("refuses DOJ files with more rows than workbooks are limited to before reading them", func() {
+		workbookPath := path.Join(outputDir, "too_large.xlsx")
+		_, err := NewResultsWorkbook(workbookPath, MaxWorkbookDOJRows+1)
+		Expect(err).To(MatchError(ContainSubstring("xlsx output is limited to DOJ files of at most 50000 rows")))
+		Expect(err).To(MatchError(HaveSuffix("Use --output-format=csv")))
+		Expect(workbookPath).ToNot(BeAnExistingFile())
+	})
+})
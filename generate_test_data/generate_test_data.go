@@ -47,7 +47,7 @@ func main() {
 		}
 	}
 
-	testWriter.Flush()
+	testWriter.Close()
 }
 
 func generateHistory() [][]string {
@@ -1,17 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"gogen_pilots/data"
 	"gogen_pilots/exporter"
+	"gogen_pilots/matchers"
 	"gogen_pilots/test_fixtures"
 	"gogen_pilots/utilities"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jessevdk/go-flags"
@@ -23,11 +28,23 @@ var defaultOpts struct{}
 
 type runOpts struct {
 	OutputFolder   string  `long:"outputs" description:"The folder in which to place result files"`
-	DOJFiles       string  `long:"input-doj" description:"The files containing criminal histories from CA DOJ"`
+	DOJFiles       string  `long:"input-doj" description:"The files containing criminal histories from CA DOJ. Use - to stream one file from stdin in a single pass, without a progress total; stdin cannot be a zip archive or be used with --resolve-identities"`
 	ComputeAt      string  `long:"compute-at" description:"The date for which eligibility will be evaluated, ex: 2020-10-31"`
 	FileNameSuffix string  `long:"file-name-suffix" hidden:"true" description:"string to append to file names"`
 	IndividualAge  int `long:"individual-age" hidden:"true" description:"minimum age of individual for record clearance"`
 	YearsConvictionFree  int `long:"years-conviction-free" hidden:"true" description:"years (as a number) since last conviction"`
+	County         string `long:"county" description:"The county whose eligibility flow will be applied, ex: LOS ANGELES (defaults to the name of --eligibility-rules, or LOS ANGELES)"`
+	EligibilityOptions string `long:"eligibility-options" description:"A JSON file of eligibility options to use instead of the county's eligibility flow"`
+	EligibilityRules string `long:"eligibility-rules" description:"A JSON file describing an eligibility decision tree to use instead of the county's eligibility flow"`
+	StatuteCatalog string `long:"statute-catalog" description:"A JSON catalog of superstrike, PC 290 and related charge code sections to use instead of the built-in catalog"`
+	MotionTemplate string `long:"motion-template" description:"A text/template file used to write a motion for each case with a conviction eligible for dismissal or reduction"`
+	ResolveIdentities bool `long:"resolve-identities" description:"Merge the histories of SUBJECT_IDs that share a CII_NUMBER or FBI_NUMBER, and flag probable duplicates on name and date of birth"`
+	Parallelism    int    `long:"parallelism" default:"1" description:"The number of DOJ files to process at the same time"`
+	InputFormat    string `long:"input-format" default:"auto" description:"The format of the DOJ files: auto, csv or dat (fixed-width)"`
+	DOJSchema      string `long:"doj-schema" default:"v1" description:"The column order of DOJ files without a header row, ex: v1"`
+	OutputFormat   string `long:"output-format" default:"csv" description:"The format of the result files: csv, or xlsx for one Excel workbook per DOJ file"`
+	Progress       string `long:"progress" default:"bar" description:"How progress is reported: bar, or json for newline-delimited JSON events"`
+	ProgressFD     int    `long:"progress-fd" default:"2" description:"The file descriptor JSON progress events are written to, 2 being stderr"`
 }
 
 type exportTestCSVOpts struct {
@@ -41,6 +58,10 @@ var opts struct {
 	Version   versionOpts       `command:"version" description:"Print the version"`
 	Run       runOpts           `command:"run" description:"Process an input DOJ file and produce an annotated DOJ data file"`
 	ExportCSV exportTestCSVOpts `command:"export-test-csv" description:"Export example data files from excel fixtures"`
+	Serve     serveOpts         `command:"serve" description:"Serve a local HTTP JSON API for submitting runs and fetching their results"`
+	Diff      diffOpts          `command:"diff" description:"Compare the results of two runs and report the convictions whose eligibility changed"`
+	Verify    verifyOpts        `command:"verify" description:"Run a county workbook's DOJ rows and compare the results with the workbook cell by cell"`
+	Explain   explainOpts       `command:"explain" description:"Print one subject's parsed history and the eligibility of each conviction under every eligibility flow"`
 }
 
 func (r runOpts) Execute(args []string) error {
@@ -50,22 +71,120 @@ func (r runOpts) Execute(args []string) error {
 
 	utilities.SetErrorFileName(utilities.GenerateFileName(r.OutputFolder, "gogen_pilots%s.err", r.FileNameSuffix))
 
-	if r.OutputFolder == "" || r.DOJFiles == "" {
-		utilities.ExitWithError(errors.New("missing required field: Run gogen_pilots --help for more info"), utilities.INVALID_RUN_OPTION_ERROR)
+	progressEvents, err := r.progressEvents()
+	if err != nil {
+		utilities.ExitWithError(err, utilities.INVALID_RUN_OPTION_ERROR)
+	}
+	utilities.SetProgressEvents(progressEvents)
+
+	settings, err := r.runSettings(processingStartTime)
+	if err != nil {
+		optionErr := err.(runOptionError)
+		utilities.ExitWithError(optionErr.err, optionErr.exitCode)
+	}
+	settings.progressEvents = progressEvents
+	progressEvents.RunStarted(settings.inputFiles)
+
+	runSummary, results := r.runFiles(settings, os.Stdout, func(fileIndex int) utilities.ProgressReporter {
+		if progressEvents != nil {
+			return progressEvents.FileReporter(fileIndex)
+		}
+		if r.Parallelism == 1 {
+			return utilities.ProgressBar{Writer: os.Stdout}
+		}
+		return nil
+	})
+
+	var runErrors []error
+	for _, result := range results {
+		if result.err != nil {
+			runErrors = append(runErrors, result.err)
+		}
+	}
+	if len(runErrors) > 0 {
+		utilities.ExitWithErrors(runErrors, utilities.FILE_PROCESSING_ERROR)
 	}
 
-	inputFiles := strings.Split(r.DOJFiles, ",")
+	ExportSummary(runSummary, processingStartTime, r.summaryFilePath())
+	progressEvents.RunFinished(0, r.summaryFilePath())
+	return nil
+}
+
+// progressEvents returns where to write JSON progress events, or nil when
+// progress is shown as a progress bar.
+func (r runOpts) progressEvents() (*utilities.ProgressEvents, error) {
+	switch r.Progress {
+	case "bar":
+		return nil, nil
+	case "json":
+		progressFile := os.NewFile(uintptr(r.ProgressFD), "progress")
+		if progressFile == nil {
+			return nil, fmt.Errorf("invalid --progress-fd %d: Must be an open file descriptor", r.ProgressFD)
+		}
+		if _, err := progressFile.Stat(); err != nil {
+			return nil, fmt.Errorf("invalid --progress-fd %d: Must be an open file descriptor", r.ProgressFD)
+		}
+		return utilities.NewProgressEvents(progressFile), nil
+	default:
+		return nil, fmt.Errorf("invalid --progress %q: Must be bar or json", r.Progress)
+	}
+}
+
+// runOptionError is an invalid run option, with the code the run command
+// exits with for it.
+type runOptionError struct {
+	err      error
+	exitCode int
+}
+
+func (e runOptionError) Error() string {
+	return e.err.Error()
+}
+
+// runSettings checks the run options and loads the files they point to. Any
+// error is a runOptionError.
+func (r runOpts) runSettings(processingStartTime time.Time) (runSettings, error) {
+	invalidRunOption := func(err error) (runSettings, error) {
+		return runSettings{}, runOptionError{err, utilities.INVALID_RUN_OPTION_ERROR}
+	}
+	invalidEligibilityOption := func(err error) (runSettings, error) {
+		return runSettings{}, runOptionError{err, utilities.INVALID_ELIGIBILITY_OPTION_ERROR}
+	}
+
+	if r.OutputFolder == "" || r.DOJFiles == "" {
+		return invalidRunOption(errors.New("missing required field: Run gogen_pilots --help for more info"))
+	}
 
 	computeAtDate := time.Now()
 
 	if r.ComputeAt != "" {
 		computeAtOption, err := time.Parse("2006-01-02", r.ComputeAt)
 		if err != nil {
-			utilities.ExitWithError(errors.New("invalid --compute-at date: Must be a valid date in the format YYYY-MM-DD"), utilities.INVALID_RUN_OPTION_ERROR)
+			return invalidRunOption(errors.New("invalid --compute-at date: Must be a valid date in the format YYYY-MM-DD"))
 		} else {
 			computeAtDate = computeAtOption
 		}
 	}
+
+	inputFormat, err := data.ParseDOJFileFormat(r.InputFormat)
+	if err != nil {
+		return invalidRunOption(fmt.Errorf("invalid --input-format: %v", err))
+	}
+
+	dojSchema, err := data.ParseDOJSchema(r.DOJSchema)
+	if err != nil {
+		return invalidRunOption(fmt.Errorf("invalid --doj-schema: %v", err))
+	}
+
+	outputFormat := strings.ToLower(strings.TrimSpace(r.OutputFormat))
+	if outputFormat != "csv" && outputFormat != "xlsx" {
+		return invalidRunOption(fmt.Errorf("invalid --output-format %q: Must be csv or xlsx", r.OutputFormat))
+	}
+
+	if r.Parallelism < 1 {
+		return invalidRunOption(fmt.Errorf("invalid --parallelism %d: Must be at least 1", r.Parallelism))
+	}
+
 	var age int
 
 	if r.IndividualAge != 0 {
@@ -80,77 +199,384 @@ func (r runOpts) Execute(args []string) error {
 		yearsConvictionFree = 10
 	}
 
+	if r.EligibilityOptions != "" && r.EligibilityRules != "" {
+		return invalidRunOption(errors.New("--eligibility-options and --eligibility-rules cannot be used together"))
+	}
+
+	var eligibilityRules data.EligibilityRules
+	if r.EligibilityRules != "" {
+		eligibilityRules, err = data.LoadEligibilityRules(r.EligibilityRules)
+		if err != nil {
+			return invalidEligibilityOption(fmt.Errorf("invalid --eligibility-rules: %v", err))
+		}
+	}
+
+	// The county of a rules file is its name. Counties with eligibility options
+	// or rules need no built-in flow: the county only selects the convictions
+	// that are evaluated and is recorded in the summary.
+	county := strings.ToUpper(strings.TrimSpace(r.County))
+	rulesCounty := strings.ToUpper(strings.TrimSpace(eligibilityRules.Name))
+	if county == "" {
+		county = rulesCounty
+	}
+	if county == "" {
+		county = "LOS ANGELES"
+	}
+	if rulesCounty != "" && county != rulesCounty {
+		return invalidRunOption(fmt.Errorf("invalid --county %q: Must be %s, the name of the --eligibility-rules", r.County, rulesCounty))
+	}
+
 	var countyEligibilityFlow data.EligibilityFlow
+	switch {
+	case r.EligibilityRules != "":
+		countyEligibilityFlow, err = data.NewRulesEligibilityFlow(eligibilityRules)
+		if err != nil {
+			return invalidEligibilityOption(fmt.Errorf("invalid --eligibility-rules: %v", err))
+		}
+	case r.EligibilityOptions != "":
+		eligibilityOptions, err := data.LoadEligibilityOptions(r.EligibilityOptions)
+		if err != nil {
+			return invalidEligibilityOption(fmt.Errorf("invalid --eligibility-options: %v", err))
+		}
+		// Thresholds left off in the options are filled from --individual-age and
+		// --years-conviction-free when they are given, so the summary reports the
+		// thresholds the flow applies. Thresholds left unset are recorded as 0.
+		relief := &eligibilityOptions.AdditionalRelief
+		if relief.SubjectAgeThreshold == 0 {
+			relief.SubjectAgeThreshold = r.IndividualAge
+		}
+		if relief.YearsCrimeFreeThreshold == 0 {
+			relief.YearsCrimeFreeThreshold = r.YearsConvictionFree
+		}
+		age = relief.SubjectAgeThreshold
+		yearsConvictionFree = relief.YearsCrimeFreeThreshold
+		countyEligibilityFlow, err = data.NewConfigurableEligibilityFlow(eligibilityOptions)
+		if err != nil {
+			return invalidEligibilityOption(fmt.Errorf("invalid --eligibility-options: %v", err))
+		}
+	default:
+		flow, ok := data.CountyEligibilityFlows[county]
+		if !ok {
+			return invalidRunOption(fmt.Errorf("invalid --county %q: Must be one of the registered counties: %s", r.County, strings.Join(data.CountyNames(), ", ")))
+		}
+		countyEligibilityFlow = flow
+	}
 
-	countyEligibilityFlow = data.EligibilityFlows["LOS ANGELES"]
+	statuteCatalog := matchers.DefaultStatuteCatalog()
+	if r.StatuteCatalog != "" {
+		statuteCatalog, err = matchers.LoadStatuteCatalog(r.StatuteCatalog)
+		if err != nil {
+			return invalidEligibilityOption(fmt.Errorf("invalid --statute-catalog: %v", err))
+		}
+	}
+
+	var motionTemplate *exporter.MotionTemplate
+	if r.MotionTemplate != "" {
+		loadedTemplate, err := exporter.LoadMotionTemplate(r.MotionTemplate)
+		if err != nil {
+			return invalidRunOption(fmt.Errorf("invalid --motion-template: %v", err))
+		}
+		motionTemplate = &loadedTemplate
+	}
+
+	inputFiles, err := data.ExpandDOJFiles(strings.Split(r.DOJFiles, ","))
+	if err != nil {
+		return runSettings{}, runOptionError{fmt.Errorf("invalid --input-doj: %v", err), utilities.FILE_PROCESSING_ERROR}
+	}
+
+	stdinFiles := 0
+	for _, inputFile := range inputFiles {
+		if inputFile == data.StdinFileName {
+			stdinFiles++
+		}
+	}
+	if stdinFiles > 1 {
+		return invalidRunOption(fmt.Errorf("invalid --input-doj: %s can only be given once", data.StdinFileName))
+	}
+	if stdinFiles > 0 && r.ResolveIdentities {
+		return invalidRunOption(fmt.Errorf("--resolve-identities cannot be used with --input-doj=%s: Stdin is read in a single pass", data.StdinFileName))
+	}
+
+	return runSettings{
+		inputFiles:          inputFiles,
+		county:              county,
+		inputFormat:         inputFormat,
+		dojSchema:           dojSchema,
+		outputFormat:        outputFormat,
+		eligibilityFlow:     countyEligibilityFlow,
+		computeAtDate:       computeAtDate,
+		age:                 age,
+		yearsConvictionFree: yearsConvictionFree,
+		statuteCatalog:      statuteCatalog,
+		resolveIdentities:   r.ResolveIdentities,
+		motionTemplate:      motionTemplate,
+		processingStartTime: processingStartTime,
+	}, nil
+}
+
+type runSettings struct {
+	inputFiles          []string
+	county              string
+	inputFormat         data.DOJFileFormat
+	dojSchema           data.DOJSchema
+	outputFormat        string
+	eligibilityFlow     data.EligibilityFlow
+	computeAtDate       time.Time
+	age                 int
+	yearsConvictionFree int
+	statuteCatalog      *matchers.StatuteCatalog
+	resolveIdentities   bool
+	motionTemplate      *exporter.MotionTemplate
+	progressEvents      *utilities.ProgressEvents
+	processingStartTime time.Time
+}
+
+// runFiles processes the input files and returns the summary of the files that
+// were processed without errors. The statute catalog is shared by the whole
+// process, so only one run can be processed at a time.
+func (r runOpts) runFiles(settings runSettings, console io.Writer, newProgressReporter func(fileIndex int) utilities.ProgressReporter) (exporter.Summary, []*fileResult) {
+	matchers.UseStatuteCatalog(settings.statuteCatalog)
 
-	var runErrors []error
 	runSummary := exporter.Summary{
-		County: "LOS ANGELES",
-		IndividualDismissAge:age,
-		YearsConvictionFree: yearsConvictionFree,
+		County: settings.county,
+		IndividualDismissAge:settings.age,
+		YearsConvictionFree: settings.yearsConvictionFree,
+		StatuteCatalogVersion: settings.statuteCatalog.Version,
 	}
-	outputJsonFilePath := utilities.GenerateFileName(r.OutputFolder, "gogen_pilots%s.json", r.FileNameSuffix)
 
-	for fileIndex, inputFile := range inputFiles {
-		fileIndex = fileIndex + 1
-		fileOutputFolder := utilities.GenerateIndexedOutputFolder(r.OutputFolder, fileIndex, r.FileNameSuffix)
-		err := os.MkdirAll(fileOutputFolder, os.ModePerm)
-		if err != nil {
-			runErrors = append(runErrors, err)
-			continue
+	results := r.processInputFiles(settings.inputFiles, settings, console, newProgressReporter)
+	for _, result := range results {
+		if result.err == nil {
+			runSummary = exporter.AccumulateSummaryData(runSummary, result.summary)
 		}
-		dojInformation, err := data.NewDOJInformation(inputFile, computeAtDate, countyEligibilityFlow)
+	}
+	return runSummary, results
+}
+
+func (r runOpts) summaryFilePath() string {
+	return utilities.GenerateFileName(r.OutputFolder, "gogen_pilots%s.json", r.FileNameSuffix)
+}
+
+type fileResult struct {
+	summary      exporter.Summary
+	err          error
+	outputFolder string
+	console      bytes.Buffer
+	done         chan struct{}
+}
+
+// processInputFiles processes up to --parallelism files at a time. Results are
+// returned in the order of inputFiles, so summaries accumulate the same way
+// however the work is scheduled. When files are processed in parallel, each
+// file's console output is held until the files before it have been printed.
+func (r runOpts) processInputFiles(inputFiles []string, settings runSettings, console io.Writer, newProgressReporter func(fileIndex int) utilities.ProgressReporter) []*fileResult {
+	results := make([]*fileResult, len(inputFiles))
+	for i := range results {
+		results[i] = &fileResult{
+			outputFolder: utilities.GenerateIndexedOutputFolder(r.OutputFolder, i+1, r.FileNameSuffix),
+			done:         make(chan struct{}),
+		}
+	}
+
+	if r.Parallelism == 1 {
+		for i, inputFile := range inputFiles {
+			results[i].summary, results[i].err = r.processInputFile(i+1, inputFile, settings, console, newProgressReporter(i+1))
+			settings.progressEvents.FileFinished(i+1, inputFile, results[i].err)
+		}
+		return results
+	}
+
+	fileIndexes := make(chan int)
+	var workers sync.WaitGroup
+	for worker := 0; worker < r.Parallelism && worker < len(inputFiles); worker++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range fileIndexes {
+				result := results[i]
+				result.summary, result.err = r.processInputFile(i+1, inputFiles[i], settings, &result.console, newProgressReporter(i+1))
+				settings.progressEvents.FileFinished(i+1, inputFiles[i], result.err)
+				close(result.done)
+			}
+		}()
+	}
+	go func() {
+		for i := range inputFiles {
+			fileIndexes <- i
+		}
+		close(fileIndexes)
+	}()
+
+	for i, result := range results {
+		<-result.done
+		fmt.Fprintf(console, "DOJ file %d of %d: %s\n", i+1, len(inputFiles), inputFiles[i])
+		result.console.WriteTo(console)
+	}
+	workers.Wait()
+	return results
+}
+
+func (r runOpts) processInputFile(fileIndex int, inputFile string, settings runSettings, console io.Writer, progressReporter utilities.ProgressReporter) (exporter.Summary, error) {
+	fileOutputFolder := utilities.GenerateIndexedOutputFolder(r.OutputFolder, fileIndex, r.FileNameSuffix)
+	err := os.MkdirAll(fileOutputFolder, os.ModePerm)
+	if err != nil {
+		return exporter.Summary{}, err
+	}
+	// Stdin can only be read once, so its rows are not counted up front and
+	// progress is reported without a total.
+	totalRows := 0
+	if inputFile != data.StdinFileName {
+		settings.progressEvents.PhaseStarted(fileIndex, utilities.PhaseCountRows)
+		totalRows, err = data.CountDOJRows(inputFile)
 		if err != nil {
-			runErrors = append(runErrors, err)
-			continue
+			return exporter.Summary{}, err
 		}
-		countyEligibilities := dojInformation.DetermineEligibility("LOS ANGELES", countyEligibilityFlow, age, yearsConvictionFree)
+		settings.progressEvents.PhaseFinished(fileIndex, utilities.PhaseCountRows)
+	}
+	dojReader, err := data.NewDOJReader(inputFile, settings.inputFormat, settings.dojSchema, settings.computeAtDate, settings.eligibilityFlow)
+	if err != nil {
+		return exporter.Summary{}, err
+	}
+	defer dojReader.Close()
 
-		dismissAllProp64Eligibilities := dojInformation.DetermineEligibility("LOS ANGELES", data.EligibilityFlows["DISMISS ALL PROP 64"], age, yearsConvictionFree)
-		dismissAllProp64AndRelatedEligibilities := dojInformation.DetermineEligibility("LOS ANGELES", data.EligibilityFlows["DISMISS ALL PROP 64 AND RELATED"], age, yearsConvictionFree)
+	if settings.resolveIdentities {
+		settings.progressEvents.PhaseStarted(fileIndex, utilities.PhaseResolveIdentities)
+		err = r.resolveIdentities(dojReader, fileIndex, inputFile, fileOutputFolder, settings, console)
+		if err != nil {
+			return exporter.Summary{}, err
+		}
+		settings.progressEvents.PhaseFinished(fileIndex, utilities.PhaseResolveIdentities)
+	}
 
+	eligibilityTraceFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "doj_eligibility_trace_%d%s.json", fileIndex, r.FileNameSuffix)
+	rejectedRowsFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "doj_rejected_rows_%d%s.csv", fileIndex, r.FileNameSuffix)
+	outputFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "gogen_pilots_%d%s.out", fileIndex, r.FileNameSuffix)
+
+	// Outputs are closed once the file is processed, or fails, so the serve
+	// command does not run out of file descriptors over many runs.
+	var outputs []io.Closer
+	defer func() {
+		closeOutputs(outputs)
+	}()
+
+	var dojWriter, condensedDojWriter, prop64ConvictionsDojWriter exporter.DOJWriter
+	var resultsWorkbook *exporter.ResultsWorkbook
+	if settings.outputFormat == "xlsx" {
+		workbookFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "doj_results_%d%s.xlsx", fileIndex, r.FileNameSuffix)
+		resultsWorkbook, err = exporter.NewResultsWorkbook(workbookFilePath, totalRows, dojReader.ExtraColumns()...)
+		if err != nil {
+			return exporter.Summary{}, err
+		}
+		dojWriter = resultsWorkbook.FullResultsWriter()
+		condensedDojWriter = resultsWorkbook.CondensedResultsWriter()
+		prop64ConvictionsDojWriter = resultsWorkbook.Prop64ConvictionsWriter()
+	} else {
 		dojFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "doj_results_%d%s.csv", fileIndex, r.FileNameSuffix)
 		condensedFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "doj_results_condensed_%d%s.csv", fileIndex, r.FileNameSuffix)
 		prop64ConvictionsFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "doj_results_convictions_%d%s.csv", fileIndex, r.FileNameSuffix)
-		outputFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "gogen_pilots_%d%s.out", fileIndex, r.FileNameSuffix)
 
-		dojWriter, err := exporter.NewDOJWriter(dojFilePath)
+		dojWriter, err = exporter.NewDOJWriter(dojFilePath, dojReader.ExtraColumns()...)
+		if err != nil {
+			return exporter.Summary{}, err
+		}
+		outputs = append(outputs, dojWriter)
+		condensedDojWriter, err = exporter.NewCondensedDOJWriter(condensedFilePath)
 		if err != nil {
-			runErrors = append(runErrors, err)
-			continue
+			return exporter.Summary{}, err
 		}
-		condensedDojWriter, err := exporter.NewCondensedDOJWriter(condensedFilePath)
+		outputs = append(outputs, condensedDojWriter)
+		prop64ConvictionsDojWriter, err = exporter.NewDOJWriter(prop64ConvictionsFilePath, dojReader.ExtraColumns()...)
 		if err != nil {
-			runErrors = append(runErrors, err)
-			continue
+			return exporter.Summary{}, err
 		}
-		prop64ConvictionsDojWriter, err := exporter.NewDOJWriter(prop64ConvictionsFilePath)
+		outputs = append(outputs, prop64ConvictionsDojWriter)
+	}
+	rejectedRowsWriter, err := exporter.NewRejectedRowsWriter(rejectedRowsFilePath, dojReader.ExtraColumns()...)
+	if err != nil {
+		return exporter.Summary{}, err
+	}
+	outputs = append(outputs, rejectedRowsWriter)
+	eligibilityTraceWriter, err := exporter.NewEligibilityTraceWriter(eligibilityTraceFilePath)
+	if err != nil {
+		return exporter.Summary{}, err
+	}
+	outputs = append(outputs, eligibilityTraceWriter)
+	aggregateFileStatsWriter, err := utilities.NewOutputWriter(outputFilePath, console)
+	if err != nil {
+		return exporter.Summary{}, err
+	}
+	outputs = append(outputs, aggregateFileStatsWriter)
+	var motionWriter *exporter.MotionWriter
+	if settings.motionTemplate != nil {
+		motionsFolder := path.Join(fileOutputFolder, "motions")
+		motionsIndexFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "doj_motions_index_%d%s.csv", fileIndex, r.FileNameSuffix)
+		motionWriter, err = exporter.NewMotionWriter(*settings.motionTemplate, motionsFolder, motionsIndexFilePath)
 		if err != nil {
-			runErrors = append(runErrors, err)
-			continue
+			return exporter.Summary{}, err
 		}
-		aggregateFileStatsWriter := utilities.GetOutputWriter(outputFilePath)
+		outputs = append(outputs, motionWriter)
+	}
 
-		dataExporter := exporter.NewDataExporter(
-			dojInformation,
-			countyEligibilities,
-			dismissAllProp64Eligibilities,
-			dismissAllProp64AndRelatedEligibilities,
-			dojWriter,
-			condensedDojWriter,
-			prop64ConvictionsDojWriter,
-			aggregateFileStatsWriter)
+	dataExporter := exporter.NewStreamingDataExporter(
+		dojReader,
+		totalRows,
+		settings.eligibilityFlow,
+		settings.age,
+		settings.yearsConvictionFree,
+		dojWriter,
+		condensedDojWriter,
+		prop64ConvictionsDojWriter,
+		rejectedRowsWriter,
+		eligibilityTraceWriter,
+		motionWriter,
+		resultsWorkbook,
+		aggregateFileStatsWriter,
+		console,
+		progressReporter)
+
+	settings.progressEvents.PhaseStarted(fileIndex, utilities.PhaseProcessSubjects)
+	fileSummary, err := dataExporter.Export(settings.county, settings.processingStartTime)
+	if err != nil {
+		return exporter.Summary{}, err
+	}
+	err = closeOutputs(outputs)
+	outputs = nil
+	if err != nil {
+		return exporter.Summary{}, err
+	}
+	settings.progressEvents.PhaseFinished(fileIndex, utilities.PhaseProcessSubjects)
+	return fileSummary, nil
+}
 
-		fileSummary := dataExporter.Export("LOS ANGELES", processingStartTime)
-		runSummary = dataExporter.AccumulateSummaryData(runSummary, fileSummary)
+// closeOutputs closes every output, returning the first error.
+func closeOutputs(outputs []io.Closer) error {
+	var firstErr error
+	for _, output := range outputs {
+		if err := output.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
+	return firstErr
+}
 
-	if len(runErrors) > 0 {
-		utilities.ExitWithErrors(runErrors, utilities.FILE_PROCESSING_ERROR)
+func (r runOpts) resolveIdentities(dojReader *data.DOJReader, fileIndex int, inputFile string, fileOutputFolder string, settings runSettings, console io.Writer) error {
+	identities, err := data.ResolveIdentities(inputFile, settings.inputFormat, settings.dojSchema)
+	if err != nil {
+		return err
 	}
+	dojReader.ResolveIdentities(identities)
 
-	ExportSummary(runSummary, processingStartTime, outputJsonFilePath)
+	identityReportFilePath := utilities.GenerateIndexedFileName(fileOutputFolder, "doj_identity_report_%d%s.csv", fileIndex, r.FileNameSuffix)
+	identityReportWriter, err := exporter.NewIdentityReportWriter(identityReportFilePath)
+	if err != nil {
+		return err
+	}
+	exporter.WriteIdentityReport(identityReportWriter, identities)
+	err = identityReportWriter.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(console, "Merged %d SUBJECT_IDs into other identities and flagged %d probable duplicates for review\n", identities.MergedSubjectCount(), len(identities.ProbableDuplicates))
 	return nil
 }
 
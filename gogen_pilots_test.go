@@ -1,14 +1,20 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"github.com/onsi/gomega/gstruct"
 	"gogen_pilots/exporter"
 	"io/ioutil"
+	"os"
 	"os/exec"
 	path "path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	. "gogen_pilots/test_fixtures"
@@ -17,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
+	"github.com/tealeg/xlsx"
 )
 
 func GetOutputSummary(filePath string) exporter.Summary {
@@ -84,195 +91,965 @@ var _ = Describe("gogen_pilots", func() {
 		Expect(summary.LineCount).To(Equal(38))
 	})
 
+	It("reads columns by the names in the header row and carries extra columns through to the full results", func() {
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		extraCommaFile, err := os.Open(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		extraCommaReader := csv.NewReader(extraCommaFile)
+		extraCommaReader.FieldsPerRecord = -1
+		rows, err := extraCommaReader.ReadAll()
+		extraCommaFile.Close()
+		Expect(err).ToNot(HaveOccurred())
+		lastColumn := len(rows[0]) - 1
+		rows[0][lastColumn] = "COUNTY_NOTE"
+		for i := 1; i < len(rows); i++ {
+			rows[i] = append(rows[i][:lastColumn], fmt.Sprintf("note %d", i))
+		}
+		pathToDOJ = path.Join(outputDir, "extra_column.csv")
+		extraColumnFile, err := os.Create(pathToDOJ)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(csv.NewWriter(extraColumnFile).WriteAll(rows)).To(Succeed())
+		extraColumnFile.Close()
+
+		pathToBadDOJ, err := path.Abs(path.Join("test_fixtures", "bad.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s,%s", pathToDOJ, pathToBadDOJ)
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, "--compute-at=2019-11-11")
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+		Expect(summary.LineCount).To(Equal(76))
+		Expect(summary.RejectedRowCount).To(Equal(0))
+
+		results, err := ioutil.ReadFile(path.Join(outputDir, "DOJ_Input_File_1_Results", "doj_results_1.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(results)).To(ContainSubstring(",COMMENT_TEXT,END_OF_REC,COUNTY_NOTE,Case Number,"))
+		Expect(string(results)).To(ContainSubstring(",note 1,"))
+
+		// bad.csv does not have a RECORD_ID column, so read by position each
+		// of its values would be one column to the left.
+		results, err = ioutil.ReadFile(path.Join(outputDir, "DOJ_Input_File_2_Results", "doj_results_2.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(results)).To(ContainSubstring("\n,,18675309,"))
+	})
+
+	It("fails and reports errors for DOJ files missing required columns", func() {
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ = path.Join(outputDir, "missing_columns.csv")
+		Expect(ioutil.WriteFile(pathToDOJ, []byte("SUBJECT_ID,PRI_NAME,PRI_DOB,STP_EVENT_DATE,CNT_ORDER,COMMENT_TEXT\n"), 0644)).To(Succeed())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(2))
+		Expect(session.Err).To(gbytes.Say("missing_columns.csv: missing required columns: STP_TYPE_DESCR, STP_ORI_CNTY_NAME, OFFENSE_DESCR, OFFENSE_TOC, DISP_DESCR, CONV_STAT_DESCR, SENT_LENGTH, SENT_TIME_CODE"))
+
+		command = exec.Command(pathToGogen, "run", outputsFlag, dojFlag, "--doj-schema=v0")
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(3))
+		Expect(session.Err).To(gbytes.Say(`invalid --doj-schema: unknown DOJ schema "v0": Must be one of v1`))
+	})
+
+	It("can handle a fixed-width DOJ file", func() {
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runFile := func(fileName string, extraFlags ...string) exporter.Summary {
+			outputDir, err := ioutil.TempDir("/tmp", "gogen_pilots")
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToDOJ, err := path.Abs(path.Join("test_fixtures", fileName))
+			Expect(err).ToNot(HaveOccurred())
+
+			runCommand := "run"
+			outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+			dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+			computeAtFlag := "--compute-at=2019-11-11"
+
+			args := append([]string{runCommand, outputsFlag, dojFlag, computeAtFlag}, extraFlags...)
+			command := exec.Command(pathToGogen, args...)
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(0))
+			summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+			summary.ProcessingTimeInSeconds = 0
+			return summary
+		}
+
+		csvSummary := runFile("no_headers.csv")
+		Expect(runFile("fixed_width.dat", "--input-format=dat")).To(Equal(csvSummary))
+		Expect(runFile("fixed_width.dat")).To(Equal(csvSummary))
+	})
+
+	It("validates the input format option", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "fixed_width.dat"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		inputFormatFlag := "--input-format=xlsx"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, inputFormatFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(3))
+		Eventually(session.Err).Should(gbytes.Say(regexp.QuoteMeta(`invalid --input-format: unknown DOJ file format "xlsx": Must be one of auto, csv or dat`)))
+	})
+
 	It("can accept a compute-at option for determining eligibility", func() {
 
 		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
 
-		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
-		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+		computeAtFlag := "--compute-at=2019-11-11"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Err).ToNot(gbytes.Say("required"))
+		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+		Expect(summary.LineCount).To(Equal(35))
+	})
+
+	It("can accept a suffix for the output file names", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		dateSuffix := "Feb_8_2019_3.32.43.PM"
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		computeAtFlag := "--compute-at=2019-11-11"
+		dateTimeFlag := fmt.Sprintf("--file-name-suffix=%s", dateSuffix)
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, dateTimeFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		fileResultsOutputDir := path.Join(outputDir, fmt.Sprintf("DOJ_Input_File_1_Results_%s", dateSuffix))
+		expectedDojResultsFileName := fmt.Sprintf("%v/doj_results_1_%s.csv", fileResultsOutputDir, dateSuffix)
+		expectedCondensedFileName := fmt.Sprintf("%v/doj_results_condensed_1_%s.csv", fileResultsOutputDir, dateSuffix)
+		expectedConvictionsFileName := fmt.Sprintf("%v/doj_results_convictions_1_%s.csv", fileResultsOutputDir, dateSuffix)
+		expectedRejectedRowsFileName := fmt.Sprintf("%v/doj_rejected_rows_1_%s.csv", fileResultsOutputDir, dateSuffix)
+		expectedEligibilityTraceFileName := fmt.Sprintf("%v/doj_eligibility_trace_1_%s.json", fileResultsOutputDir, dateSuffix)
+		expectedOutputFileName := fmt.Sprintf("%v/gogen_pilots_1_%s.out", fileResultsOutputDir, dateSuffix)
+		expectedJsonOutputFileName := fmt.Sprintf("%v/gogen_pilots_%s.json", outputDir, dateSuffix)
+
+		Ω(expectedDojResultsFileName).Should(BeAnExistingFile())
+		Ω(expectedCondensedFileName).Should(BeAnExistingFile())
+		Ω(expectedConvictionsFileName).Should(BeAnExistingFile())
+		Ω(expectedRejectedRowsFileName).Should(BeAnExistingFile())
+		Ω(expectedEligibilityTraceFileName).Should(BeAnExistingFile())
+		Ω(expectedOutputFileName).Should(BeAnExistingFile())
+		Ω(expectedJsonOutputFileName).Should(BeAnExistingFile())
+	})
+	It("can accept an age for a participant to determine eligibility", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+		var age float64
+		age = 40
+		ageFlag := fmt.Sprintf("--individual-age=%v", age)
+		computeAtFlag := "--compute-at=2019-11-11"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, ageFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit())
+		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+		Expect(summary).To(gstruct.MatchAllFields(gstruct.Fields{
+			"County":                   Equal("LOS ANGELES"),
+			"IndividualDismissAge":     Equal(40),
+			"YearsConvictionFree":      Equal(10),
+			"StatuteCatalogVersion":    Equal("2019-11-01"),
+			"LineCount":                Equal(35),
+			"RejectedRowCount":         Equal(0),
+			"RejectedRowCountByReason": BeEmpty(),
+			"ProcessingTimeInSeconds":  BeNumerically(">", 0),
+			"EarliestConviction":       Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
+			"ReliefWithCurrentEligibilityChoices": gstruct.MatchAllKeys(gstruct.Keys{
+				"CountSubjectsNoFelony":               Equal(1),
+				"CountSubjectsNoConviction":           Equal(1),
+				"CountSubjectsNoConvictionLast7Years": Equal(0),
+			}),
+			"ReliefWithDismissAllProp64": gstruct.MatchAllKeys(gstruct.Keys{
+				"CountSubjectsNoFelony":               Equal(2),
+				"CountSubjectsNoConviction":           Equal(2),
+				"CountSubjectsNoConvictionLast7Years": Equal(3),
+			}),
+			"Prop64ConvictionsCountInCountyByCodeSection": gstruct.MatchAllKeys(gstruct.Keys{
+				"11357": Equal(3),
+				"11358": Equal(9),
+				"11359": Equal(4),
+			}),
+			"SubjectsWithProp64ConvictionCountInCounty": Equal(10),
+			"Prop64FelonyConvictionsCountInCounty":      Equal(13),
+			"Prop64MisdemeanorConvictionsCountInCounty": Equal(3),
+			"SubjectsWithSomeReliefCount":               Equal(8),
+			"ConvictionDismissalCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
+			"ConvictionReductionCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
+			"ConvictionDismissalCountByAdditionalRelief": gstruct.MatchAllKeys(gstruct.Keys{
+				"21 years or younger": Equal(1),
+				"40 years or older":   Equal(6),
+				"Only has 11357-60 charges and completed sentence": Equal(1),
+				"11357(a) or 11357(b)":                             Equal(1),
+			}),
+			"ConvictionAlreadyRelievedCountByReason": gstruct.MatchAllKeys(gstruct.Keys{}),
+		}))
+	})
+
+	It("can accept a number of years conviction free as a parameter to determine eligibility", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+		var yearsConvictionFree int
+		yearsConvictionFree = 2
+		yearsConvictionFreeFlag := fmt.Sprintf("--years-conviction-free=%v", yearsConvictionFree)
+		computeAtFlag := "--compute-at=2019-11-11"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, yearsConvictionFreeFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit())
+		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+		Expect(summary).To(gstruct.MatchAllFields(gstruct.Fields{
+			"County":                   Equal("LOS ANGELES"),
+			"IndividualDismissAge":     Equal(50),
+			"YearsConvictionFree":      Equal(2),
+			"StatuteCatalogVersion":    Equal("2019-11-01"),
+			"LineCount":                Equal(35),
+			"RejectedRowCount":         Equal(0),
+			"RejectedRowCountByReason": BeEmpty(),
+			"ProcessingTimeInSeconds":  BeNumerically(">", 0),
+			"EarliestConviction":       Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
+			"ReliefWithCurrentEligibilityChoices": gstruct.MatchAllKeys(gstruct.Keys{
+				"CountSubjectsNoFelony":               Equal(2),
+				"CountSubjectsNoConviction":           Equal(2),
+				"CountSubjectsNoConvictionLast7Years": Equal(1),
+			}),
+			"ReliefWithDismissAllProp64": gstruct.MatchAllKeys(gstruct.Keys{
+				"CountSubjectsNoFelony":               Equal(2),
+				"CountSubjectsNoConviction":           Equal(2),
+				"CountSubjectsNoConvictionLast7Years": Equal(3),
+			}),
+			"Prop64ConvictionsCountInCountyByCodeSection": gstruct.MatchAllKeys(gstruct.Keys{
+				"11357": Equal(3),
+				"11358": Equal(9),
+				"11359": Equal(4),
+			}),
+			"SubjectsWithProp64ConvictionCountInCounty": Equal(10),
+			"Prop64FelonyConvictionsCountInCounty":      Equal(13),
+			"Prop64MisdemeanorConvictionsCountInCounty": Equal(3),
+			"SubjectsWithSomeReliefCount":               Equal(9),
+			"ConvictionDismissalCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
+			"ConvictionReductionCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
+			"ConvictionDismissalCountByAdditionalRelief": gstruct.MatchAllKeys(gstruct.Keys{
+				"21 years or younger": Equal(1),
+				"50 years or older":   Equal(5),
+				"Only has 11357-60 charges and completed sentence": Equal(1),
+				"11357(a) or 11357(b)":                             Equal(1),
+				"No convictions in past 2 years":                   Equal(2),
+			}),
+			"ConvictionAlreadyRelievedCountByReason": gstruct.MatchAllKeys(gstruct.Keys{}),
+		}))
+	})
+
+	It("validates required options", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		computeAtFlag := "--compute-at=2019-11-11"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, computeAtFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(3))
+		Eventually(session.Err).Should(gbytes.Say("missing required field: Run gogen_pilots --help for more info"))
+
+		expectedErrorFileName := fmt.Sprintf("%v/gogen_pilots%s.err", outputDir, "")
+
+		Ω(expectedErrorFileName).Should(BeAnExistingFile())
+		data, _ := ioutil.ReadFile(expectedErrorFileName)
+		Expect(string(data)).To(Equal("missing required field: Run gogen_pilots --help for more info\n"))
+	})
+
+	It("validates the county option against the registered counties", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		computeAtFlag := "--compute-at=2019-11-11"
+		countyFlag := "--county=Springfield"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, countyFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(3))
+		Eventually(session.Err).Should(gbytes.Say(regexp.QuoteMeta(`invalid --county "Springfield": Must be one of the registered counties: LOS ANGELES`)))
+
+		command = exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, "--county=DISMISS ALL PROP 64")
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(3))
+		Eventually(session.Err).Should(gbytes.Say(regexp.QuoteMeta(`invalid --county "DISMISS ALL PROP 64": Must be one of the registered counties: LOS ANGELES`)))
+	})
+
+	It("can accept a county option to select the eligibility flow", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+		computeAtFlag := "--compute-at=2019-11-11"
+		countyFlag := "--county=los angeles"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, countyFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+		Expect(summary.County).To(Equal("LOS ANGELES"))
+		Expect(summary.Prop64ConvictionsCountInCountyByCodeSection).To(Equal(map[string]int{
+			"11357": 3,
+			"11358": 9,
+			"11359": 4,
+		}))
+	})
+
+	It("can accept eligibility options to configure the eligibility flow", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+
+		pathToEligibilityOptions, err := path.Abs(path.Join("test_fixtures", "eligibility_options.json"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", pathToEligibilityOptions)
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+		Eventually(session).Should(gbytes.Say("Found 9 convictions with eligibility reason Dismiss all HS 11358 convictions"))
+
+		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+		Expect(summary.IndividualDismissAge).To(Equal(57))
+		Expect(summary.ConvictionDismissalCountByCodeSection).To(Equal(map[string]int{
+			"11357(c)": 2,
+			"11358":    9,
+		}))
+		Expect(summary.ConvictionReductionCountByCodeSection).To(Equal(map[string]int{
+			"11359": 1,
+		}))
+	})
+
+	It("applies eligibility options to a county without a built-in eligibility flow", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+		losAngelesRows, err := ioutil.ReadFile(inputCSV)
+		Expect(err).ToNot(HaveOccurred())
+		pathToDOJ = path.Join(outputDir, "sacramento.csv")
+		Expect(ioutil.WriteFile(pathToDOJ, bytes.Replace(losAngelesRows, []byte("LOS ANGELES"), []byte("SACRAMENTO"), -1), 0644)).To(Succeed())
+
+		optionsFile, err := ioutil.TempFile(outputDir, "eligibility_options")
+		Expect(err).ToNot(HaveOccurred())
+		optionsFile.WriteString(`{"baselineEligibility": {"dismiss": ["11358"], "reduce": ["11359"]}}`)
+		optionsFile.Close()
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		computeAtFlag := "--compute-at=2019-11-11"
+		countyFlag := "--county=sacramento"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", optionsFile.Name())
+		individualAgeFlag := "--individual-age=45"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, countyFlag, eligibilityOptionsFlag, individualAgeFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+		Eventually(session).Should(gbytes.Say("Found 2 convictions with eligibility reason 45 years or older"))
+		Eventually(session).Should(gbytes.Say("Found 9 convictions with eligibility reason Dismiss all HS 11358 convictions"))
+
+		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+		Expect(summary.County).To(Equal("SACRAMENTO"))
+		Expect(summary.IndividualDismissAge).To(Equal(45))
+		Expect(summary.YearsConvictionFree).To(Equal(0))
+		Expect(summary.ConvictionDismissalCountByCodeSection).To(HaveKeyWithValue("11358", 9))
+	})
+
+	It("fails and reports errors for invalid eligibility options", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		optionsFile, err := ioutil.TempFile(outputDir, "eligibility_options")
+		Expect(err).ToNot(HaveOccurred())
+		optionsFile.WriteString(`{"baselineEligibility": {"dismiss": ["11358"], "reduce": ["11358"]}}`)
+		optionsFile.Close()
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", optionsFile.Name())
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(4))
+		Eventually(session.Err).Should(gbytes.Say(`invalid --eligibility-options: code section "11358" cannot be in both the dismiss and reduce lists`))
+	})
+
+	It("can use eligibility rules in place of the county's eligibility flow", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+
+		pathToEligibilityRules, err := path.Abs(path.Join("eligibility_rules", "los_angeles.json"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+		computeAtFlag := "--compute-at=2019-11-11"
+
+		flowOutputsFlag := fmt.Sprintf("--outputs=%s", path.Join(outputDir, "flow"))
+		command := exec.Command(pathToGogen, runCommand, flowOutputsFlag, dojFlag, computeAtFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		rulesOutputsFlag := fmt.Sprintf("--outputs=%s", path.Join(outputDir, "rules"))
+		eligibilityRulesFlag := fmt.Sprintf("--eligibility-rules=%s", pathToEligibilityRules)
+		command = exec.Command(pathToGogen, runCommand, rulesOutputsFlag, dojFlag, computeAtFlag, eligibilityRulesFlag)
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		flowSummary := GetOutputSummary(path.Join(outputDir, "flow", "gogen_pilots.json"))
+		rulesSummary := GetOutputSummary(path.Join(outputDir, "rules", "gogen_pilots.json"))
+		flowSummary.ProcessingTimeInSeconds = 0
+		rulesSummary.ProcessingTimeInSeconds = 0
+		Expect(rulesSummary).To(Equal(flowSummary))
+	})
+
+	It("runs a county's eligibility rules under the county they are named for", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+		losAngelesRows, err := ioutil.ReadFile(inputCSV)
+		Expect(err).ToNot(HaveOccurred())
+		pathToDOJ = path.Join(outputDir, "sacramento.csv")
+		Expect(ioutil.WriteFile(pathToDOJ, bytes.Replace(losAngelesRows, []byte("LOS ANGELES"), []byte("SACRAMENTO"), -1), 0644)).To(Succeed())
+
+		losAngelesRules, err := ioutil.ReadFile(path.Join("eligibility_rules", "los_angeles.json"))
+		Expect(err).ToNot(HaveOccurred())
+		pathToEligibilityRules := path.Join(outputDir, "sacramento.json")
+		Expect(ioutil.WriteFile(pathToEligibilityRules, bytes.Replace(losAngelesRules, []byte(`"name": "LOS ANGELES"`), []byte(`"name": "SACRAMENTO"`), 1), 0644)).To(Succeed())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		computeAtFlag := "--compute-at=2019-11-11"
+
+		flowOutputsFlag := fmt.Sprintf("--outputs=%s", path.Join(outputDir, "flow"))
+		command := exec.Command(pathToGogen, runCommand, flowOutputsFlag, fmt.Sprintf("--input-doj=%s", inputCSV), computeAtFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		rulesOutputsFlag := fmt.Sprintf("--outputs=%s", path.Join(outputDir, "rules"))
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		eligibilityRulesFlag := fmt.Sprintf("--eligibility-rules=%s", pathToEligibilityRules)
+		command = exec.Command(pathToGogen, runCommand, rulesOutputsFlag, dojFlag, computeAtFlag, eligibilityRulesFlag)
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		flowSummary := GetOutputSummary(path.Join(outputDir, "flow", "gogen_pilots.json"))
+		rulesSummary := GetOutputSummary(path.Join(outputDir, "rules", "gogen_pilots.json"))
+		Expect(rulesSummary.County).To(Equal("SACRAMENTO"))
+		flowSummary.County = rulesSummary.County
+		flowSummary.ProcessingTimeInSeconds = 0
+		rulesSummary.ProcessingTimeInSeconds = 0
+		Expect(rulesSummary).To(Equal(flowSummary))
+
+		command = exec.Command(pathToGogen, runCommand, rulesOutputsFlag, dojFlag, computeAtFlag, eligibilityRulesFlag, "--county=LOS ANGELES")
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(3))
+		Eventually(session.Err).Should(gbytes.Say(regexp.QuoteMeta(`invalid --county "LOS ANGELES": Must be SACRAMENTO, the name of the --eligibility-rules`)))
+	})
+
+	It("fails and reports errors for invalid eligibility rules", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		rulesFile, err := ioutil.TempFile(outputDir, "eligibility_rules")
+		Expect(err).ToNot(HaveOccurred())
+		rulesFile.WriteString(`{"relevantCharges": "prop64", "start": "Begin", "nodes": {"Begin": {"condition": {"type": "isFelony"}, "then": "Reduce"}}}`)
+		rulesFile.Close()
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		eligibilityRulesFlag := fmt.Sprintf("--eligibility-rules=%s", rulesFile.Name())
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, eligibilityRulesFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(4))
+		Eventually(session.Err).Should(gbytes.Say(`invalid --eligibility-rules: node "Begin": node "Reduce" is not defined`))
+	})
+
+	It("records the version of the statute catalog in the summary", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		catalogFile, err := ioutil.TempFile(outputDir, "statute_catalog")
+		Expect(err).ToNot(HaveOccurred())
+		catalogFile.WriteString(`{"version": "county-review-2", "superstrikes": [{"citation": "187 PC", "pattern": "187 PC", "effectiveDate": "2012-11-07", "description": "Murder"}]}`)
+		catalogFile.Close()
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		computeAtFlag := "--compute-at=2019-11-11"
+
+		defaultOutputsFlag := fmt.Sprintf("--outputs=%s", path.Join(outputDir, "default"))
+		command := exec.Command(pathToGogen, runCommand, defaultOutputsFlag, dojFlag, computeAtFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		catalogOutputsFlag := fmt.Sprintf("--outputs=%s", path.Join(outputDir, "catalog"))
+		statuteCatalogFlag := fmt.Sprintf("--statute-catalog=%s", catalogFile.Name())
+		command = exec.Command(pathToGogen, runCommand, catalogOutputsFlag, dojFlag, computeAtFlag, statuteCatalogFlag)
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(GetOutputSummary(path.Join(outputDir, "default", "gogen_pilots.json")).StatuteCatalogVersion).To(Equal("2019-11-01"))
+		Expect(GetOutputSummary(path.Join(outputDir, "catalog", "gogen_pilots.json")).StatuteCatalogVersion).To(Equal("county-review-2"))
+	})
+
+	It("merges subjects sharing a CII number and reports the merges when resolving identities", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		inputPath := path.Join("test_fixtures", "los_angeles.xlsx")
+		pathToDOJ, _, err = ExtractFullCSVFixtures(inputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		computeAtFlag := "--compute-at=2019-11-11"
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, "--resolve-identities")
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out).To(gbytes.Say("Merged 3 SUBJECT_IDs into other identities and flagged 0 probable duplicates for review"))
+
+		reportFile, err := os.Open(path.Join(outputDir, "DOJ_Input_File_1_Results", "doj_identity_report_1.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		defer reportFile.Close()
+		report, err := csv.NewReader(reportFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report).To(Equal([][]string{
+			{"ACTION", "RESOLVED_SUBJECT_ID", "SUBJECT_IDS", "MATCHED_ON", "PRI_NAME", "PRI_DOB"},
+			{"MERGED", "18675309", "18675309; 23675654; 14575654", "CII_NUMBER 1008675309", "", ""},
+			{"MERGED", "90675321", "90675321; 95875321", "CII_NUMBER A123456781", "", ""},
+		}))
+
+		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
+		Expect(summary.LineCount).To(Equal(35))
+		Expect(summary.ConvictionDismissalCountByAdditionalRelief).ToNot(HaveKey("Only has 11357-60 charges and completed sentence"))
+	})
+
+	It("writes motions for eligible cases from a motion template", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		inputPath := path.Join("test_fixtures", "los_angeles.xlsx")
+		pathToDOJ, _, err = ExtractFullCSVFixtures(inputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToMotionTemplate, err := path.Abs(path.Join("motion_templates", "prop64_motion.txt"))
+		Expect(err).ToNot(HaveOccurred())
 
 		pathToGogen, err := gexec.Build("gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
 
 		runCommand := "run"
 		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
-		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
 		computeAtFlag := "--compute-at=2019-11-11"
+		motionTemplateFlag := fmt.Sprintf("--motion-template=%s", pathToMotionTemplate)
 
-		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag)
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, motionTemplateFlag)
 		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
 		Expect(err).ToNot(HaveOccurred())
 
 		Eventually(session).Should(gexec.Exit(0))
-		Expect(session.Err).ToNot(gbytes.Say("required"))
-		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
-		Expect(summary.LineCount).To(Equal(35))
+		Expect(session.Out).To(gbytes.Say("Wrote 7 motions"))
+
+		fileOutputDir := path.Join(outputDir, "DOJ_Input_File_1_Results")
+		motions, err := ioutil.ReadDir(path.Join(fileOutputDir, "motions"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(motions).To(HaveLen(7))
+
+		motion, err := ioutil.ReadFile(path.Join(fileOutputDir, "motions", "motion_14575654_101001.txt"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(motion)).To(ContainSubstring("VONWINKLE,BERT,"))
+		Expect(string(motion)).To(ContainSubstring("  - 11359 HS, convicted 04/11/1991 (felony)"))
+
+		indexFile, err := os.Open(path.Join(fileOutputDir, "doj_motions_index_1.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		defer indexFile.Close()
+		index, err := csv.NewReader(indexFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(index).To(HaveLen(8))
 	})
 
-	It("can accept a suffix for the output file names", func() {
+	It("writes the results to an Excel workbook", func() {
 
 		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
 
-		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		inputPath := path.Join("test_fixtures", "los_angeles.xlsx")
+		pathToDOJ, _, err = ExtractFullCSVFixtures(inputPath)
 		Expect(err).ToNot(HaveOccurred())
 
 		pathToGogen, err := gexec.Build("gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
-		dateSuffix := "Feb_8_2019_3.32.43.PM"
 
 		runCommand := "run"
 		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
 		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
 		computeAtFlag := "--compute-at=2019-11-11"
-		dateTimeFlag := fmt.Sprintf("--file-name-suffix=%s", dateSuffix)
+		outputFormatFlag := "--output-format=xlsx"
 
-		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, dateTimeFlag)
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, outputFormatFlag)
 		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
 		Expect(err).ToNot(HaveOccurred())
 
 		Eventually(session).Should(gexec.Exit(0))
 
-		fileResultsOutputDir := path.Join(outputDir, fmt.Sprintf("DOJ_Input_File_1_Results_%s", dateSuffix))
-		expectedDojResultsFileName := fmt.Sprintf("%v/doj_results_1_%s.csv", fileResultsOutputDir, dateSuffix)
-		expectedCondensedFileName := fmt.Sprintf("%v/doj_results_condensed_1_%s.csv", fileResultsOutputDir, dateSuffix)
-		expectedConvictionsFileName := fmt.Sprintf("%v/doj_results_convictions_1_%s.csv", fileResultsOutputDir, dateSuffix)
-		expectedOutputFileName := fmt.Sprintf("%v/gogen_pilots_1_%s.out", fileResultsOutputDir, dateSuffix)
-		expectedJsonOutputFileName := fmt.Sprintf("%v/gogen_pilots_%s.json", outputDir, dateSuffix)
+		fileOutputDir := path.Join(outputDir, "DOJ_Input_File_1_Results")
+		Expect(path.Join(fileOutputDir, "doj_results_1.csv")).ToNot(BeAnExistingFile())
+		Expect(path.Join(fileOutputDir, "gogen_pilots_1.out")).To(BeAnExistingFile())
 
-		Ω(expectedDojResultsFileName).Should(BeAnExistingFile())
-		Ω(expectedCondensedFileName).Should(BeAnExistingFile())
-		Ω(expectedConvictionsFileName).Should(BeAnExistingFile())
-		Ω(expectedOutputFileName).Should(BeAnExistingFile())
-		Ω(expectedJsonOutputFileName).Should(BeAnExistingFile())
+		workbook, err := xlsx.OpenFile(path.Join(fileOutputDir, "doj_results_1.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(workbook.Sheets).To(HaveLen(4))
+		Expect(workbook.Sheet["Full Results"].Rows).To(HaveLen(36))
+		Expect(workbook.Sheet["Prop 64 Convictions"].Rows).To(HaveLen(17))
 	})
-	It("can accept an age for a participant to determine eligibility", func() {
+
+	It("fails and reports errors for an invalid output format", func() {
 
 		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
 
-		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
-		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
 
 		pathToGogen, err := gexec.Build("gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
 
 		runCommand := "run"
 		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
-		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
-		var age float64
-		age = 40
-		ageFlag := fmt.Sprintf("--individual-age=%v", age)
-		computeAtFlag := "--compute-at=2019-11-11"
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		outputFormatFlag := "--output-format=pdf"
 
-		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, ageFlag)
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, outputFormatFlag)
 		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
 		Expect(err).ToNot(HaveOccurred())
 
-		Eventually(session).Should(gexec.Exit())
-		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
-		Expect(summary).To(gstruct.MatchAllFields(gstruct.Fields{
-			"County":                  Equal("LOS ANGELES"),
-			"IndividualDismissAge":    Equal(40),
-			"YearsConvictionFree":     Equal(10),
-			"LineCount":               Equal(35),
-			"ProcessingTimeInSeconds": BeNumerically(">", 0),
-			"EarliestConviction":      Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
-			"ReliefWithCurrentEligibilityChoices": gstruct.MatchAllKeys(gstruct.Keys{
-				"CountSubjectsNoFelony":               Equal(1),
-				"CountSubjectsNoConviction":           Equal(1),
-				"CountSubjectsNoConvictionLast7Years": Equal(0),
-			}),
-			"ReliefWithDismissAllProp64": gstruct.MatchAllKeys(gstruct.Keys{
-				"CountSubjectsNoFelony":               Equal(2),
-				"CountSubjectsNoConviction":           Equal(2),
-				"CountSubjectsNoConvictionLast7Years": Equal(3),
-			}),
-			"Prop64ConvictionsCountInCountyByCodeSection": gstruct.MatchAllKeys(gstruct.Keys{
-				"11357": Equal(3),
-				"11358": Equal(9),
-				"11359": Equal(4),
-			}),
-			"SubjectsWithProp64ConvictionCountInCounty": Equal(0),
-			"Prop64FelonyConvictionsCountInCounty":      Equal(0),
-			"Prop64MisdemeanorConvictionsCountInCounty": Equal(0),
-			"SubjectsWithSomeReliefCount":               Equal(0),
-			"ConvictionDismissalCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
-			"ConvictionReductionCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
-			"ConvictionDismissalCountByAdditionalRelief": gstruct.MatchAllKeys(gstruct.Keys{
-				"21 years or younger": Equal(1),
-				"40 years or older":   Equal(6),
-				"Only has 11357-60 charges and completed sentence": Equal(1),
-				"11357(a) or 11357(b)":                             Equal(1),
-			}),
-		}))
+		Eventually(session).Should(gexec.Exit(3))
+		Eventually(session.Err).Should(gbytes.Say(`invalid --output-format "pdf": Must be csv or xlsx`))
 	})
 
-	It("can accept a number of years conviction free as a parameter to determine eligibility", func() {
+	It("writes JSON progress events to a file descriptor", func() {
 
 		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
 
-		pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
-		inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+		inputPath := path.Join("test_fixtures", "los_angeles.xlsx")
+		pathToDOJ, _, err = ExtractFullCSVFixtures(inputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		eventsFile, err := ioutil.TempFile(outputDir, "events")
+		Expect(err).ToNot(HaveOccurred())
+		defer eventsFile.Close()
 
 		pathToGogen, err := gexec.Build("gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
 
 		runCommand := "run"
 		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
-		dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
-		var yearsConvictionFree int
-		yearsConvictionFree = 2
-		yearsConvictionFreeFlag := fmt.Sprintf("--years-conviction-free=%v", yearsConvictionFree)
+		dojFlag := fmt.Sprintf("--input-doj=%s,%s", pathToDOJ, path.Join(outputDir, "missing.csv"))
 		computeAtFlag := "--compute-at=2019-11-11"
+		progressFlag := "--progress=json"
+		progressFDFlag := "--progress-fd=3"
 
-		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, yearsConvictionFreeFlag)
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, computeAtFlag, progressFlag, progressFDFlag)
+		command.ExtraFiles = []*os.File{eventsFile}
 		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
 		Expect(err).ToNot(HaveOccurred())
 
-		Eventually(session).Should(gexec.Exit())
-		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
-		Expect(summary).To(gstruct.MatchAllFields(gstruct.Fields{
-			"County":                  Equal("LOS ANGELES"),
-			"IndividualDismissAge":    Equal(50),
-			"YearsConvictionFree":     Equal(2),
-			"LineCount":               Equal(35),
-			"ProcessingTimeInSeconds": BeNumerically(">", 0),
-			"EarliestConviction":      Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
-			"ReliefWithCurrentEligibilityChoices": gstruct.MatchAllKeys(gstruct.Keys{
-				"CountSubjectsNoFelony":               Equal(2),
-				"CountSubjectsNoConviction":           Equal(2),
-				"CountSubjectsNoConvictionLast7Years": Equal(1),
-			}),
-			"ReliefWithDismissAllProp64": gstruct.MatchAllKeys(gstruct.Keys{
-				"CountSubjectsNoFelony":               Equal(2),
-				"CountSubjectsNoConviction":           Equal(2),
-				"CountSubjectsNoConvictionLast7Years": Equal(3),
-			}),
-			"Prop64ConvictionsCountInCountyByCodeSection": gstruct.MatchAllKeys(gstruct.Keys{
-				"11357": Equal(3),
-				"11358": Equal(9),
-				"11359": Equal(4),
-			}),
-			"SubjectsWithProp64ConvictionCountInCounty": Equal(0),
-			"Prop64FelonyConvictionsCountInCounty":      Equal(0),
-			"Prop64MisdemeanorConvictionsCountInCounty": Equal(0),
-			"SubjectsWithSomeReliefCount":               Equal(0),
-			"ConvictionDismissalCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
-			"ConvictionReductionCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
-			"ConvictionDismissalCountByAdditionalRelief": gstruct.MatchAllKeys(gstruct.Keys{
-				"21 years or younger": Equal(1),
-				"50 years or older":   Equal(5),
-				"Only has 11357-60 charges and completed sentence": Equal(1),
-				"11357(a) or 11357(b)":                             Equal(1),
-				"No convictions in past 2 years":                   Equal(2),
-			}),
+		Eventually(session).Should(gexec.Exit(2))
+		Expect(session.Out).ToNot(gbytes.Say(`\[=+`))
+
+		contents, err := ioutil.ReadFile(eventsFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		var eventNames []string
+		var lastEvent map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+			lastEvent = map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(line), &lastEvent)).To(Succeed())
+			if lastEvent["event"] != "progress" {
+				eventNames = append(eventNames, lastEvent["event"].(string)+" "+fmt.Sprint(lastEvent["fileIndex"]))
+			}
+		}
+		Expect(eventNames).To(Equal([]string{
+			"runStarted <nil>",
+			"phaseStarted 1",
+			"phaseFinished 1",
+			"phaseStarted 1",
+			"phaseFinished 1",
+			"fileFinished 1",
+			"phaseStarted 2",
+			"fileFinished 2",
+			"error <nil>",
+			"runFinished <nil>",
 		}))
+		Expect(string(contents)).To(ContainSubstring(`"rowsProcessed":35,"totalRows":35,"percent":100`))
+		Expect(lastEvent["exitCode"]).To(BeNumerically("==", 2))
 	})
 
-	It("validates required options", func() {
+	It("writes JSON progress events for stdin, which has no total rows", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		inputCSV, _, err := ExtractFullCSVFixtures(path.Join("test_fixtures", "los_angeles.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+		inputFile, err := os.Open(inputCSV)
+		Expect(err).ToNot(HaveOccurred())
+		defer inputFile.Close()
+		rows, err := csv.NewReader(inputFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Copies of the fixture's subjects, under new SUBJECT_IDs, make stdin
+		// long enough for more than one progress event.
+		subjectIDColumn := -1
+		for i, header := range rows[0] {
+			if header == "SUBJECT_ID" {
+				subjectIDColumn = i
+			}
+		}
+		Expect(subjectIDColumn).ToNot(Equal(-1))
+		var stdin bytes.Buffer
+		stdinWriter := csv.NewWriter(&stdin)
+		Expect(stdinWriter.Write(rows[0])).To(Succeed())
+		for copyIndex := 0; copyIndex < 40; copyIndex++ {
+			for _, row := range rows[1:] {
+				copied := append([]string{}, row...)
+				copied[subjectIDColumn] = fmt.Sprintf("%s_%d", row[subjectIDColumn], copyIndex)
+				Expect(stdinWriter.Write(copied)).To(Succeed())
+			}
+		}
+		stdinWriter.Flush()
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		command := exec.Command(pathToGogen, "run", fmt.Sprintf("--outputs=%s", outputDir), "--input-doj=-", "--compute-at=2019-11-11", "--progress=json")
+		command.Stdin = &stdin
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session, "20s").Should(gexec.Exit(0))
+
+		var progressEvents []map[string]interface{}
+		for _, line := range strings.Split(string(session.Err.Contents()), "\n") {
+			event := map[string]interface{}{}
+			if json.Unmarshal([]byte(line), &event) == nil && event["event"] == "progress" {
+				progressEvents = append(progressEvents, event)
+			}
+		}
+		Expect(len(progressEvents)).To(BeNumerically(">", 1))
+		for _, event := range progressEvents {
+			Expect(event).To(HaveKey("rowsProcessed"))
+			Expect(event).ToNot(HaveKey("totalRows"))
+			Expect(event).ToNot(HaveKey("percent"))
+			Expect(event).ToNot(HaveKey("etaSeconds"))
+		}
+	})
+
+	It("fails and reports errors for an invalid progress option", func() {
 
 		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
@@ -285,20 +1062,73 @@ var _ = Describe("gogen_pilots", func() {
 
 		runCommand := "run"
 		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
-		computeAtFlag := "--compute-at=2019-11-11"
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		progressFlag := "--progress=xml"
 
-		command := exec.Command(pathToGogen, runCommand, outputsFlag, computeAtFlag)
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, progressFlag)
 		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
 		Expect(err).ToNot(HaveOccurred())
 
 		Eventually(session).Should(gexec.Exit(3))
-		Eventually(session.Err).Should(gbytes.Say("missing required field: Run gogen_pilots --help for more info"))
+		Eventually(session.Err).Should(gbytes.Say(`invalid --progress "xml": Must be bar or json`))
+	})
 
-		expectedErrorFileName := fmt.Sprintf("%v/gogen_pilots%s.err", outputDir, "")
+	It("fails and reports errors for an invalid motion template", func() {
 
-		Ω(expectedErrorFileName).Should(BeAnExistingFile())
-		data, _ := ioutil.ReadFile(expectedErrorFileName)
-		Expect(string(data)).To(Equal("missing required field: Run gogen_pilots --help for more info\n"))
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		templateFile, err := ioutil.TempFile(outputDir, "motion")
+		Expect(err).ToNot(HaveOccurred())
+		templateFile.WriteString("{{.Defendant}}")
+		templateFile.Close()
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		motionTemplateFlag := fmt.Sprintf("--motion-template=%s", templateFile.Name())
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, motionTemplateFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(3))
+		Eventually(session.Err).Should(gbytes.Say(`invalid --motion-template: .*can't evaluate field Defendant`))
+	})
+
+	It("fails and reports errors for an invalid statute catalog", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		catalogFile, err := ioutil.TempFile(outputDir, "statute_catalog")
+		Expect(err).ToNot(HaveOccurred())
+		catalogFile.WriteString(`{"version": "1", "pc290": [{"citation": "288 PC", "pattern": "288 PC", "effectiveDate": "2016-11-09"}]}`)
+		catalogFile.Close()
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		runCommand := "run"
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		statuteCatalogFlag := fmt.Sprintf("--statute-catalog=%s", catalogFile.Name())
+
+		command := exec.Command(pathToGogen, runCommand, outputsFlag, dojFlag, statuteCatalogFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(4))
+		Eventually(session.Err).Should(gbytes.Say(`invalid --statute-catalog: pc290\[0\] "288 PC": missing description`))
 	})
 
 	It("fails and reports errors for missing input files", func() {
@@ -333,13 +1163,17 @@ var _ = Describe("gogen_pilots", func() {
 		Expect(string(data)).To(MatchRegexp("open .*missing.csv: no such file or directory"))
 	})
 
-	It("fails and reports errors for invalid input files", func() {
+	It("reports rows that fail validation instead of failing the run", func() {
 
 		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
 
-		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "bad.csv"))
+		// Without its header row, bad.csv is read by the v1 schema, which its
+		// rows are one column short of.
+		badDOJ, err := ioutil.ReadFile(path.Join("test_fixtures", "bad.csv"))
 		Expect(err).ToNot(HaveOccurred())
+		pathToDOJ = path.Join(outputDir, "bad_without_headers.csv")
+		Expect(ioutil.WriteFile(pathToDOJ, badDOJ[bytes.IndexByte(badDOJ, '\n')+1:], 0644)).To(Succeed())
 
 		pathToGogen, err := gexec.Build("gogen_pilots")
 		Expect(err).ToNot(HaveOccurred())
@@ -355,14 +1189,22 @@ var _ = Describe("gogen_pilots", func() {
 		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
 		Expect(err).ToNot(HaveOccurred())
 
-		Eventually(session).Should(gexec.Exit(2))
-		Eventually(session.Err).Should(gbytes.Say("record on line 2: wrong number of fields"))
+		Eventually(session).Should(gexec.Exit(0))
+		Eventually(session).Should(gbytes.Say("Rejected 38 rows that failed validation"))
 
-		expectedErrorFileName := fmt.Sprintf("%v/gogen_pilots_%s.err", outputDir, filenameSuffix)
+		summary := GetOutputSummary(path.Join(outputDir, fmt.Sprintf("gogen_pilots_%s.json", filenameSuffix)))
+		Expect(summary.LineCount).To(Equal(0))
+		Expect(summary.RejectedRowCount).To(Equal(38))
+		Expect(summary.RejectedRowCountByReason).To(Equal(map[string]int{
+			"too few columns": 38,
+		}))
 
-		Ω(expectedErrorFileName).Should(BeAnExistingFile())
-		data, _ := ioutil.ReadFile(expectedErrorFileName)
-		Expect(string(data)).To(MatchRegexp("record on line 2: wrong number of fields"))
+		fileOutputDir := path.Join(outputDir, fmt.Sprintf("DOJ_Input_File_1_Results_%s", filenameSuffix))
+		rejectedRowsFileName := path.Join(fileOutputDir, fmt.Sprintf("doj_rejected_rows_1_%s.csv", filenameSuffix))
+		Ω(rejectedRowsFileName).Should(BeAnExistingFile())
+		rejectedRows, _ := ioutil.ReadFile(rejectedRowsFileName)
+		Expect(string(rejectedRows)).To(HavePrefix("ROW_NUMBER,REJECTION_REASONS,RECORD_ID,"))
+		Expect(string(rejectedRows)).To(ContainSubstring("\n1,too few columns,,18675309,"))
 	})
 
 	It("runs and has output for Los Angeles", func() {
@@ -388,12 +1230,15 @@ var _ = Describe("gogen_pilots", func() {
 		Eventually(session).Should(gexec.Exit())
 		summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
 		Expect(summary).To(gstruct.MatchAllFields(gstruct.Fields{
-			"County":                  Equal("LOS ANGELES"),
-			"IndividualDismissAge":    Equal(50),
-			"YearsConvictionFree":     Equal(10),
-			"LineCount":               Equal(35),
-			"ProcessingTimeInSeconds": BeNumerically(">", 0),
-			"EarliestConviction":      Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
+			"County":                   Equal("LOS ANGELES"),
+			"IndividualDismissAge":     Equal(50),
+			"YearsConvictionFree":      Equal(10),
+			"StatuteCatalogVersion":    Equal("2019-11-01"),
+			"LineCount":                Equal(35),
+			"RejectedRowCount":         Equal(0),
+			"RejectedRowCountByReason": BeEmpty(),
+			"ProcessingTimeInSeconds":  BeNumerically(">", 0),
+			"EarliestConviction":       Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
 			"ReliefWithCurrentEligibilityChoices": gstruct.MatchAllKeys(gstruct.Keys{
 				"CountSubjectsNoFelony":               Equal(1),
 				"CountSubjectsNoConviction":           Equal(1),
@@ -409,10 +1254,10 @@ var _ = Describe("gogen_pilots", func() {
 				"11358": Equal(9),
 				"11359": Equal(4),
 			}),
-			"SubjectsWithProp64ConvictionCountInCounty": Equal(0),
-			"Prop64FelonyConvictionsCountInCounty":      Equal(0),
-			"Prop64MisdemeanorConvictionsCountInCounty": Equal(0),
-			"SubjectsWithSomeReliefCount":               Equal(0),
+			"SubjectsWithProp64ConvictionCountInCounty": Equal(10),
+			"Prop64FelonyConvictionsCountInCounty":      Equal(13),
+			"Prop64MisdemeanorConvictionsCountInCounty": Equal(3),
+			"SubjectsWithSomeReliefCount":               Equal(7),
 			"ConvictionDismissalCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
 			"ConvictionReductionCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
 			"ConvictionDismissalCountByAdditionalRelief": gstruct.MatchAllKeys(gstruct.Keys{
@@ -421,6 +1266,7 @@ var _ = Describe("gogen_pilots", func() {
 				"Only has 11357-60 charges and completed sentence": Equal(1),
 				"11357(a) or 11357(b)":                             Equal(1),
 			}),
+			"ConvictionAlreadyRelievedCountByReason": gstruct.MatchAllKeys(gstruct.Keys{}),
 		}))
 
 		Eventually(session).Should(gbytes.Say("----------- Overall summary of DOJ file --------------------"))
@@ -575,12 +1421,15 @@ var _ = Describe("gogen_pilots", func() {
 			summary := GetOutputSummary(path.Join(outputDir, "gogen_pilots.json"))
 
 			Expect(summary).To(gstruct.MatchAllFields(gstruct.Fields{
-				"County":                  Equal("LOS ANGELES"),
-				"IndividualDismissAge":    Equal(50),
-				"YearsConvictionFree":     Equal(10),
-				"LineCount":               Equal(70),
-				"EarliestConviction":      Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
-				"ProcessingTimeInSeconds": BeNumerically(">", 0),
+				"County":                   Equal("LOS ANGELES"),
+				"IndividualDismissAge":     Equal(50),
+				"YearsConvictionFree":      Equal(10),
+				"StatuteCatalogVersion":    Equal("2019-11-01"),
+				"LineCount":                Equal(70),
+				"RejectedRowCount":         Equal(0),
+				"RejectedRowCountByReason": BeEmpty(),
+				"EarliestConviction":       Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)),
+				"ProcessingTimeInSeconds":  BeNumerically(">", 0),
 				"ReliefWithCurrentEligibilityChoices": gstruct.MatchAllKeys(gstruct.Keys{
 					"CountSubjectsNoFelony":               Equal(2),
 					"CountSubjectsNoConviction":           Equal(2),
@@ -596,10 +1445,10 @@ var _ = Describe("gogen_pilots", func() {
 					"11358": Equal(18),
 					"11359": Equal(8),
 				}),
-				"SubjectsWithProp64ConvictionCountInCounty": Equal(0),
-				"Prop64FelonyConvictionsCountInCounty":      Equal(0),
-				"Prop64MisdemeanorConvictionsCountInCounty": Equal(0),
-				"SubjectsWithSomeReliefCount":               Equal(0),
+				"SubjectsWithProp64ConvictionCountInCounty": Equal(20),
+				"Prop64FelonyConvictionsCountInCounty":      Equal(26),
+				"Prop64MisdemeanorConvictionsCountInCounty": Equal(6),
+				"SubjectsWithSomeReliefCount":               Equal(14),
 				"ConvictionDismissalCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
 				"ConvictionReductionCountByCodeSection":     gstruct.MatchAllKeys(gstruct.Keys{}),
 				"ConvictionDismissalCountByAdditionalRelief": gstruct.MatchAllKeys(gstruct.Keys{
@@ -608,6 +1457,7 @@ var _ = Describe("gogen_pilots", func() {
 					"Only has 11357-60 charges and completed sentence": Equal(2),
 					"11357(a) or 11357(b)":                             Equal(2),
 				}),
+				"ConvictionAlreadyRelievedCountByReason": gstruct.MatchAllKeys(gstruct.Keys{}),
 			}))
 		})
 
@@ -636,7 +1486,6 @@ var _ = Describe("gogen_pilots", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			Eventually(session).Should(gexec.Exit(2))
-			Eventually(session.Err).Should(gbytes.Say("record on line 2: wrong number of fields"))
 			Eventually(session.Err).Should(gbytes.Say("open .*missing.csv: no such file or directory"))
 
 			expectedErrorFileName := fmt.Sprintf("%v/gogen_pilots_%s.err", outputDir, filenameSuffix)
@@ -644,7 +1493,146 @@ var _ = Describe("gogen_pilots", func() {
 			Ω(expectedErrorFileName).Should(BeAnExistingFile())
 			data, _ := ioutil.ReadFile(expectedErrorFileName)
 			Expect(string(data)).To(MatchRegexp("open .*missing.csv: no such file or directory"))
-			Expect(string(data)).To(MatchRegexp("record on line 2: wrong number of fields"))
+			Expect(string(data)).ToNot(MatchRegexp("bad.csv"))
+		})
+
+		It("processes files in parallel with the same results as processing them one at a time", func() {
+			outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+			inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+			pathToNoHeaders, err := path.Abs(path.Join("test_fixtures", "no_headers.csv"))
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToGogen, err := gexec.Build("gogen_pilots")
+			Expect(err).ToNot(HaveOccurred())
+
+			runCommand := "run"
+			dojFlag := fmt.Sprintf("--input-doj=%s", strings.Join([]string{inputCSV, pathToNoHeaders, inputCSV}, ","))
+			computeAtFlag := "--compute-at=2019-11-11"
+
+			sequentialOutputsFlag := fmt.Sprintf("--outputs=%s", path.Join(outputDir, "sequential"))
+			command := exec.Command(pathToGogen, runCommand, sequentialOutputsFlag, dojFlag, computeAtFlag)
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			parallelOutputsFlag := fmt.Sprintf("--outputs=%s", path.Join(outputDir, "parallel"))
+			command = exec.Command(pathToGogen, runCommand, parallelOutputsFlag, dojFlag, computeAtFlag, "--parallelism=3")
+			session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session).To(gbytes.Say("DOJ file 1 of 3: .*\nProcessing DOJ Data\n\nComplete...\n"))
+			Expect(session).To(gbytes.Say("DOJ file 2 of 3: .*no_headers.csv\nProcessing DOJ Data\n"))
+			Expect(session).To(gbytes.Say("DOJ file 3 of 3: "))
+
+			for fileIndex := 1; fileIndex <= 3; fileIndex++ {
+				resultsFolder := fmt.Sprintf("DOJ_Input_File_%d_Results", fileIndex)
+				resultsFile := fmt.Sprintf("doj_results_%d.csv", fileIndex)
+				sequentialResults, err := ioutil.ReadFile(path.Join(outputDir, "sequential", resultsFolder, resultsFile))
+				Expect(err).ToNot(HaveOccurred())
+				parallelResults, err := ioutil.ReadFile(path.Join(outputDir, "parallel", resultsFolder, resultsFile))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(parallelResults).To(Equal(sequentialResults))
+			}
+
+			sequentialSummary := GetOutputSummary(path.Join(outputDir, "sequential", "gogen_pilots.json"))
+			parallelSummary := GetOutputSummary(path.Join(outputDir, "parallel", "gogen_pilots.json"))
+			sequentialSummary.ProcessingTimeInSeconds = 0
+			parallelSummary.ProcessingTimeInSeconds = 0
+			Expect(parallelSummary).To(Equal(sequentialSummary))
+		})
+
+		It("processes each file of a zip archive and compressed files from stdin", func() {
+			outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToInputExcel := path.Join("test_fixtures", "los_angeles.xlsx")
+			inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+			dojContents, err := ioutil.ReadFile(inputCSV)
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToZip := path.Join(outputDir, "cadoj.zip")
+			zipFile, err := os.Create(pathToZip)
+			Expect(err).ToNot(HaveOccurred())
+			archive := zip.NewWriter(zipFile)
+			for _, entry := range []string{"part_1.csv", "part_2.csv"} {
+				entryWriter, err := archive.Create(entry)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = entryWriter.Write(dojContents)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(archive.Close()).To(Succeed())
+			Expect(zipFile.Close()).To(Succeed())
+
+			var gzipped bytes.Buffer
+			gzipWriter := gzip.NewWriter(&gzipped)
+			_, err = gzipWriter.Write(dojContents)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gzipWriter.Close()).To(Succeed())
+
+			pathToGogen, err := gexec.Build("gogen_pilots")
+			Expect(err).ToNot(HaveOccurred())
+
+			zipOutputDir := path.Join(outputDir, "zip")
+			command := exec.Command(pathToGogen, "run", fmt.Sprintf("--outputs=%s", zipOutputDir), fmt.Sprintf("--input-doj=%s", pathToZip), "--compute-at=2019-11-11")
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(0))
+			Ω(path.Join(zipOutputDir, "DOJ_Input_File_1_Results", "doj_results_1.csv")).Should(BeAnExistingFile())
+			Ω(path.Join(zipOutputDir, "DOJ_Input_File_2_Results", "doj_results_2.csv")).Should(BeAnExistingFile())
+			Expect(GetOutputSummary(path.Join(zipOutputDir, "gogen_pilots.json")).LineCount).To(Equal(70))
+
+			stdinOutputDir := path.Join(outputDir, "stdin")
+			command = exec.Command(pathToGogen, "run", fmt.Sprintf("--outputs=%s", stdinOutputDir), "--input-doj=-", "--compute-at=2019-11-11")
+			command.Stdin = &gzipped
+			session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(GetOutputSummary(path.Join(stdinOutputDir, "gogen_pilots.json")).LineCount).To(Equal(35))
+
+			command = exec.Command(pathToGogen, "run", fmt.Sprintf("--outputs=%s", stdinOutputDir), "--input-doj=-", "--compute-at=2019-11-11", "--resolve-identities")
+			command.Stdin = bytes.NewReader(dojContents)
+			session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(3))
+			Eventually(session.Err).Should(gbytes.Say("--resolve-identities cannot be used with --input-doj=-: Stdin is read in a single pass"))
+
+			zipContents, err := os.Open(pathToZip)
+			Expect(err).ToNot(HaveOccurred())
+			defer zipContents.Close()
+			command = exec.Command(pathToGogen, "run", fmt.Sprintf("--outputs=%s", path.Join(outputDir, "stdin_zip")), "--input-doj=-", "--compute-at=2019-11-11")
+			command.Stdin = zipContents
+			session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(2))
+			Eventually(session.Err).Should(gbytes.Say("- is a zip archive: Zip archives cannot be read from stdin, name the archive in --input-doj instead"))
+		})
+
+		It("validates the parallelism option", func() {
+			outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToGogen, err := gexec.Build("gogen_pilots")
+			Expect(err).ToNot(HaveOccurred())
+
+			outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+			dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+
+			command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, "--parallelism=0")
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(3))
+			Eventually(session.Err).Should(gbytes.Say("invalid --parallelism 0: Must be at least 1"))
 		})
 	})
 })
@@ -0,0 +1,147 @@
+package matchers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CodeSection is a statute parsed from a DOJ offense description, ex:
+// "11357(B)(2) HS-POSSESS MARIJUANA" is section 11357, subdivisions B and 2,
+// of the Health and Safety code.
+type CodeSection struct {
+	Section      string
+	Subdivisions []string
+	CodeBody     string
+}
+
+var codeBodies = []string{"H&S", "HS", "PC", "VC", "BP", "WI"}
+
+var sectionNumberMatcher = regexp.MustCompile(`^\d+(\.\d+)?`)
+var attemptedPrefixMatcher = regexp.MustCompile(`^664(?:[./-](\d+)|(\d{4,}))`)
+var parenthesizedSubdivisionMatcher = regexp.MustCompile(`^\(\s*([0-9A-Za-z]{1,4})\s*\)`)
+var bareSubdivisionMatcher = regexp.MustCompile(`^([A-Za-z]|\d+)`)
+
+// ParseCodeSection finds the first statute in a code section string. Attempted
+// offenses written as 664/187, 664.11357, 664-11357 or 66411357 are parsed as the
+// attempted statute. Subdivisions may be written in parentheses or not, so
+// "11357(B)(2)", "11357B2" and "11357 (b)(2)" all parse to the same value.
+func ParseCodeSection(codeSection string) (CodeSection, bool) {
+	start := strings.IndexAny(codeSection, "0123456789")
+	if start < 0 {
+		return CodeSection{}, false
+	}
+	rest := codeSection[start:]
+	if attempted := attemptedPrefixMatcher.FindStringSubmatchIndex(rest); attempted != nil {
+		if attempted[2] >= 0 {
+			rest = rest[attempted[2]:]
+		} else {
+			rest = rest[attempted[4]:]
+		}
+	}
+
+	section := sectionNumberMatcher.FindString(rest)
+	parsed := CodeSection{Section: section}
+	rest = rest[len(section):]
+
+	for {
+		trimmed := strings.TrimLeft(rest, " ")
+		if codeBody := leadingCodeBody(trimmed); codeBody != "" {
+			parsed.CodeBody = normalizeCodeBody(codeBody)
+			break
+		}
+		if subdivision := parenthesizedSubdivisionMatcher.FindStringSubmatch(trimmed); subdivision != nil {
+			parsed.Subdivisions = append(parsed.Subdivisions, strings.ToUpper(subdivision[1]))
+			rest = trimmed[len(subdivision[0]):]
+			continue
+		}
+		if subdivision := bareSubdivisionMatcher.FindString(rest); subdivision != "" && isBareSubdivision(rest, subdivision) {
+			parsed.Subdivisions = append(parsed.Subdivisions, strings.ToUpper(subdivision))
+			rest = rest[len(subdivision):]
+			continue
+		}
+		break
+	}
+	return parsed, true
+}
+
+// A bare subdivision directly follows the section number or the previous
+// subdivision, ex: the B and 2 in "11357B2". A single letter followed by more
+// letters is a word, not a subdivision.
+func isBareSubdivision(rest string, subdivision string) bool {
+	next := rest[len(subdivision):]
+	if next == "" {
+		return true
+	}
+	isLetter := func(c byte) bool { return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') }
+	return !(isLetter(subdivision[0]) && isLetter(next[0]))
+}
+
+func leadingCodeBody(codeSection string) string {
+	upper := strings.ToUpper(codeSection)
+	for _, codeBody := range codeBodies {
+		if strings.HasPrefix(upper, codeBody) {
+			next := upper[len(codeBody):]
+			if next == "" || !(next[0] >= 'A' && next[0] <= 'Z') {
+				return codeBody
+			}
+		}
+	}
+	return ""
+}
+
+func normalizeCodeBody(codeBody string) string {
+	if codeBody == "H&S" {
+		return "HS"
+	}
+	return codeBody
+}
+
+// Normalized writes the code section in the form DOJ uses most often, ex:
+// "11357(B)(2) HS".
+func (c CodeSection) Normalized() string {
+	var normalized strings.Builder
+	normalized.WriteString(c.Section)
+	for _, subdivision := range c.Subdivisions {
+		normalized.WriteString("(" + subdivision + ")")
+	}
+	if c.CodeBody != "" {
+		normalized.WriteString(" " + c.CodeBody)
+	}
+	return normalized.String()
+}
+
+func (c CodeSection) String() string {
+	return c.Normalized()
+}
+
+// Equal compares section and subdivisions. Code bodies are only compared when
+// both are known, since DOJ descriptions often leave them out.
+func (c CodeSection) Equal(other CodeSection) bool {
+	return c.StartsWith(other) && len(c.Subdivisions) == len(other.Subdivisions)
+}
+
+// StartsWith reports whether c is prefix or one of its subdivisions, ex:
+// 11357(B)(2) starts with 11357 and 11357(B), but not 11357(B)(1).
+func (c CodeSection) StartsWith(prefix CodeSection) bool {
+	if c.Section != prefix.Section || len(prefix.Subdivisions) > len(c.Subdivisions) {
+		return false
+	}
+	if c.CodeBody != "" && prefix.CodeBody != "" && c.CodeBody != prefix.CodeBody {
+		return false
+	}
+	for i, subdivision := range prefix.Subdivisions {
+		if c.Subdivisions[i] != subdivision {
+			return false
+		}
+	}
+	return true
+}
+
+// Subdivision returns the subdivision at depth, starting from 0, or "" if the
+// code section does not have one.
+func (c CodeSection) Subdivision(depth int) string {
+	if depth < len(c.Subdivisions) {
+		return c.Subdivisions[depth]
+	}
+	return ""
+}
@@ -2,21 +2,48 @@ package matchers
 
 import (
 	"regexp"
+	"strings"
 )
 
 var prop64matcher = regexp.MustCompile(`(11357|11358|11359|11360)`)
-var relatedChargeMatcher = regexp.MustCompile(`(647\(f\)\s*PC|602\s*PC|466\s*PC|148\.9\s*PC|148\s*PC|11364\s*HS|11550\s*HS|4140\s*BP|4149\s*BP|4060\s*BP|40508\s*VC|1320[^\d\.][^\.]*PC)`)
-var Prop64MatchersByCodeSection = map[string]*regexp.Regexp{
-	"11357(a)":              regexp.MustCompile(`11357\(A\).*`),
-	"11357(b)":              regexp.MustCompile(`11357\(B\).*`),
-	"11357(c)":              regexp.MustCompile(`11357\(C\).*`),
-	"11357(d)":              regexp.MustCompile(`11357\(D\).*`),
-	"11357(no-sub-section)": regexp.MustCompile(`11357(\([^A-D]\)|[^(]).*`),
-	"11358":                 regexp.MustCompile(`11358.*`),
-	"11359":                 regexp.MustCompile(`11359.*`),
-	"11360":                 regexp.MustCompile(`11360.*`),
-}
-var Section11357SubSectionMatcher = regexp.MustCompile(`11357\(([A-D])\)`)
+
+// Prop64CodeSectionKeys name the Prop 64 code sections that counties choose
+// relief for, ex: in eligibility options.
+var Prop64CodeSectionKeys = []string{
+	"11357(a)",
+	"11357(b)",
+	"11357(c)",
+	"11357(d)",
+	"11357(no-sub-section)",
+	"11358",
+	"11359",
+	"11360",
+}
+
+// Prop64CodeSectionKey returns the key in Prop64CodeSectionKeys for a code
+// section, or "" if it is not a Prop 64 code section.
+func Prop64CodeSectionKey(codeSection CodeSection) string {
+	switch codeSection.Section {
+	case "11358", "11359", "11360":
+		return codeSection.Section
+	case "11357":
+		switch subdivision := codeSection.Subdivision(0); subdivision {
+		case "A", "B", "C", "D":
+			return "11357(" + strings.ToLower(subdivision) + ")"
+		}
+		return "11357(no-sub-section)"
+	}
+	return ""
+}
+
+func IsProp64CodeSectionKey(key string) bool {
+	for _, prop64Key := range Prop64CodeSectionKeys {
+		if key == prop64Key {
+			return true
+		}
+	}
+	return false
+}
 
 func ExtractProp64Section(codeSection string) (bool, string) {
 	if IsProp64Charge(codeSection) {
@@ -27,11 +54,7 @@ func ExtractProp64Section(codeSection string) (bool, string) {
 }
 
 func ExtractRelatedChargeSection(codeSection string) (bool, string) {
-	if IsRelatedCharge(codeSection) {
-		return true, relatedChargeMatcher.FindStringSubmatch(codeSection)[1]
-	} else {
-		return false, ""
-	}
+	return CurrentStatuteCatalog().ExtractRelatedChargeSection(codeSection)
 }
 
 func IsProp64Charge(codeSection string) bool {
@@ -39,14 +62,21 @@ func IsProp64Charge(codeSection string) bool {
 }
 
 func IsRelatedCharge(codeSection string) bool {
-	return relatedChargeMatcher.Match([]byte(codeSection))
+	return CurrentStatuteCatalog().IsRelatedCharge(codeSection)
 }
 
 func Extract11357SubSection(codeSection string) (bool, string) {
-	result := Section11357SubSectionMatcher.FindStringSubmatch(codeSection)
-	if result != nil {
-		return true, result[1]
-	} else {
+	start := strings.Index(codeSection, "11357")
+	if start < 0 {
 		return false, ""
 	}
-}
\ No newline at end of file
+	parsed, ok := ParseCodeSection(codeSection[start:])
+	if !ok || parsed.Section != "11357" {
+		return false, ""
+	}
+	switch subdivision := parsed.Subdivision(0); subdivision {
+	case "A", "B", "C", "D":
+		return true, subdivision
+	}
+	return false, ""
+}
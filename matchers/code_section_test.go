@@ -0,0 +1,105 @@
+package matchers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/matchers"
+)
+
+func parseCodeSection(codeSection string) matchers.CodeSection {
+	parsed, ok := matchers.ParseCodeSection(codeSection)
+	Expect(ok).To(BeTrue(), codeSection)
+	return parsed
+}
+
+var _ = Describe("ParseCodeSection", func() {
+	It("parses the statute, subdivisions and code body", func() {
+		Expect(parseCodeSection("11357(B)(2) HS")).To(Equal(matchers.CodeSection{
+			Section:      "11357",
+			Subdivisions: []string{"B", "2"},
+			CodeBody:     "HS",
+		}))
+		Expect(parseCodeSection("186.22(B)(4) PC")).To(Equal(matchers.CodeSection{
+			Section:      "186.22",
+			Subdivisions: []string{"B", "4"},
+			CodeBody:     "PC",
+		}))
+		Expect(parseCodeSection("11358 HS")).To(Equal(matchers.CodeSection{Section: "11358", CodeBody: "HS"}))
+		Expect(parseCodeSection("11359HS")).To(Equal(matchers.CodeSection{Section: "11359", CodeBody: "HS"}))
+		Expect(parseCodeSection("4060    BP")).To(Equal(matchers.CodeSection{Section: "4060", CodeBody: "BP"}))
+	})
+
+	It("parses subdivisions written without parentheses", func() {
+		Expect(parseCodeSection("11357A").Subdivisions).To(Equal([]string{"A"}))
+		Expect(parseCodeSection("11357B2").Subdivisions).To(Equal([]string{"B", "2"}))
+		Expect(parseCodeSection("11357(B)2 HS").Subdivisions).To(Equal([]string{"B", "2"}))
+	})
+
+	It("does not mistake words for subdivisions", func() {
+		Expect(parseCodeSection("11357 ATTEMPTED").Subdivisions).To(BeEmpty())
+		Expect(parseCodeSection("11357ASSAULT").Subdivisions).To(BeEmpty())
+	})
+
+	It("parses attempted code sections as the attempted statute", func() {
+		for _, codeSection := range []string{"664.11357(c) HS", "66411357(c) HS", "664-11357(c) HS", "664/11357(c) HS"} {
+			Expect(parseCodeSection(codeSection).Normalized()).To(Equal("11357(C) HS"), codeSection)
+		}
+		Expect(parseCodeSection("664/187(A) PC").Normalized()).To(Equal("187(A) PC"))
+		Expect(parseCodeSection("664 PC").Section).To(Equal("664"))
+	})
+
+	It("skips leading characters that are not part of the code section", func() {
+		Expect(parseCodeSection("/11357 HS").Normalized()).To(Equal("11357 HS"))
+		Expect(parseCodeSection("--40508 VC--").Normalized()).To(Equal("40508 VC"))
+	})
+
+	It("does not parse strings without a statute", func() {
+		_, ok := matchers.ParseCodeSection("SEE COMMENT FOR CHARGE")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("CodeSection", func() {
+	It("treats spellings of the same code section as equal", func() {
+		variants := []string{"11357B2", "11357(B)(2)", "11357(b)(2) HS", "11357 (B)(2)"}
+		for _, variant := range variants {
+			Expect(parseCodeSection(variant).Equal(parseCodeSection("11357(B)(2) HS"))).To(BeTrue(), variant)
+		}
+		Expect(parseCodeSection("11357A").Equal(parseCodeSection("11357(A)"))).To(BeTrue())
+	})
+
+	It("distinguishes different subdivisions and code bodies", func() {
+		Expect(parseCodeSection("11357(B)(1)").Equal(parseCodeSection("11357(B)(2)"))).To(BeFalse())
+		Expect(parseCodeSection("11357(B)").Equal(parseCodeSection("11357(B)(2)"))).To(BeFalse())
+		Expect(parseCodeSection("148 PC").Equal(parseCodeSection("148 VC"))).To(BeFalse())
+	})
+
+	It("starts with its section and each of its subdivisions", func() {
+		codeSection := parseCodeSection("11357(B)(2) HS")
+		Expect(codeSection.StartsWith(parseCodeSection("11357"))).To(BeTrue())
+		Expect(codeSection.StartsWith(parseCodeSection("11357B"))).To(BeTrue())
+		Expect(codeSection.StartsWith(parseCodeSection("11357(A)"))).To(BeFalse())
+		Expect(codeSection.StartsWith(parseCodeSection("11357(B)(2)(A)"))).To(BeFalse())
+	})
+
+	It("returns the subdivision at a depth", func() {
+		codeSection := parseCodeSection("11357(B)(2)")
+		Expect(codeSection.Subdivision(0)).To(Equal("B"))
+		Expect(codeSection.Subdivision(1)).To(Equal("2"))
+		Expect(codeSection.Subdivision(2)).To(Equal(""))
+	})
+})
+
+var _ = Describe("Prop64CodeSectionKey", func() {
+	It("returns the key for Prop 64 code sections", func() {
+		Expect(matchers.Prop64CodeSectionKey(parseCodeSection("11357(A) HS"))).To(Equal("11357(a)"))
+		Expect(matchers.Prop64CodeSectionKey(parseCodeSection("11357b2"))).To(Equal("11357(b)"))
+		Expect(matchers.Prop64CodeSectionKey(parseCodeSection("11357 HS"))).To(Equal("11357(no-sub-section)"))
+		Expect(matchers.Prop64CodeSectionKey(parseCodeSection("11357(E) HS"))).To(Equal("11357(no-sub-section)"))
+		Expect(matchers.Prop64CodeSectionKey(parseCodeSection("11359(C) HS"))).To(Equal("11359"))
+	})
+
+	It("returns an empty key for other code sections", func() {
+		Expect(matchers.Prop64CodeSectionKey(parseCodeSection("148 PC"))).To(Equal(""))
+	})
+})
@@ -0,0 +1,103 @@
+package matchers
+
+// defaultStatuteCatalogJSON is the statute catalog used when no --statute-catalog
+// is given. Copy it to a file to review or change the lists, and bump the
+// version whenever an entry changes.
+const defaultStatuteCatalogJSON = `{
+  "version": "2019-11-01",
+  "superstrikes": [
+    {"citation": "37 PC", "pattern": "37 PC", "effectiveDate": "2012-11-07", "description": "Treason"},
+    {"citation": "128 PC", "pattern": "128 PC", "effectiveDate": "2012-11-07", "description": "Perjury causing the execution of an innocent person"},
+    {"citation": "187 PC", "pattern": "187 PC", "effectiveDate": "2012-11-07", "description": "Murder"},
+    {"citation": "188 PC", "pattern": "188 PC", "effectiveDate": "2012-11-07", "description": "Murder (malice aforethought)"},
+    {"citation": "189 PC", "pattern": "189(\\.[15])? PC", "effectiveDate": "2012-11-07", "description": "First and second degree murder"},
+    {"citation": "190 PC", "pattern": "190(\\.\\d{1,2})?(\\(.*\\))? PC", "effectiveDate": "2012-11-07", "description": "Murder with special circumstances"},
+    {"citation": "191.5 PC", "pattern": "191(\\.5)? PC", "effectiveDate": "2012-11-07", "description": "Gross vehicular manslaughter while intoxicated"},
+    {"citation": "205 PC", "pattern": "205 PC", "effectiveDate": "2012-11-07", "description": "Aggravated mayhem"},
+    {"citation": "207 PC", "pattern": "207 PC", "effectiveDate": "2012-11-07", "description": "Kidnapping"},
+    {"citation": "209 PC", "pattern": "209(\\.5)? PC", "effectiveDate": "2012-11-07", "description": "Kidnapping for ransom, robbery or carjacking"},
+    {"citation": "217.1 PC", "pattern": "217\\.1 PC", "effectiveDate": "2012-11-07", "description": "Assault on or attempted murder of a public official"},
+    {"citation": "218 PC", "pattern": "218 PC", "effectiveDate": "2012-11-07", "description": "Train wrecking"},
+    {"citation": "219 PC", "pattern": "219 PC", "effectiveDate": "2012-11-07", "description": "Train wrecking"},
+    {"citation": "220 PC", "pattern": "220 PC", "effectiveDate": "2012-11-07", "description": "Assault with intent to commit a sex offense"},
+    {"citation": "245(d)(3) PC", "pattern": "245\\(D\\)\\(3\\) PC", "effectiveDate": "2012-11-07", "description": "Assault with a machine gun on a peace officer or firefighter"},
+    {"citation": "261 PC", "pattern": "261(\\(.*\\))? PC", "effectiveDate": "2012-11-07", "description": "Rape"},
+    {"citation": "262 PC", "pattern": "262(\\(.*\\))? PC", "effectiveDate": "2012-11-07", "description": "Spousal rape"},
+    {"citation": "264.1 PC", "pattern": "264\\.1 PC", "effectiveDate": "2012-11-07", "description": "Rape in concert"},
+    {"citation": "269 PC", "pattern": "269 PC", "effectiveDate": "2012-11-07", "description": "Aggravated sexual assault of a child"},
+    {"citation": "273ab PC", "pattern": "273AB(\\(.*\\))? PC", "effectiveDate": "2012-11-07", "description": "Assault on a child causing death or coma"},
+    {"citation": "286 PC", "pattern": "286((\\(C\\)\\([123]\\)(\\([ABC]\\))?)|(\\(D\\)\\([123]\\)))? PC", "effectiveDate": "2012-11-07", "description": "Sodomy by force or with a child"},
+    {"citation": "287 PC", "pattern": "287 PC", "effectiveDate": "2012-11-07", "description": "Oral copulation by force or with a child"},
+    {"citation": "288 PC", "pattern": "288((\\(A\\))|(\\(B\\)\\([12]\\)))? PC", "effectiveDate": "2012-11-07", "description": "Lewd or lascivious acts with a child under 14"},
+    {"citation": "288a PC", "pattern": "288A((\\(D\\))|(\\(C\\)\\(1\\))|(\\(C\\)\\(2\\)\\([ABC]\\)))? PC", "effectiveDate": "2012-11-07", "description": "Oral copulation by force or with a child (former section number)"},
+    {"citation": "288.5 PC", "pattern": "288\\.5(\\(A\\))? PC", "effectiveDate": "2012-11-07", "description": "Continuous sexual abuse of a child"},
+    {"citation": "289 PC", "pattern": "289((\\(J\\))|(\\(A\\)\\(1\\)\\([ABC]\\))|(\\(A\\)\\(2\\)\\(C\\)))? PC", "effectiveDate": "2012-11-07", "description": "Sexual penetration by force or with a child"},
+    {"citation": "451.5 PC", "pattern": "451\\.5 PC", "effectiveDate": "2012-11-07", "description": "Aggravated arson"},
+    {"citation": "653f PC", "pattern": "653F PC", "effectiveDate": "2012-11-07", "description": "Solicitation to commit murder or a sex offense"},
+    {"citation": "667.61 PC", "pattern": "667\\.(61|7|71) PC", "effectiveDate": "2012-11-07", "description": "One strike, habitual offender and habitual sex offender sentencing"},
+    {"citation": "4500 PC", "pattern": "4500 PC", "effectiveDate": "2012-11-07", "description": "Assault with malice aforethought by a life prisoner"},
+    {"citation": "11418 PC", "pattern": "11418((\\(A\\)\\(1\\))|(\\(B\\)\\([12]\\))) PC", "effectiveDate": "2012-11-07", "description": "Use of a weapon of mass destruction"},
+    {"citation": "12308 PC", "pattern": "12308 PC", "effectiveDate": "2012-11-07", "description": "Exploding a destructive device with intent to murder (former section number)"},
+    {"citation": "12310 PC", "pattern": "12310 PC", "effectiveDate": "2012-11-07", "description": "Exploding a destructive device causing death or injury (former section number)"},
+    {"citation": "18745 PC", "pattern": "18745 PC", "effectiveDate": "2012-11-07", "description": "Exploding a destructive device with intent to murder"},
+    {"citation": "18755 PC", "pattern": "18755 PC", "effectiveDate": "2012-11-07", "description": "Exploding a destructive device causing death or injury"},
+    {"citation": "1672(a) MV", "pattern": "1672\\(A\\) MV", "effectiveDate": "2012-11-07", "description": "Military and Veterans Code offense causing death"}
+  ],
+  "gangEnhancements": [
+    {"citation": "186.22(b)(4) PC", "pattern": "186\\.22\\(B\\)\\(4\\)", "effectiveDate": "2012-11-07", "description": "Criminal street gang enhancement punishable by a life term"}
+  ],
+  "gangEnhanceableOffenses": [
+    {"citation": "136.1 PC", "pattern": "136\\.1", "effectiveDate": "2012-11-07", "description": "Dissuading a witness"},
+    {"citation": "215 PC", "pattern": "215", "effectiveDate": "2012-11-07", "description": "Carjacking"},
+    {"citation": "213(a)(1)(A) PC", "pattern": "213\\(A\\)\\(1\\)\\(A\\)", "effectiveDate": "2012-11-07", "description": "Home invasion robbery in concert"},
+    {"citation": "246 PC", "pattern": "246", "effectiveDate": "2012-11-07", "description": "Shooting at an inhabited dwelling or occupied vehicle"},
+    {"citation": "519 PC", "pattern": "519", "effectiveDate": "2012-11-07", "description": "Extortion by threat"},
+    {"citation": "12022.55 PC", "pattern": "12022\\.55", "effectiveDate": "2012-11-07", "description": "Discharging a firearm from a vehicle causing great bodily injury"}
+  ],
+  "pc290": [
+    {"citation": "236.1(b) PC", "pattern": "236\\.1\\([BC]\\)(.*) PC", "effectiveDate": "2016-11-09", "description": "Human trafficking for a sex offense"},
+    {"citation": "243.4 PC", "pattern": "243\\.4(.*) PC", "effectiveDate": "2016-11-09", "description": "Sexual battery"},
+    {"citation": "261 PC", "pattern": "261 PC", "effectiveDate": "2016-11-09", "description": "Rape"},
+    {"citation": "261 PC", "pattern": "261(\\(|\\.|[a-zA-Z])+(.*) PC", "effectiveDate": "2016-11-09", "description": "Rape (subdivisions)"},
+    {"citation": "262(a)(1) PC", "pattern": "262\\(A\\)(\\(1\\))? PC", "effectiveDate": "2016-11-09", "description": "Spousal rape by force"},
+    {"citation": "264.1 PC", "pattern": "264\\.1(\\((.*))? PC", "effectiveDate": "2016-11-09", "description": "Rape in concert"},
+    {"citation": "266 PC", "pattern": "266 PC", "effectiveDate": "2016-11-09", "description": "Enticing a minor into prostitution"},
+    {"citation": "266c PC", "pattern": "266C PC", "effectiveDate": "2016-11-09", "description": "Sexual act induced by fraud or fear"},
+    {"citation": "266h(b) PC", "pattern": "266H\\(B\\)(.*) PC", "effectiveDate": "2016-11-09", "description": "Pimping a minor"},
+    {"citation": "266i(b) PC", "pattern": "266I\\(B\\)(.*) PC", "effectiveDate": "2016-11-09", "description": "Pandering a minor"},
+    {"citation": "266j PC", "pattern": "266J PC", "effectiveDate": "2016-11-09", "description": "Procuring a child under 16 for lewd acts"},
+    {"citation": "267 PC", "pattern": "267 PC", "effectiveDate": "2016-11-09", "description": "Abduction of a minor for prostitution"},
+    {"citation": "269 PC", "pattern": "269 PC", "effectiveDate": "2016-11-09", "description": "Aggravated sexual assault of a child"},
+    {"citation": "269 PC", "pattern": "269(\\(|\\.|[a-zA-Z])+(.*) PC", "effectiveDate": "2016-11-09", "description": "Aggravated sexual assault of a child (subdivisions)"},
+    {"citation": "272 PC", "pattern": "272 PC", "effectiveDate": "2016-11-09", "description": "Contributing to the delinquency of a minor with lewd intent"},
+    {"citation": "285 PC", "pattern": "285 PC", "effectiveDate": "2016-11-09", "description": "Incest"},
+    {"citation": "287 PC", "pattern": "287 PC", "effectiveDate": "2016-11-09", "description": "Oral copulation"},
+    {"citation": "286 PC", "pattern": "286([^\\.]*) PC", "effectiveDate": "2016-11-09", "description": "Sodomy"},
+    {"citation": "288 PC", "pattern": "288 PC", "effectiveDate": "2016-11-09", "description": "Lewd or lascivious acts with a child under 14"},
+    {"citation": "288a PC", "pattern": "288A(.*) PC", "effectiveDate": "2016-11-09", "description": "Oral copulation (former section number)"},
+    {"citation": "288.2 PC", "pattern": "288\\.[23457](.*) PC", "effectiveDate": "2016-11-09", "description": "Sex offenses against children under 288.2, 288.3, 288.4, 288.5 and 288.7"},
+    {"citation": "289 PC", "pattern": "289([^\\.]*) PC", "effectiveDate": "2016-11-09", "description": "Sexual penetration by a foreign object"},
+    {"citation": "311.1 PC", "pattern": "311\\.1(.*) PC", "effectiveDate": "2016-11-09", "description": "Sending or bringing child pornography into the state"},
+    {"citation": "311.2(b) PC", "pattern": "311\\.2\\([BCD]\\) PC", "effectiveDate": "2016-11-09", "description": "Distributing child pornography"},
+    {"citation": "311.3 PC", "pattern": "311\\.([34]|10|11)(.*) PC", "effectiveDate": "2016-11-09", "description": "Sexual exploitation of a child, and producing, advertising or possessing child pornography"},
+    {"citation": "314(1) PC", "pattern": "314\\([12]\\) PC", "effectiveDate": "2016-11-09", "description": "Indecent exposure"},
+    {"citation": "451.5 PC", "pattern": "451\\.5 PC", "effectiveDate": "2016-11-09", "description": "Aggravated arson"},
+    {"citation": "647.6 PC", "pattern": "647\\.6(.*) PC", "effectiveDate": "2016-11-09", "description": "Annoying or molesting a child"},
+    {"citation": "647a PC", "pattern": "647A(.*) PC", "effectiveDate": "2016-11-09", "description": "Annoying or molesting a child (former section number)"},
+    {"citation": "653f(b) PC", "pattern": "653F\\([BC]\\) PC", "effectiveDate": "2016-11-09", "description": "Solicitation to commit rape, sodomy or oral copulation by force"}
+  ],
+  "relatedCharges": [
+    {"citation": "647(f) PC", "pattern": "647\\(f\\)\\s*PC", "effectiveDate": "2016-11-09", "description": "Public intoxication"},
+    {"citation": "602 PC", "pattern": "602\\s*PC", "effectiveDate": "2016-11-09", "description": "Trespassing"},
+    {"citation": "466 PC", "pattern": "466\\s*PC", "effectiveDate": "2016-11-09", "description": "Possession of burglary tools"},
+    {"citation": "148.9 PC", "pattern": "148\\.9\\s*PC", "effectiveDate": "2016-11-09", "description": "Giving false identification to a peace officer"},
+    {"citation": "148 PC", "pattern": "148\\s*PC", "effectiveDate": "2016-11-09", "description": "Resisting or obstructing a peace officer"},
+    {"citation": "11364 HS", "pattern": "11364\\s*HS", "effectiveDate": "2016-11-09", "description": "Possession of drug paraphernalia"},
+    {"citation": "11550 HS", "pattern": "11550\\s*HS", "effectiveDate": "2016-11-09", "description": "Under the influence of a controlled substance"},
+    {"citation": "4140 BP", "pattern": "4140\\s*BP", "effectiveDate": "2016-11-09", "description": "Possession of a hypodermic needle or syringe"},
+    {"citation": "4149 BP", "pattern": "4149\\s*BP", "effectiveDate": "2016-11-09", "description": "Possession of a hypodermic needle or syringe"},
+    {"citation": "4060 BP", "pattern": "4060\\s*BP", "effectiveDate": "2016-11-09", "description": "Possession of a controlled substance without a prescription"},
+    {"citation": "40508 VC", "pattern": "40508\\s*VC", "effectiveDate": "2016-11-09", "description": "Failure to appear on a traffic citation"},
+    {"citation": "1320 PC", "pattern": "1320[^\\d\\.][^\\.]*PC", "effectiveDate": "2016-11-09", "description": "Failure to appear"}
+  ]
+}`
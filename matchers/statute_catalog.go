@@ -0,0 +1,220 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Statute is one entry in a StatuteCatalog. Pattern is a regular expression
+// matched against DOJ code sections, ex: `187 PC`.
+type Statute struct {
+	Citation      string `json:"citation"`
+	Pattern       string `json:"pattern"`
+	EffectiveDate string `json:"effectiveDate"`
+	Description   string `json:"description"`
+}
+
+// StatuteCatalog lists the code sections that disqualify a subject from relief
+// (superstrikes and PC 290 registrable offenses) and the charges related to
+// Prop 64. A superstrike is also any gang enhanceable offense charged together
+// with a gang enhancement, ex: "215 PC 186.22(B)(4) PC".
+type StatuteCatalog struct {
+	Version                 string    `json:"version"`
+	Superstrikes            []Statute `json:"superstrikes"`
+	GangEnhancements        []Statute `json:"gangEnhancements"`
+	GangEnhanceableOffenses []Statute `json:"gangEnhanceableOffenses"`
+	PC290                   []Statute `json:"pc290"`
+	RelatedCharges          []Statute `json:"relatedCharges"`
+
+	superstrikePatterns       []*regexp.Regexp
+	pc290Patterns             []*regexp.Regexp
+	gangEnhancementPattern    *regexp.Regexp
+	enhanceableOffensePattern *regexp.Regexp
+	relatedChargePattern      *regexp.Regexp
+}
+
+var (
+	statuteCatalogMutex   sync.RWMutex
+	currentStatuteCatalog = mustParseStatuteCatalog(defaultStatuteCatalogJSON)
+)
+
+// DefaultStatuteCatalog returns the catalog built into gogen_pilots.
+func DefaultStatuteCatalog() *StatuteCatalog {
+	return mustParseStatuteCatalog(defaultStatuteCatalogJSON)
+}
+
+// CurrentStatuteCatalog returns the catalog used to match code sections.
+func CurrentStatuteCatalog() *StatuteCatalog {
+	statuteCatalogMutex.RLock()
+	defer statuteCatalogMutex.RUnlock()
+	return currentStatuteCatalog
+}
+
+// UseStatuteCatalog replaces the catalog used to match code sections. Call it
+// before processing any DOJ files.
+func UseStatuteCatalog(catalog *StatuteCatalog) {
+	statuteCatalogMutex.Lock()
+	defer statuteCatalogMutex.Unlock()
+	currentStatuteCatalog = catalog
+}
+
+func LoadStatuteCatalog(filePath string) (*StatuteCatalog, error) {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatuteCatalog(contents)
+}
+
+func ParseStatuteCatalog(contents []byte) (*StatuteCatalog, error) {
+	var catalog StatuteCatalog
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&catalog); err != nil {
+		return nil, err
+	}
+	if err := catalog.compile(); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+func mustParseStatuteCatalog(contents string) *StatuteCatalog {
+	catalog, err := ParseStatuteCatalog([]byte(contents))
+	if err != nil {
+		panic(fmt.Sprintf("invalid default statute catalog: %v", err))
+	}
+	return catalog
+}
+
+func (c *StatuteCatalog) compile() error {
+	if strings.TrimSpace(c.Version) == "" {
+		return errors.New("missing version")
+	}
+
+	lists := []struct {
+		name     string
+		statutes []Statute
+	}{
+		{"superstrikes", c.Superstrikes},
+		{"gangEnhancements", c.GangEnhancements},
+		{"gangEnhanceableOffenses", c.GangEnhanceableOffenses},
+		{"pc290", c.PC290},
+		{"relatedCharges", c.RelatedCharges},
+	}
+	for _, list := range lists {
+		for i, statute := range list.statutes {
+			if err := statute.validate(); err != nil {
+				return fmt.Errorf("%s[%d] %q: %v", list.name, i, statute.Citation, err)
+			}
+		}
+	}
+
+	c.superstrikePatterns = compileStatutes(c.Superstrikes)
+	c.pc290Patterns = compileStatutes(c.PC290)
+	c.gangEnhancementPattern = nil
+	c.enhanceableOffensePattern = nil
+	c.relatedChargePattern = nil
+
+	gangEnhancements := joinStatutePatterns(c.GangEnhancements)
+	enhanceableOffenses := joinStatutePatterns(c.GangEnhanceableOffenses)
+	if gangEnhancements != "" {
+		c.gangEnhancementPattern = regexp.MustCompile(gangEnhancements + ` PC`)
+	}
+	if enhanceableOffenses != "" {
+		c.enhanceableOffensePattern = regexp.MustCompile(enhanceableOffenses + ` PC`)
+	}
+	if gangEnhancements != "" && enhanceableOffenses != "" {
+		c.superstrikePatterns = append(c.superstrikePatterns,
+			regexp.MustCompile(enhanceableOffenses+`.*`+gangEnhancements+` PC`),
+			regexp.MustCompile(gangEnhancements+`.*`+enhanceableOffenses+` PC`),
+		)
+	}
+	if relatedCharges := joinStatutePatterns(c.RelatedCharges); relatedCharges != "" {
+		c.relatedChargePattern = regexp.MustCompile(relatedCharges)
+	}
+	return nil
+}
+
+func (s Statute) validate() error {
+	if strings.TrimSpace(s.Citation) == "" {
+		return errors.New("missing citation")
+	}
+	if strings.TrimSpace(s.Description) == "" {
+		return errors.New("missing description")
+	}
+	if _, err := time.Parse("2006-01-02", s.EffectiveDate); err != nil {
+		return fmt.Errorf("effectiveDate must be a date in the format YYYY-MM-DD, got %q", s.EffectiveDate)
+	}
+	if s.Pattern == "" {
+		return errors.New("missing pattern")
+	}
+	if _, err := regexp.Compile(s.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern: %v", err)
+	}
+	return nil
+}
+
+func compileStatutes(statutes []Statute) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(statutes))
+	for _, statute := range statutes {
+		patterns = append(patterns, regexp.MustCompile(statute.Pattern))
+	}
+	return patterns
+}
+
+// joinStatutePatterns matches any of the statutes, capturing the match as the
+// first group.
+func joinStatutePatterns(statutes []Statute) string {
+	if len(statutes) == 0 {
+		return ""
+	}
+	patterns := make([]string, 0, len(statutes))
+	for _, statute := range statutes {
+		patterns = append(patterns, `(`+statute.Pattern+`)`)
+	}
+	return `(` + strings.Join(patterns, `|`) + `)`
+}
+
+func (c *StatuteCatalog) IsSuperstrike(codeSection string) bool {
+	return matchesAny(c.superstrikePatterns, codeSection)
+}
+
+func (c *StatuteCatalog) IsPC290(codeSection string) bool {
+	return matchesAny(c.pc290Patterns, codeSection)
+}
+
+func (c *StatuteCatalog) IsGangEnhancement(codeSection string) bool {
+	return c.gangEnhancementPattern != nil && c.gangEnhancementPattern.MatchString(codeSection)
+}
+
+func (c *StatuteCatalog) IsEnhanceableOffense(codeSection string) bool {
+	return c.enhanceableOffensePattern != nil && c.enhanceableOffensePattern.MatchString(codeSection)
+}
+
+func (c *StatuteCatalog) IsRelatedCharge(codeSection string) bool {
+	return c.relatedChargePattern != nil && c.relatedChargePattern.MatchString(codeSection)
+}
+
+func (c *StatuteCatalog) ExtractRelatedChargeSection(codeSection string) (bool, string) {
+	if !c.IsRelatedCharge(codeSection) {
+		return false, ""
+	}
+	return true, c.relatedChargePattern.FindStringSubmatch(codeSection)[1]
+}
+
+func matchesAny(patterns []*regexp.Regexp, codeSection string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(codeSection) {
+			return true
+		}
+	}
+	return false
+}
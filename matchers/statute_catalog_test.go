@@ -0,0 +1,71 @@
+package matchers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/matchers"
+)
+
+var _ = Describe("StatuteCatalog", func() {
+	It("has a version and complete entries in the default catalog", func() {
+		catalog := matchers.DefaultStatuteCatalog()
+		Expect(catalog.Version).To(Equal("2019-11-01"))
+		for _, statutes := range [][]matchers.Statute{catalog.Superstrikes, catalog.GangEnhancements, catalog.GangEnhanceableOffenses, catalog.PC290, catalog.RelatedCharges} {
+			Expect(statutes).ToNot(BeEmpty())
+		}
+	})
+
+	It("matches superstrikes, including gang enhanced offenses", func() {
+		catalog := matchers.DefaultStatuteCatalog()
+		Expect(catalog.IsSuperstrike("187 PC")).To(BeTrue())
+		Expect(catalog.IsSuperstrike("215 PC 186.22(B)(4) PC")).To(BeTrue())
+		Expect(catalog.IsSuperstrike("215 PC")).To(BeFalse())
+		Expect(catalog.IsGangEnhancement("186.22(B)(4) PC")).To(BeTrue())
+		Expect(catalog.IsEnhanceableOffense("215 PC")).To(BeTrue())
+	})
+
+	It("matches PC 290 registrable offenses and related charges", func() {
+		catalog := matchers.DefaultStatuteCatalog()
+		Expect(catalog.IsPC290("288 PC")).To(BeTrue())
+		Expect(catalog.IsPC290("187 PC")).To(BeFalse())
+		matched, codeSection := catalog.ExtractRelatedChargeSection("11364  HS-POSSESS UNLAWFUL PARAPHERNALIA")
+		Expect(matched).To(BeTrue())
+		Expect(codeSection).To(Equal("11364  HS"))
+	})
+
+	It("matches only the statutes in a custom catalog", func() {
+		catalog, err := matchers.ParseStatuteCatalog([]byte(`{
+			"version": "custom",
+			"relatedCharges": [{"citation": "602 PC", "pattern": "602\\s*PC", "effectiveDate": "2016-11-09", "description": "Trespassing"}]
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(catalog.IsRelatedCharge("602 PC")).To(BeTrue())
+		Expect(catalog.IsRelatedCharge("11364 HS")).To(BeFalse())
+		Expect(catalog.IsSuperstrike("187 PC")).To(BeFalse())
+		Expect(catalog.IsGangEnhancement("186.22(B)(4) PC")).To(BeFalse())
+	})
+
+	It("uses the current catalog for the package level matchers", func() {
+		catalog, err := matchers.ParseStatuteCatalog([]byte(`{"version": "empty"}`))
+		Expect(err).ToNot(HaveOccurred())
+		matchers.UseStatuteCatalog(catalog)
+		defer matchers.UseStatuteCatalog(matchers.DefaultStatuteCatalog())
+
+		Expect(matchers.CurrentStatuteCatalog().Version).To(Equal("empty"))
+		Expect(matchers.IsRelatedCharge("11364 HS")).To(BeFalse())
+	})
+
+	It("rejects invalid catalogs", func() {
+		invalidCatalogs := map[string]string{
+			`{"superstrikes": []}`:                "missing version",
+			`{"version": "1", "superstrike": []}`: `json: unknown field "superstrike"`,
+			`{"version": "1", "pc290": [{"pattern": "288 PC", "effectiveDate": "2016-11-09", "description": "Lewd acts"}]}`:                                `pc290[0] "": missing citation`,
+			`{"version": "1", "pc290": [{"citation": "288 PC", "pattern": "288 PC", "effectiveDate": "11/09/2016", "description": "Lewd acts"}]}`:          `pc290[0] "288 PC": effectiveDate must be a date in the format YYYY-MM-DD, got "11/09/2016"`,
+			`{"version": "1", "relatedCharges": [{"citation": "602 PC", "pattern": "602(", "effectiveDate": "2016-11-09", "description": "Trespassing"}]}`: "relatedCharges[0] \"602 PC\": invalid pattern: error parsing regexp: missing closing ): `602(`",
+		}
+		for contents, expectedError := range invalidCatalogs {
+			_, err := matchers.ParseStatuteCatalog([]byte(contents))
+			Expect(err).To(MatchError(expectedError), contents)
+		}
+	})
+})
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gogen_pilots/data"
+	"gogen_pilots/exporter"
+	"gogen_pilots/utilities"
+)
+
+type serveOpts struct {
+	Address string `long:"address" default:"127.0.0.1:8080" description:"The address the HTTP API listens on"`
+	Root    string `long:"root" required:"true" description:"The folder that the input and output paths of runs must be in"`
+}
+
+// runRequest is the body of POST /runs. Its fields are the options of the run
+// command, except that input files are a list.
+type runRequest struct {
+	InputDOJ            []string `json:"inputDoj"`
+	Outputs             string   `json:"outputs"`
+	ComputeAt           string   `json:"computeAt"`
+	County              string   `json:"county"`
+	InputFormat         string   `json:"inputFormat"`
+	DOJSchema           string   `json:"dojSchema"`
+	OutputFormat        string   `json:"outputFormat"`
+	EligibilityOptions  string   `json:"eligibilityOptions"`
+	EligibilityRules    string   `json:"eligibilityRules"`
+	StatuteCatalog      string   `json:"statuteCatalog"`
+	MotionTemplate      string   `json:"motionTemplate"`
+	ResolveIdentities   bool     `json:"resolveIdentities"`
+	Parallelism         int      `json:"parallelism"`
+	IndividualAge       int      `json:"individualAge"`
+	YearsConvictionFree int      `json:"yearsConvictionFree"`
+	FileNameSuffix      string   `json:"fileNameSuffix"`
+}
+
+func (req runRequest) runOpts() runOpts {
+	r := runOpts{
+		OutputFolder:        req.Outputs,
+		DOJFiles:            strings.Join(req.InputDOJ, ","),
+		ComputeAt:           req.ComputeAt,
+		FileNameSuffix:      req.FileNameSuffix,
+		IndividualAge:       req.IndividualAge,
+		YearsConvictionFree: req.YearsConvictionFree,
+		County:              req.County,
+		EligibilityOptions:  req.EligibilityOptions,
+		EligibilityRules:    req.EligibilityRules,
+		StatuteCatalog:      req.StatuteCatalog,
+		MotionTemplate:      req.MotionTemplate,
+		ResolveIdentities:   req.ResolveIdentities,
+		Parallelism:         req.Parallelism,
+		InputFormat:         req.InputFormat,
+		DOJSchema:           req.DOJSchema,
+		OutputFormat:        req.OutputFormat,
+	}
+	// Fill in the defaults the run command's flags have.
+	if r.Parallelism == 0 {
+		r.Parallelism = 1
+	}
+	if r.InputFormat == "" {
+		r.InputFormat = "auto"
+	}
+	if r.DOJSchema == "" {
+		r.DOJSchema = data.DefaultDOJSchemaName
+	}
+	if r.OutputFormat == "" {
+		r.OutputFormat = "csv"
+	}
+	return r
+}
+
+const (
+	runQueued    = "queued"
+	runRunning   = "running"
+	runSucceeded = "succeeded"
+	runFailed    = "failed"
+)
+
+// serverRun is a run submitted to the HTTP API, as returned by GET /runs/{id}.
+// Summary is only set once every file has been processed without errors.
+type serverRun struct {
+	ID          string            `json:"id"`
+	Status      string            `json:"status"`
+	Files       []*serverRunFile  `json:"files"`
+	Summary     *exporter.Summary `json:"summary,omitempty"`
+	SummaryFile string            `json:"summaryFile,omitempty"`
+	Errors      []string          `json:"errors,omitempty"`
+
+	opts     runOpts
+	settings runSettings
+}
+
+type serverRunFile struct {
+	FileIndex    int      `json:"fileIndex"`
+	InputFile    string   `json:"inputFile"`
+	Status       string   `json:"status"`
+	RowsRead     int      `json:"rowsRead"`
+	TotalRows    int      `json:"totalRows"`
+	OutputFolder string   `json:"outputFolder,omitempty"`
+	OutputFiles  []string `json:"outputFiles,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// runServer is the HTTP API of the serve command. Runs are processed one at a
+// time, in the order they were submitted, by the same pipeline as the run
+// command.
+//
+// Requests must carry the server's token, so other programs and web pages
+// cannot submit runs, and runs can only read and write files inside root.
+type runServer struct {
+	root  string
+	token string
+	mutex sync.Mutex
+	runs  []*serverRun
+	queue chan *serverRun
+}
+
+func newRunServer(root string, token string) *runServer {
+	s := &runServer{root: root, token: token, queue: make(chan *serverRun, 100)}
+	go s.processRuns()
+	return s
+}
+
+// newServerToken is a random token for a server's clients to authenticate with.
+func newServerToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func (s *runServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	authorization := []byte(req.Header.Get("Authorization"))
+	if subtle.ConstantTimeCompare(authorization, []byte("Bearer "+s.token)) != 1 {
+		writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token: Send the token the server printed as Authorization: Bearer <token>"))
+		return
+	}
+
+	switch {
+	case req.URL.Path == "/runs" && req.Method == http.MethodPost:
+		// Requiring JSON makes browsers send a CORS preflight, which this API does
+		// not answer, before any cross-site request can submit a run.
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, errors.New("runs must be submitted with Content-Type: application/json"))
+			return
+		}
+		s.submitRun(w, req)
+	case req.URL.Path == "/runs" && req.Method == http.MethodGet:
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		writeJSON(w, http.StatusOK, s.runs)
+	case strings.HasPrefix(req.URL.Path, "/runs/") && req.Method == http.MethodGet:
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		run := s.findRun(strings.TrimPrefix(req.URL.Path, "/runs/"))
+		if run == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no run %s", strings.TrimPrefix(req.URL.Path, "/runs/")))
+			return
+		}
+		writeJSON(w, http.StatusOK, run)
+	case req.URL.Path == "/runs" || strings.HasPrefix(req.URL.Path, "/runs/"):
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not supported for %s", req.Method, req.URL.Path))
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no such endpoint %s", req.URL.Path))
+	}
+}
+
+func (s *runServer) submitRun(w http.ResponseWriter, req *http.Request) {
+	var request runRequest
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid run request: %v", err))
+		return
+	}
+
+	for i, inputFile := range request.InputDOJ {
+		if inputFile == data.StdinFileName {
+			writeError(w, http.StatusBadRequest, errors.New(`invalid inputDoj: "-" (stdin) cannot be used with the HTTP API`))
+			return
+		}
+		request.InputDOJ[i], err = s.resolvePath("inputDoj", inputFile)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	paths := []struct {
+		option string
+		path   *string
+	}{
+		{"outputs", &request.Outputs},
+		{"eligibilityOptions", &request.EligibilityOptions},
+		{"eligibilityRules", &request.EligibilityRules},
+		{"statuteCatalog", &request.StatuteCatalog},
+		{"motionTemplate", &request.MotionTemplate},
+	}
+	for _, p := range paths {
+		if *p.path == "" {
+			continue
+		}
+		*p.path, err = s.resolvePath(p.option, *p.path)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	opts := request.runOpts()
+	settings, err := opts.runSettings(time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	run := &serverRun{
+		ID:       strconv.Itoa(len(s.runs) + 1),
+		Status:   runQueued,
+		opts:     opts,
+		settings: settings,
+	}
+	for i, inputFile := range settings.inputFiles {
+		run.Files = append(run.Files, &serverRunFile{FileIndex: i + 1, InputFile: inputFile, Status: runQueued})
+	}
+	select {
+	case s.queue <- run:
+	default:
+		writeError(w, http.StatusServiceUnavailable, errors.New("too many runs are waiting to be processed"))
+		return
+	}
+	s.runs = append(s.runs, run)
+
+	w.Header().Set("Location", "/runs/"+run.ID)
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// resolvePath is the absolute path of a path in a run request, where relative
+// paths are in the server's root. Paths outside the root, including through
+// symlinks, are rejected.
+func (s *runServer) resolvePath(option string, requestPath string) (string, error) {
+	resolved := requestPath
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(s.root, resolved)
+	}
+	resolved = filepath.Clean(resolved)
+
+	// Resolve the symlinks of the part of the path that exists. Output folders
+	// and zip entry names may not exist as files.
+	existing, rest := resolved, ""
+	for {
+		if linked, err := filepath.EvalSymlinks(existing); err == nil {
+			resolved = filepath.Join(linked, rest)
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+
+	relative, err := filepath.Rel(s.root, resolved)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid %s %q: Must be inside %s", option, requestPath, s.root)
+	}
+	return resolved, nil
+}
+
+func (s *runServer) findRun(id string) *serverRun {
+	for _, run := range s.runs {
+		if run.ID == id {
+			return run
+		}
+	}
+	return nil
+}
+
+func (s *runServer) processRuns() {
+	for run := range s.queue {
+		s.processRun(run)
+	}
+}
+
+func (s *runServer) processRun(run *serverRun) {
+	s.mutex.Lock()
+	run.Status = runRunning
+	run.settings.processingStartTime = time.Now()
+	s.mutex.Unlock()
+
+	runSummary, results := run.opts.runFiles(run.settings, ioutil.Discard, func(fileIndex int) utilities.ProgressReporter {
+		s.mutex.Lock()
+		run.Files[fileIndex-1].Status = runRunning
+		s.mutex.Unlock()
+		return serverRunProgress{server: s, file: run.Files[fileIndex-1]}
+	})
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	run.Status = runSucceeded
+	for i, result := range results {
+		file := run.Files[i]
+		file.OutputFolder = result.outputFolder
+		file.OutputFiles = listOutputFiles(result.outputFolder)
+		if result.err != nil {
+			file.Status = runFailed
+			file.Error = result.err.Error()
+			run.Status = runFailed
+			run.Errors = append(run.Errors, result.err.Error())
+		} else {
+			file.Status = runSucceeded
+		}
+	}
+	if run.Status != runSucceeded {
+		return
+	}
+
+	// Write the summary JSON the way the run command does, but report an error
+	// on the run instead of stopping the server.
+	runSummary.ProcessingTimeInSeconds = time.Since(run.settings.processingStartTime).Seconds()
+	summaryJSON, err := json.Marshal(runSummary)
+	if err == nil {
+		err = ioutil.WriteFile(run.opts.summaryFilePath(), summaryJSON, 0644)
+	}
+	if err != nil {
+		run.Status = runFailed
+		run.Errors = append(run.Errors, err.Error())
+		return
+	}
+	run.Summary = &runSummary
+	run.SummaryFile = run.opts.summaryFilePath()
+}
+
+type serverRunProgress struct {
+	server *runServer
+	file   *serverRunFile
+}
+
+func (p serverRunProgress) ReportProgress(rowsRead int, totalRows int, elapsed time.Duration) {
+	p.server.mutex.Lock()
+	defer p.server.mutex.Unlock()
+	p.file.RowsRead = rowsRead
+	p.file.TotalRows = totalRows
+}
+
+func listOutputFiles(outputFolder string) []string {
+	entries, err := ioutil.ReadDir(outputFolder)
+	if err != nil {
+		return nil
+	}
+	var outputFiles []string
+	for _, entry := range entries {
+		outputFiles = append(outputFiles, path.Join(outputFolder, entry.Name()))
+	}
+	return outputFiles
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (s serveOpts) Execute(args []string) error {
+	root, err := filepath.Abs(s.Root)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("invalid --root: %v", err))
+		os.Exit(utilities.INVALID_RUN_OPTION_ERROR)
+	}
+	token, err := newServerToken()
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("invalid --address: %v", err))
+		os.Exit(utilities.INVALID_RUN_OPTION_ERROR)
+	}
+	fmt.Printf("Listening on http://%s\n", listener.Addr())
+	fmt.Printf("Token: %s\n", token)
+	return http.Serve(listener, newRunServer(root, token))
+}
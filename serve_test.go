@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	path "path/filepath"
+	"regexp"
+
+	. "gogen_pilots/test_fixtures"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("serve", func() {
+	var (
+		session *gexec.Session
+		baseURL string
+		token   string
+		rootDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		rootDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		rootDir, err = path.EvalSymlinks(rootDir)
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+
+		command := exec.Command(pathToGogen, "serve", "--address=127.0.0.1:0", "--root="+rootDir)
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session.Out).Should(gbytes.Say(`Token: \w+`))
+		baseURL = regexp.MustCompile(`http://\S+`).FindString(string(session.Out.Contents()))
+		token = regexp.MustCompile(`Token: (\w+)`).FindStringSubmatch(string(session.Out.Contents()))[1]
+	})
+
+	AfterEach(func() {
+		session.Kill()
+	})
+
+	sendRequest := func(method string, url string, contentType string, authorization string, body []byte) (int, map[string]interface{}) {
+		request, err := http.NewRequest(method, url, bytes.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set("Authorization", authorization)
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+		var responseJSON map[string]interface{}
+		Expect(json.NewDecoder(response.Body).Decode(&responseJSON)).To(Succeed())
+		return response.StatusCode, responseJSON
+	}
+
+	postRun := func(request map[string]interface{}) (int, map[string]interface{}) {
+		body, err := json.Marshal(request)
+		Expect(err).ToNot(HaveOccurred())
+		return sendRequest(http.MethodPost, baseURL+"/runs", "application/json", "Bearer "+token, body)
+	}
+
+	getRun := func(id string) map[string]interface{} {
+		request, err := http.NewRequest(http.MethodGet, baseURL+"/runs/"+id, nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		var run map[string]interface{}
+		Expect(json.NewDecoder(response.Body).Decode(&run)).To(Succeed())
+		return run
+	}
+
+	It("processes a submitted run and returns its summary and output files", func() {
+		outputDir := path.Join(rootDir, "outputs")
+		inputCSV, _, err := ExtractFullCSVFixtures(path.Join("test_fixtures", "los_angeles.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+		dojRows, err := ioutil.ReadFile(inputCSV)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(path.Join(rootDir, "los_angeles.csv"), dojRows, 0644)).To(Succeed())
+		Expect(os.Mkdir(outputDir, 0755)).To(Succeed())
+
+		status, run := postRun(map[string]interface{}{
+			"inputDoj":  []string{"los_angeles.csv"},
+			"outputs":   outputDir,
+			"computeAt": "2019-11-11",
+			"county":    "LOS ANGELES",
+		})
+		Expect(status).To(Equal(http.StatusAccepted))
+		Expect(run["id"]).To(Equal("1"))
+
+		Eventually(func() interface{} {
+			run = getRun("1")
+			return run["status"]
+		}, "10s").Should(Equal("succeeded"))
+
+		summary := run["summary"].(map[string]interface{})
+		Expect(summary["county"]).To(Equal("LOS ANGELES"))
+		Expect(summary["lineCount"]).To(BeNumerically("==", 35))
+		Expect(run["summaryFile"]).To(Equal(path.Join(outputDir, "gogen_pilots.json")))
+		Expect(path.Join(outputDir, "gogen_pilots.json")).To(BeAnExistingFile())
+
+		files := run["files"].([]interface{})
+		Expect(files).To(HaveLen(1))
+		file := files[0].(map[string]interface{})
+		Expect(file["status"]).To(Equal("succeeded"))
+		Expect(file["rowsRead"]).To(BeNumerically("==", 35))
+		Expect(file["totalRows"]).To(BeNumerically("==", 35))
+		Expect(file["outputFiles"]).To(ContainElement(path.Join(outputDir, "DOJ_Input_File_1_Results", "doj_results_1.csv")))
+	})
+
+	It("rejects runs with invalid options", func() {
+		status, response := postRun(map[string]interface{}{
+			"inputDoj": []string{"input.csv"},
+			"outputs":  rootDir,
+			"county":   "NOWHERE",
+		})
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(response["error"]).To(ContainSubstring(`invalid --county "NOWHERE"`))
+
+		status, response = postRun(map[string]interface{}{"inputDojs": []string{"input.csv"}})
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(response["error"]).To(ContainSubstring(`unknown field "inputDojs"`))
+	})
+
+	It("rejects requests without the token or JSON content", func() {
+		body := []byte(`{"inputDoj": ["input.csv"], "outputs": "outputs"}`)
+
+		status, response := sendRequest(http.MethodPost, baseURL+"/runs", "application/json", "", body)
+		Expect(status).To(Equal(http.StatusUnauthorized))
+		Expect(response["error"]).To(ContainSubstring("missing or invalid token"))
+
+		status, _ = sendRequest(http.MethodGet, baseURL+"/runs", "", "Bearer not-the-token", nil)
+		Expect(status).To(Equal(http.StatusUnauthorized))
+
+		status, response = sendRequest(http.MethodPost, baseURL+"/runs", "text/plain", "Bearer "+token, body)
+		Expect(status).To(Equal(http.StatusUnsupportedMediaType))
+		Expect(response["error"]).To(ContainSubstring("Content-Type: application/json"))
+	})
+
+	It("rejects runs with paths outside the root", func() {
+		status, response := postRun(map[string]interface{}{
+			"inputDoj": []string{"/etc/passwd"},
+			"outputs":  rootDir,
+		})
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(response["error"]).To(Equal(fmt.Sprintf(`invalid inputDoj "/etc/passwd": Must be inside %s`, rootDir)))
+
+		status, response = postRun(map[string]interface{}{
+			"inputDoj": []string{"input.csv"},
+			"outputs":  "../outputs",
+		})
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(response["error"]).To(ContainSubstring(`invalid outputs "../outputs"`))
+
+		Expect(os.Symlink("/tmp", path.Join(rootDir, "link"))).To(Succeed())
+		status, response = postRun(map[string]interface{}{
+			"inputDoj":           []string{"input.csv"},
+			"outputs":            rootDir,
+			"eligibilityOptions": "link/options.json",
+		})
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(response["error"]).To(ContainSubstring(`invalid eligibilityOptions "link/options.json"`))
+	})
+
+	It("reports the status of failed runs", func() {
+		status, _ := postRun(map[string]interface{}{
+			"inputDoj": []string{"missing.csv"},
+			"outputs":  rootDir,
+		})
+		Expect(status).To(Equal(http.StatusAccepted))
+
+		var run map[string]interface{}
+		Eventually(func() interface{} {
+			run = getRun("1")
+			return run["status"]
+		}, "10s").Should(Equal("failed"))
+		Expect(run["errors"]).To(ConsistOf(ContainSubstring("missing.csv")))
+		Expect(run).ToNot(HaveKey("summary"))
+	})
+})
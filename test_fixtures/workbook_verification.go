@@ -0,0 +1,260 @@
+package test_fixtures
+
+import (
+	"encoding/csv"
+	"fmt"
+	"gogen_pilots/data"
+	"gogen_pilots/exporter"
+	"io"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+// A county workbook has the same layout as los_angeles.xlsx: a first row
+// flagging the columns of the condensed results, a header row, then one row
+// per DOJ row, with a first column flagging the rows of the Prop 64
+// convictions results. Its DOJ columns are the input, and all of its columns
+// are the expected results.
+
+// CellMismatch is a cell of a results file that does not have the value the
+// workbook expects. Row is the row of the results file, counting its header
+// as row 1. A mismatch without a Column is a row missing from, or extra in,
+// the results file.
+type CellMismatch struct {
+	File     string
+	Row      int
+	Column   string
+	Expected string
+	Actual   string
+}
+
+type WorkbookVerification struct {
+	RowsCompared map[string]int
+	Mismatches   []CellMismatch
+}
+
+func (v WorkbookVerification) Passed() bool {
+	return len(v.Mismatches) == 0
+}
+
+var workbookResultsFiles = []string{"doj_results_1.csv", "doj_results_condensed_1.csv", "doj_results_convictions_1.csv"}
+
+// VerifyWorkbook runs the pipeline on the DOJ rows of a county workbook,
+// writing results to outputFolder, and compares them with the workbook. The
+// county's eligibility flow is run with the default age and years conviction
+// free thresholds.
+func VerifyWorkbook(workbookPath string, outputFolder string, county string, computeAt time.Time) (WorkbookVerification, error) {
+	eligibilityFlow, ok := data.CountyEligibilityFlows[county]
+	if !ok {
+		return WorkbookVerification{}, fmt.Errorf("no eligibility flow for county %q", county)
+	}
+	_, err := xlsx.OpenFile(workbookPath)
+	if err != nil {
+		return WorkbookVerification{}, fmt.Errorf("cannot open workbook %s: %v", workbookPath, err)
+	}
+	err = os.MkdirAll(outputFolder, os.ModePerm)
+	if err != nil {
+		return WorkbookVerification{}, err
+	}
+
+	inputCSV, _, err := ExtractFullCSVFixtures(workbookPath)
+	if err != nil {
+		return WorkbookVerification{}, err
+	}
+	err = runWorkbookPipeline(inputCSV, outputFolder, county, eligibilityFlow, computeAt)
+	if err != nil {
+		return WorkbookVerification{}, err
+	}
+	return VerifyWorkbookResults(workbookPath, outputFolder)
+}
+
+func runWorkbookPipeline(inputCSV string, outputFolder string, county string, eligibilityFlow data.EligibilityFlow, computeAt time.Time) error {
+	totalRows, err := data.CountDOJRows(inputCSV)
+	if err != nil {
+		return err
+	}
+	dojReader, err := data.NewDOJReader(inputCSV, data.CSVFormat, data.DefaultDOJSchema, computeAt, eligibilityFlow)
+	if err != nil {
+		return err
+	}
+	defer dojReader.Close()
+
+	dojWriter, err := exporter.NewDOJWriter(path.Join(outputFolder, workbookResultsFiles[0]))
+	if err != nil {
+		return err
+	}
+	defer dojWriter.Close()
+	condensedDOJWriter, err := exporter.NewCondensedDOJWriter(path.Join(outputFolder, workbookResultsFiles[1]))
+	if err != nil {
+		return err
+	}
+	defer condensedDOJWriter.Close()
+	prop64ConvictionsDOJWriter, err := exporter.NewDOJWriter(path.Join(outputFolder, workbookResultsFiles[2]))
+	if err != nil {
+		return err
+	}
+	defer prop64ConvictionsDOJWriter.Close()
+	rejectedRowsWriter, err := exporter.NewRejectedRowsWriter(path.Join(outputFolder, "doj_rejected_rows_1.csv"))
+	if err != nil {
+		return err
+	}
+	defer rejectedRowsWriter.Close()
+	eligibilityTraceWriter, err := exporter.NewEligibilityTraceWriter(path.Join(outputFolder, "doj_eligibility_trace_1.json"))
+	if err != nil {
+		return err
+	}
+
+	dataExporter := exporter.NewStreamingDataExporter(
+		dojReader,
+		totalRows,
+		eligibilityFlow,
+		50,
+		10,
+		dojWriter,
+		condensedDOJWriter,
+		prop64ConvictionsDOJWriter,
+		rejectedRowsWriter,
+		eligibilityTraceWriter,
+		nil,
+		nil,
+		ioutil.Discard,
+		ioutil.Discard,
+		nil)
+	_, err = dataExporter.Export(county, time.Now())
+	closeErr := eligibilityTraceWriter.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// VerifyWorkbookResults compares the full, condensed and Prop 64 convictions
+// results in resultsFolder with the workbook, cell by cell. Columns are
+// matched by their header, so columns the workbook does not have, like the
+// Eligibility Trace, are not compared.
+func VerifyWorkbookResults(workbookPath string, resultsFolder string) (WorkbookVerification, error) {
+	_, expectedFullResults, err := ExtractFullCSVFixtures(workbookPath)
+	if err != nil {
+		return WorkbookVerification{}, err
+	}
+	expectedCondensedResults, err := ExtractCondensedCSVFixture(workbookPath)
+	if err != nil {
+		return WorkbookVerification{}, err
+	}
+	expectedProp64Convictions, err := ExtractProp64ConvictionsCSVFixture(workbookPath)
+	if err != nil {
+		return WorkbookVerification{}, err
+	}
+
+	verification := WorkbookVerification{RowsCompared: make(map[string]int)}
+	for i, expectedFile := range []string{expectedFullResults, expectedCondensedResults, expectedProp64Convictions} {
+		expected, err := readCSVFile(expectedFile)
+		if err != nil {
+			return WorkbookVerification{}, err
+		}
+		actual, err := readCSVFile(path.Join(resultsFolder, workbookResultsFiles[i]))
+		if err != nil {
+			return WorkbookVerification{}, err
+		}
+		verification.compare(workbookResultsFiles[i], expected, actual)
+	}
+	return verification, nil
+}
+
+func (v *WorkbookVerification) compare(file string, expected [][]string, actual [][]string) {
+	if len(expected) == 0 {
+		return
+	}
+	var actualHeaders []string
+	if len(actual) > 0 {
+		actualHeaders = actual[0]
+	}
+	actualColumns := make(map[string]int)
+	for i, header := range actualHeaders {
+		if _, ok := actualColumns[header]; !ok {
+			actualColumns[header] = i
+		}
+	}
+
+	for _, header := range expected[0] {
+		if _, ok := actualColumns[header]; !ok {
+			v.Mismatches = append(v.Mismatches, CellMismatch{File: file, Row: 1, Column: header, Expected: header, Actual: "(no such column)"})
+		}
+	}
+
+	for row := 1; row < len(expected) || row < len(actual); row++ {
+		if row >= len(actual) {
+			v.Mismatches = append(v.Mismatches, CellMismatch{File: file, Row: row + 1, Expected: strings.Join(expected[row], ","), Actual: "(no row)"})
+			continue
+		}
+		if row >= len(expected) {
+			v.Mismatches = append(v.Mismatches, CellMismatch{File: file, Row: row + 1, Expected: "(no row)", Actual: strings.Join(actual[row], ",")})
+			continue
+		}
+		v.RowsCompared[file]++
+		for column, header := range expected[0] {
+			actualColumn, ok := actualColumns[header]
+			if !ok {
+				continue
+			}
+			expectedValue := cellValue(expected[row], column)
+			actualValue := cellValue(actual[row], actualColumn)
+			if expectedValue != actualValue {
+				v.Mismatches = append(v.Mismatches, CellMismatch{File: file, Row: row + 1, Column: header, Expected: expectedValue, Actual: actualValue})
+			}
+		}
+	}
+}
+
+func cellValue(row []string, column int) string {
+	if column < len(row) {
+		return row[column]
+	}
+	return ""
+}
+
+func readCSVFile(filePath string) ([][]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
+
+// WriteReport prints whether the results match the workbook, and each
+// mismatch by file, row and column.
+func (v WorkbookVerification) WriteReport(w io.Writer) {
+	for _, file := range workbookResultsFiles {
+		fmt.Fprintf(w, "%s: %d rows compared\n", file, v.RowsCompared[file])
+	}
+	if v.Passed() {
+		fmt.Fprintln(w, "PASS: the results match the workbook")
+		return
+	}
+	fmt.Fprintf(w, "FAIL: %d mismatches\n", len(v.Mismatches))
+	for _, mismatch := range v.Mismatches {
+		if mismatch.Column == "" {
+			fmt.Fprintf(w, "%s row %d: expected %q, actual %q\n", mismatch.File, mismatch.Row, mismatch.Expected, mismatch.Actual)
+			continue
+		}
+		fmt.Fprintf(w, "%s row %d, column %s: expected %q, actual %q\n", mismatch.File, mismatch.Row, mismatch.Column, mismatch.Expected, mismatch.Actual)
+	}
+}
+
+var WorkbookMismatchHeaders = []string{"File", "Row", "Column", "Expected", "Actual"}
+
+// WriteMismatches writes each mismatch as a row of WorkbookMismatchHeaders.
+func (v WorkbookVerification) WriteMismatches(w exporter.DOJWriter) {
+	for _, mismatch := range v.Mismatches {
+		w.Write([]string{mismatch.File, fmt.Sprint(mismatch.Row), mismatch.Column, mismatch.Expected, mismatch.Actual})
+	}
+	w.Flush()
+}
@@ -0,0 +1,218 @@
+package utilities
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// ProgressEventsSchemaVersion is the version of the events documented in
+// docs/progress_events.md. Bump it when a field is removed or changes meaning;
+// adding a field or an event does not change the version.
+const ProgressEventsSchemaVersion = 1
+
+const (
+	PhaseCountRows         = "countRows"
+	PhaseResolveIdentities = "resolveIdentities"
+	PhaseProcessSubjects   = "processSubjects"
+)
+
+// ProgressEvents writes the progress of a run as newline-delimited JSON, one
+// event per line. A nil *ProgressEvents writes nothing, so callers do not need
+// to check whether events were asked for.
+type ProgressEvents struct {
+	mutex       sync.Mutex
+	encoder     *json.Encoder
+	phaseStarts map[filePhase]time.Time
+}
+
+type filePhase struct {
+	fileIndex int
+	phase     string
+}
+
+func NewProgressEvents(w io.Writer) *ProgressEvents {
+	return &ProgressEvents{
+		encoder:     json.NewEncoder(w),
+		phaseStarts: make(map[filePhase]time.Time),
+	}
+}
+
+// progressEvent holds the fields every event has. Each event embeds it, so
+// they are written first.
+type progressEvent struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Event         string    `json:"event"`
+	Time          time.Time `json:"time"`
+}
+
+func newProgressEvent(event string) progressEvent {
+	return progressEvent{SchemaVersion: ProgressEventsSchemaVersion, Event: event, Time: time.Now().UTC()}
+}
+
+func (e *ProgressEvents) write(event interface{}) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.writeLocked(event)
+}
+
+func (e *ProgressEvents) writeLocked(event interface{}) {
+	_ = e.encoder.Encode(event)
+}
+
+func (e *ProgressEvents) RunStarted(inputFiles []string) {
+	if e == nil {
+		return
+	}
+	e.write(struct {
+		progressEvent
+		InputFiles []string `json:"inputFiles"`
+		FileCount  int      `json:"fileCount"`
+	}{newProgressEvent("runStarted"), inputFiles, len(inputFiles)})
+}
+
+func (e *ProgressEvents) PhaseStarted(fileIndex int, phase string) {
+	if e == nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.phaseStarts[filePhase{fileIndex, phase}] = time.Now()
+	e.writeLocked(struct {
+		progressEvent
+		FileIndex int    `json:"fileIndex"`
+		Phase     string `json:"phase"`
+	}{newProgressEvent("phaseStarted"), fileIndex, phase})
+}
+
+func (e *ProgressEvents) PhaseFinished(fileIndex int, phase string) {
+	if e == nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	key := filePhase{fileIndex, phase}
+	elapsed := time.Since(e.phaseStarts[key])
+	delete(e.phaseStarts, key)
+	e.writeLocked(struct {
+		progressEvent
+		FileIndex      int     `json:"fileIndex"`
+		Phase          string  `json:"phase"`
+		ElapsedSeconds float64 `json:"elapsedSeconds"`
+	}{newProgressEvent("phaseFinished"), fileIndex, phase, elapsed.Seconds()})
+}
+
+// When the rows of a file are not known up front, like for stdin, a progress
+// event is written every progressRowInterval rows or progressTimeInterval,
+// whichever comes first.
+const (
+	progressRowInterval  = 1000
+	progressTimeInterval = time.Second
+)
+
+// FileReporter reports the rows processed in the file at fileIndex as progress
+// events. Progress is reported for each subject, so to keep the events of a
+// large file readable an event is only written when the percent processed
+// goes up.
+func (e *ProgressEvents) FileReporter(fileIndex int) ProgressReporter {
+	if e == nil {
+		return nil
+	}
+	return &fileProgressEvents{events: e, fileIndex: fileIndex, lastPercent: -1}
+}
+
+type fileProgressEvents struct {
+	events      *ProgressEvents
+	fileIndex   int
+	lastPercent int
+	lastRows    int
+	lastElapsed time.Duration
+}
+
+func (f *fileProgressEvents) ReportProgress(rowsRead int, totalRows int, elapsed time.Duration) {
+	if totalRows == 0 {
+		f.reportRowsRead(rowsRead, elapsed)
+		return
+	}
+
+	percent := Percent(rowsRead, totalRows)
+	if percent <= f.lastPercent {
+		return
+	}
+	f.lastPercent = percent
+
+	var eta time.Duration
+	if rowsRead > 0 && rowsRead < totalRows {
+		eta = time.Duration(float64(elapsed) / float64(rowsRead) * float64(totalRows-rowsRead))
+	}
+	f.events.write(struct {
+		progressEvent
+		FileIndex     int     `json:"fileIndex"`
+		RowsProcessed int     `json:"rowsProcessed"`
+		TotalRows     int     `json:"totalRows"`
+		Percent       int     `json:"percent"`
+		ETASeconds    float64 `json:"etaSeconds"`
+	}{newProgressEvent("progress"), f.fileIndex, rowsRead, totalRows, percent, eta.Seconds()})
+}
+
+// reportRowsRead writes the rows processed without a total, percent or time
+// left, which cannot be known.
+func (f *fileProgressEvents) reportRowsRead(rowsRead int, elapsed time.Duration) {
+	if f.lastRows > 0 && rowsRead-f.lastRows < progressRowInterval && elapsed-f.lastElapsed < progressTimeInterval {
+		return
+	}
+	f.lastRows = rowsRead
+	f.lastElapsed = elapsed
+	f.events.write(struct {
+		progressEvent
+		FileIndex     int `json:"fileIndex"`
+		RowsProcessed int `json:"rowsProcessed"`
+	}{newProgressEvent("progress"), f.fileIndex, rowsRead})
+}
+
+func (e *ProgressEvents) FileFinished(fileIndex int, inputFile string, err error) {
+	if e == nil {
+		return
+	}
+	event := struct {
+		progressEvent
+		FileIndex int    `json:"fileIndex"`
+		InputFile string `json:"inputFile"`
+		Status    string `json:"status"`
+		Error     string `json:"error,omitempty"`
+	}{progressEvent: newProgressEvent("fileFinished"), FileIndex: fileIndex, InputFile: inputFile, Status: "succeeded"}
+	if err != nil {
+		event.Status = "failed"
+		event.Error = err.Error()
+	}
+	e.write(event)
+}
+
+func (e *ProgressEvents) Error(err error) {
+	if e == nil {
+		return
+	}
+	e.write(struct {
+		progressEvent
+		Message string `json:"message"`
+	}{newProgressEvent("error"), err.Error()})
+}
+
+// RunFinished is the last event of a run. The exit code is the one the process
+// exits with, and summaryFile is only set when the run succeeded.
+func (e *ProgressEvents) RunFinished(exitCode int, summaryFile string) {
+	if e == nil {
+		return
+	}
+	status := "succeeded"
+	if exitCode != 0 {
+		status = "failed"
+	}
+	e.write(struct {
+		progressEvent
+		Status      string `json:"status"`
+		ExitCode    int    `json:"exitCode"`
+		SummaryFile string `json:"summaryFile,omitempty"`
+	}{newProgressEvent("runFinished"), status, exitCode, summaryFile})
+}
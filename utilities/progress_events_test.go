@@ -0,0 +1,91 @@
+package utilities_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen_pilots/utilities"
+)
+
+var _ = Describe("ProgressEvents", func() {
+	var output bytes.Buffer
+
+	readEvents := func() []map[string]interface{} {
+		var events []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+			var event map[string]interface{}
+			Expect(json.Unmarshal([]byte(line), &event)).To(Succeed(), line)
+			Expect(event["schemaVersion"]).To(BeNumerically("==", utilities.ProgressEventsSchemaVersion))
+			_, err := time.Parse(time.RFC3339Nano, event["time"].(string))
+			Expect(err).ToNot(HaveOccurred())
+			delete(event, "schemaVersion")
+			delete(event, "time")
+			events = append(events, event)
+		}
+		return events
+	}
+
+	BeforeEach(func() {
+		output.Reset()
+	})
+
+	It("writes one JSON event per line", func() {
+		events := utilities.NewProgressEvents(&output)
+		events.RunStarted([]string{"a.csv", "b.csv"})
+		events.PhaseStarted(1, utilities.PhaseCountRows)
+		events.PhaseFinished(1, utilities.PhaseCountRows)
+		events.FileFinished(1, "a.csv", nil)
+		events.FileFinished(2, "b.csv", errors.New("open b.csv: no such file or directory"))
+		events.Error(errors.New("open b.csv: no such file or directory"))
+		events.RunFinished(2, "")
+
+		written := readEvents()
+		Expect(written).To(HaveLen(7))
+		Expect(written[0]).To(Equal(map[string]interface{}{"event": "runStarted", "inputFiles": []interface{}{"a.csv", "b.csv"}, "fileCount": 2.0}))
+		Expect(written[1]).To(Equal(map[string]interface{}{"event": "phaseStarted", "fileIndex": 1.0, "phase": "countRows"}))
+		Expect(written[2]).To(HaveKeyWithValue("event", "phaseFinished"))
+		Expect(written[2]).To(HaveKey("elapsedSeconds"))
+		Expect(written[3]).To(Equal(map[string]interface{}{"event": "fileFinished", "fileIndex": 1.0, "inputFile": "a.csv", "status": "succeeded"}))
+		Expect(written[4]).To(Equal(map[string]interface{}{"event": "fileFinished", "fileIndex": 2.0, "inputFile": "b.csv", "status": "failed", "error": "open b.csv: no such file or directory"}))
+		Expect(written[5]).To(Equal(map[string]interface{}{"event": "error", "message": "open b.csv: no such file or directory"}))
+		Expect(written[6]).To(Equal(map[string]interface{}{"event": "runFinished", "status": "failed", "exitCode": 2.0}))
+	})
+
+	It("reports progress each time the percent processed goes up, with an estimate of the time left", func() {
+		reporter := utilities.NewProgressEvents(&output).FileReporter(2)
+		reporter.ReportProgress(100, 1000, 10*time.Second)
+		reporter.ReportProgress(101, 1000, 11*time.Second)
+		reporter.ReportProgress(1000, 1000, 100*time.Second)
+
+		Expect(readEvents()).To(Equal([]map[string]interface{}{
+			{"event": "progress", "fileIndex": 2.0, "rowsProcessed": 100.0, "totalRows": 1000.0, "percent": 10.0, "etaSeconds": 90.0},
+			{"event": "progress", "fileIndex": 2.0, "rowsProcessed": 1000.0, "totalRows": 1000.0, "percent": 100.0, "etaSeconds": 0.0},
+		}))
+	})
+
+	It("reports the rows processed every 1000 rows or second when the total rows are not known", func() {
+		reporter := utilities.NewProgressEvents(&output).FileReporter(1)
+		reporter.ReportProgress(10, 0, time.Millisecond)
+		reporter.ReportProgress(500, 0, 100*time.Millisecond)
+		reporter.ReportProgress(1010, 0, 200*time.Millisecond)
+		reporter.ReportProgress(1020, 0, 1300*time.Millisecond)
+
+		Expect(readEvents()).To(Equal([]map[string]interface{}{
+			{"event": "progress", "fileIndex": 1.0, "rowsProcessed": 10.0},
+			{"event": "progress", "fileIndex": 1.0, "rowsProcessed": 1010.0},
+			{"event": "progress", "fileIndex": 1.0, "rowsProcessed": 1020.0},
+		}))
+	})
+
+	It("writes nothing when there are no progress events", func() {
+		var events *utilities.ProgressEvents
+		events.RunStarted([]string{"a.csv"})
+		events.RunFinished(0, "gogen_pilots.json")
+		Expect(events.FileReporter(1)).To(BeNil())
+	})
+})
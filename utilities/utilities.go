@@ -19,13 +19,42 @@ const (
 
 var errorFileName string
 
+var progressEvents *ProgressEvents
+
 func PrintProgressBar(index, totalRows int, totalTime time.Duration, tail string) {
-	progress := float64(index) / float64(totalRows)
+	FprintProgressBar(os.Stdout, index, totalRows, totalTime, tail)
+}
+
+// FprintProgressBar draws the progress of index out of totalRows rows. When
+// totalRows is 0 the total is not known, like for stdin, so only the rows
+// processed are printed.
+func FprintProgressBar(w io.Writer, index, totalRows int, totalTime time.Duration, tail string) {
+	averageTime := AverageTime(totalTime, index)
+	if totalRows == 0 {
+		fmt.Fprintf(w, "%d rows (avg time: %s) "+tail, index, averageTime)
+		fmt.Fprint(w, "\r")
+		return
+	}
+	progress := math.Min(float64(index)/float64(totalRows), 1)
 	bar := strings.Repeat("=", int(math.Round(progress*50.0)))
 	space := strings.Repeat(" ", int(math.Round((1-progress)*50)))
-	averageTime := AverageTime(totalTime, index)
-	fmt.Printf("["+bar+space+"] %d/%d (avg time: %s) "+tail, int(index), int(totalRows), averageTime)
-	fmt.Print("\r")
+	fmt.Fprintf(w, "["+bar+space+"] %d/%d (avg time: %s) "+tail, int(index), int(totalRows), averageTime)
+	fmt.Fprint(w, "\r")
+}
+
+// ProgressReporter is told how many rows of a DOJ file have been processed.
+// totalRows is 0 when the rows of the file are not known, like for stdin.
+type ProgressReporter interface {
+	ReportProgress(rowsRead int, totalRows int, elapsed time.Duration)
+}
+
+// ProgressBar reports progress by redrawing a progress bar on a console.
+type ProgressBar struct {
+	Writer io.Writer
+}
+
+func (p ProgressBar) ReportProgress(rowsRead int, totalRows int, elapsed time.Duration) {
+	FprintProgressBar(p.Writer, rowsRead, totalRows, elapsed, "")
 }
 
 func AverageTime(totalTime time.Duration, index int) time.Duration {
@@ -54,6 +83,12 @@ func SetErrorFileName(filename string) {
 	errorFileName = filename
 }
 
+// SetProgressEvents makes ExitWithError and ExitWithErrors also report their
+// errors, and the exit code, as progress events.
+func SetProgressEvents(events *ProgressEvents) {
+	progressEvents = events
+}
+
 func ExitWithError(originalError error, exitCode int) {
 	errorFile, err := os.Create(errorFileName)
 	if err != nil {
@@ -62,6 +97,9 @@ func ExitWithError(originalError error, exitCode int) {
 
 	errorWriter := io.MultiWriter(os.Stderr, errorFile)
 	fmt.Fprintln(errorWriter, originalError)
+	errorFile.Close()
+	progressEvents.Error(originalError)
+	progressEvents.RunFinished(exitCode, "")
 	os.Exit(exitCode)
 }
 
@@ -74,7 +112,10 @@ func ExitWithErrors(originalErrors []error, exitCode int) {
 	errorWriter := io.MultiWriter(os.Stderr, errorFile)
 	for _, errorMessage := range originalErrors {
 		fmt.Fprintln(errorWriter, errorMessage)
+		progressEvents.Error(errorMessage)
 	}
+	errorFile.Close()
+	progressEvents.RunFinished(exitCode, "")
 	os.Exit(exitCode)
 }
 
@@ -100,11 +141,29 @@ func GenerateIndexedOutputFolder(outputFolder string, fileIndex int, suffix stri
 	return filepath.Join(outputFolder, fmt.Sprintf("DOJ_Input_File_%d_Results%s", fileIndex, suffix))
 }
 
-func GetOutputWriter(filePath string) io.Writer {
-	summaryFile, err := os.Create(filePath)
+func GetOutputWriter(filePath string) io.WriteCloser {
+	summaryWriter, err := NewOutputWriter(filePath, os.Stdout)
 	if err != nil {
 		ExitWithError(err, OTHER_ERROR)
 	}
-	summaryWriter := io.MultiWriter(os.Stdout, summaryFile)
 	return summaryWriter
 }
+
+// NewOutputWriter writes to both a new file at filePath and console, which is
+// usually os.Stdout. Closing it closes the file.
+func NewOutputWriter(filePath string, console io.Writer) (io.WriteCloser, error) {
+	summaryFile, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return outputWriter{Writer: io.MultiWriter(console, summaryFile), file: summaryFile}, nil
+}
+
+type outputWriter struct {
+	io.Writer
+	file *os.File
+}
+
+func (w outputWriter) Close() error {
+	return w.file.Close()
+}
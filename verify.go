@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gogen_pilots/exporter"
+	"gogen_pilots/test_fixtures"
+	"gogen_pilots/utilities"
+)
+
+type verifyOpts struct {
+	Workbook     string `long:"workbook" description:"A county workbook in the layout of test_fixtures/los_angeles.xlsx"`
+	OutputFolder string `long:"outputs" description:"The folder in which to place result files and the verification report"`
+	ComputeAt    string `long:"compute-at" description:"The date for which eligibility will be evaluated, ex: 2019-11-11"`
+	County       string `long:"county" default:"LOS ANGELES" description:"The county whose eligibility flow will be applied, ex: LOS ANGELES"`
+}
+
+func (v verifyOpts) Execute(args []string) error {
+	passed, err := v.verify(os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if _, ok := err.(verifyOptionError); ok {
+			os.Exit(utilities.INVALID_RUN_OPTION_ERROR)
+		}
+		os.Exit(utilities.FILE_PROCESSING_ERROR)
+	}
+	if !passed {
+		os.Exit(utilities.OTHER_ERROR)
+	}
+	return nil
+}
+
+type verifyOptionError struct {
+	error
+}
+
+// verify runs the pipeline on the workbook's DOJ rows, prints whether the
+// results match the workbook, and writes any mismatches to
+// gogen_pilots_verification.csv.
+func (v verifyOpts) verify(w io.Writer) (bool, error) {
+	if v.Workbook == "" || v.OutputFolder == "" {
+		return false, verifyOptionError{errors.New("missing required field: verify needs --workbook and --outputs")}
+	}
+
+	computeAtDate := time.Now()
+	if v.ComputeAt != "" {
+		computeAtOption, err := time.Parse("2006-01-02", v.ComputeAt)
+		if err != nil {
+			return false, verifyOptionError{errors.New("invalid --compute-at date: Must be a valid date in the format YYYY-MM-DD")}
+		}
+		computeAtDate = computeAtOption
+	}
+
+	verification, err := test_fixtures.VerifyWorkbook(v.Workbook, v.OutputFolder, strings.ToUpper(strings.TrimSpace(v.County)), computeAtDate)
+	if err != nil {
+		return false, err
+	}
+
+	reportFilePath := filepath.Join(v.OutputFolder, "gogen_pilots_verification.csv")
+	reportWriter, err := exporter.NewWriter(reportFilePath, test_fixtures.WorkbookMismatchHeaders)
+	if err != nil {
+		return false, err
+	}
+	verification.WriteMismatches(reportWriter)
+	err = reportWriter.Close()
+	if err != nil {
+		return false, err
+	}
+
+	verification.WriteReport(w)
+	if !verification.Passed() {
+		fmt.Fprintf(w, "Mismatches written to %s\n", reportFilePath)
+	}
+	return verification.Passed(), nil
+}
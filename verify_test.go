@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	path "path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("verify", func() {
+	var (
+		pathToGogen string
+		outputDir   string
+	)
+
+	BeforeEach(func() {
+		var err error
+		pathToGogen, err = gexec.Build("gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+		outputDir, err = ioutil.TempDir("/tmp", "gogen_pilots")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	verify := func(computeAt string) *gexec.Session {
+		command := exec.Command(pathToGogen, "verify", fmt.Sprintf("--workbook=%s", path.Join("test_fixtures", "los_angeles.xlsx")), fmt.Sprintf("--outputs=%s", outputDir), "--compute-at="+computeAt)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		return session
+	}
+
+	It("passes when the results match the workbook", func() {
+		session := verify("2019-11-11")
+		Eventually(session, "10s").Should(gexec.Exit(0))
+		Expect(session.Out).To(gbytes.Say("doj_results_1.csv: 35 rows compared"))
+		Expect(session.Out).To(gbytes.Say("PASS: the results match the workbook"))
+	})
+
+	It("fails and reports the mismatches when the results do not match the workbook", func() {
+		session := verify("2021-11-11")
+		Eventually(session, "10s").Should(gexec.Exit(1))
+		Expect(session.Out).To(gbytes.Say(`FAIL: \d+ mismatches`))
+		Expect(session.Out).To(gbytes.Say(`doj_results_1.csv row 33, column Eligibility Determination: expected "Hand Review", actual "Eligible for Dismissal"`))
+
+		report, err := ioutil.ReadFile(path.Join(outputDir, "gogen_pilots_verification.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(report)).To(HavePrefix("File,Row,Column,Expected,Actual\n"))
+		Expect(string(report)).To(ContainSubstring("doj_results_1.csv,33,Eligibility Reason,Currently serving sentence,Only has 11357-60 charges and completed sentence\n"))
+	})
+})